@@ -0,0 +1,72 @@
+package buffer
+
+import (
+	"sync"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+)
+
+// A Backend does the heavy lifting for buffers: it allocates their memory, fills in their tones,
+// renders them into audio samples, and releases their memory once they're no longer needed. This
+// lets the same buffer code run on different hardware, like a GPU or a pool of CPU goroutines.
+//
+// A backend is used as part of the key for buffer pools, so it must be comparable. Backends are
+// usually pointers.
+type Backend interface {
+	// Allocate allocates the specified number of tones, and a position for each one, for the
+	// buffer.
+	Allocate(ctx context.Context, buffer *Buffer, numTones int)
+
+	// Fill calls fill once for every tone in the buffer. The calls can happen concurrently and in
+	// any order.
+	Fill(ctx context.Context, buffer *Buffer, fill FillFunc)
+
+	// Render fills samples with mono audio rendered from the buffer's tones, one tone per sample,
+	// starting at the context's current time. Panning into the buffer's channel layout is left to
+	// the buffer. It advances the renderer's phases and the context's
+	// time by the number of samples rendered.
+	Render(ctx context.Context, buffer *Buffer, r *render.Renderer, samples []float32)
+
+	// Release releases the buffer's memory. The buffer can't be used after this.
+	Release(buffer *Buffer)
+}
+
+// A FillFunc sets the values of the tone at the specified index in a buffer.
+type FillFunc func(index int, t *tone.Tone)
+
+var (
+	backend      Backend = &CPUBackend{}
+	backendMutex sync.Mutex
+	backendKey   backendCtxKey
+)
+
+type backendCtxKey struct{}
+
+// BufferBackend returns the backend for buffers in this context, or nil if no backend is set.
+func BufferBackend(ctx context.Context) Backend {
+	if ctx == nil {
+		return nil
+	}
+
+	if v := ctx.Value(backendKey); v != nil {
+		if b, ok := v.(Backend); ok {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// SetBufferBackend sets the global backend for buffers. All contexts created after this is called
+// will use the backend set here. Setting a nil backend goes back to the default CPU backend.
+func SetBufferBackend(b Backend) {
+	backendMutex.Lock()
+	defer backendMutex.Unlock()
+
+	if b == nil {
+		b = &CPUBackend{}
+	}
+	backend = b
+}
@@ -0,0 +1,61 @@
+package buffer
+
+import (
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_BufferBackend tests that BufferBackend returns the backend set in the context.
+func Test_BufferBackend(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		require.Nil(t, BufferBackend(nil))
+	})
+
+	t.Run("no value set", func(t *testing.T) {
+		ctx := context.NewTestContext()
+		require.Nil(t, BufferBackend(ctx))
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		ctx := context.NewTestContext()
+		ctx = ctx.WithValue(backendKey, 5)
+		require.Nil(t, BufferBackend(ctx))
+	})
+
+	t.Run("default backend", func(t *testing.T) {
+		ctx := context.NewContext()
+		b := BufferBackend(ctx)
+		require.NotNil(t, b)
+		require.IsType(t, &CPUBackend{}, b)
+	})
+}
+
+// Test_SetBufferBackend tests that SetBufferBackend sets the backend for contexts created after it
+// is called.
+func Test_SetBufferBackend(t *testing.T) {
+	defer SetBufferBackend(nil)
+
+	before := context.NewContext()
+	oldBackend := BufferBackend(before)
+
+	newBackend := &CPUBackend{Workers: 3}
+	SetBufferBackend(newBackend)
+
+	after := context.NewContext()
+	require.Same(t, newBackend, BufferBackend(after))
+	require.Same(t, oldBackend, BufferBackend(before))
+
+	// Each backend gets its own buffer pool.
+	require.NotSame(t, BufferPool(before), BufferPool(after))
+	buffer := NewBuffer(after)
+	defer buffer.Release()
+	require.Same(t, newBackend, buffer.backend)
+
+	// A nil backend goes back to the default.
+	SetBufferBackend(nil)
+	ctx := context.NewContext()
+	require.IsType(t, &CPUBackend{}, BufferBackend(ctx))
+	require.NotSame(t, newBackend, BufferBackend(ctx))
+}
@@ -1,32 +1,291 @@
-//go:build !darwin
-
 package buffer
 
 import (
 	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
 	"github.com/green-aloe/enobox/tone"
 )
 
+// A Buffer holds one tone for every frame in a block of audio, which is one second long by default.
+// Buffers are expensive to allocate, so they should usually come from the context's buffer pool
+// and be released back to it when they're no longer needed.
+//
+// A buffer can have multiple voices, which are tones that play at the same time, like the notes in
+// a chord. The tones are stored one voice after another: all the frames of the first voice, then
+// all the frames of the second voice, and so on. Voice returns the tones of a single voice.
+//
+// A buffer renders audio for a channel layout. Each tone has a position that pans it across the
+// layout's speakers.
 type Buffer struct {
 	Tones []tone.Tone
+
+	// Positions holds the position of each tone, at the same index.
+	Positions []Position
+
+	// VoiceGains holds the gain of each voice, which scales every tone in the voice. Voices without
+	// a gain play at full volume.
+	VoiceGains []float32
+
+	// Layout is the arrangement of speakers that the buffer renders for. It's set when the buffer
+	// is allocated.
+	Layout Layout
+
+	// PanLaw is the way that tones are split between neighboring speakers.
+	PanLaw PanLaw
+
+	// Number of voices in the buffer. 0 means 1.
+	numVoices int
+
+	// ID that the buffer is tracked by in debug mode, or 0 if it isn't tracked
+	id uint64
+
+	// Pool that the buffer is returned to when it's released.
+	pool *Pool
+
+	// Backend that manages the buffer's memory and does its work.
+	backend Backend
+
+	// Mono samples of a single voice that are mixed into the rendered audio.
+	scratch []float32
 }
 
+// NewBuffer allocates a new buffer with the context's number of frames and voices, using the
+// context's backend and channel layout. If the context doesn't set a number of frames, the buffer
+// holds one second of audio at the context's sample rate. If it doesn't set a number of voices or
+// a layout, the buffer has one voice and is mono. The buffer is released to the context's buffer
+// pool, which counts it as one of its buffers. NewBuffer never waits for the pool's limit, but the
+// buffer still counts towards it, so Get waits for it (or another buffer) to be released if the
+// pool is at its limit.
 func NewBuffer(ctx context.Context) Buffer {
-	tones := make([]tone.Tone, ctx.SampleRate())
-	for i := range tones {
-		tones[i] = tone.NewTone(ctx)
+	bufferPool := BufferPool(ctx)
+	buffer := newBuffer(ctx, bufferPool)
+	bufferPool.track(buffer)
+	buffer.id = trackBuffer()
+
+	return buffer
+}
+
+// newBuffer allocates a new buffer that is released to the specified pool.
+func newBuffer(ctx context.Context, bufferPool *Pool) Buffer {
+	buffer := Buffer{
+		Layout:    BufferLayout(ctx),
+		numVoices: max(NumVoices(ctx), 1),
+		pool:      bufferPool,
+		backend:   BufferBackend(ctx),
+	}
+	if buffer.Layout == 0 {
+		buffer.Layout = DefaultLayout
+	}
+	if buffer.backend == nil {
+		buffer.backend = &CPUBackend{}
 	}
 
-	return Buffer{
-		Tones: tones,
+	frames := BufferFrames(ctx)
+	if frames == 0 {
+		frames = ctx.SampleRate()
 	}
+	buffer.backend.Allocate(ctx, &buffer, frames*buffer.numVoices)
+
+	buffer.VoiceGains = make([]float32, buffer.numVoices)
+	for i := range buffer.VoiceGains {
+		buffer.VoiceGains[i] = 1
+	}
+
+	return buffer
 }
 
 // Release releases the buffer back to the system.
 // After this, the buffer should not be used again.
+//
+// The buffer is reset and stored in the pool that it came from so that it can be reused. Buffers
+// that don't belong to a pool have their memory released by their backend. Releasing a buffer more
+// than once has no effect, except in debug mode, where it panics.
 func (buffer *Buffer) Release() {
+	if buffer == nil {
+		return
+	}
+	if buffer.id != 0 {
+		untrackBuffer(buffer.id)
+	}
+	if buffer.Tones == nil {
+		return
+	}
+
+	if buffer.pool != nil {
+		buffer.pool.Store(*buffer)
+	} else if buffer.backend != nil {
+		buffer.backend.Release(buffer)
+	}
+
+	buffer.Tones = nil
+	buffer.Positions = nil
+	buffer.VoiceGains = nil
+	buffer.pool = nil
+	buffer.backend = nil
+	buffer.scratch = nil
 }
 
 // Reset resets a buffer to its zero values.
 func (buffer *Buffer) Reset() {
+	if buffer == nil {
+		return
+	}
+
+	for i := range buffer.Tones {
+		buffer.Tones[i].Reset()
+	}
+	clear(buffer.Positions)
+	for i := range buffer.VoiceGains {
+		buffer.VoiceGains[i] = 1
+	}
+	buffer.PanLaw = 0
+}
+
+// NumVoices returns the number of voices in the buffer.
+func (buffer *Buffer) NumVoices() int {
+	if buffer == nil {
+		return 0
+	}
+
+	return max(buffer.numVoices, 1)
+}
+
+// Frames returns the number of frames in the buffer, which is the number of tones in each voice.
+func (buffer *Buffer) Frames() int {
+	if buffer == nil {
+		return 0
+	}
+
+	return len(buffer.Tones) / buffer.NumVoices()
+}
+
+// Voice returns the tones of one voice in the buffer, one tone per frame, or nil if the voice
+// doesn't exist. The tones are shared with the buffer.
+func (buffer *Buffer) Voice(voice int) []tone.Tone {
+	if buffer == nil || voice < 0 || voice >= buffer.NumVoices() {
+		return nil
+	}
+
+	frames := buffer.Frames()
+
+	return buffer.Tones[voice*frames : (voice+1)*frames]
+}
+
+// Fill calls fill once for every tone in the buffer to set its values. The index is the tone's index
+// in Tones, so a tone's voice is index / Frames() and its frame is index % Frames(). The backend can
+// make the calls concurrently and in any order, so fill must be safe to call from multiple
+// goroutines.
+func (buffer *Buffer) Fill(ctx context.Context, fill FillFunc) {
+	if buffer == nil || fill == nil {
+		return
+	}
+	if buffer.id != 0 {
+		checkBuffer(buffer.id)
+	}
+
+	buffer.getBackend().Fill(ctx, buffer, fill)
+}
+
+// Render fills samples with audio rendered from the buffer's tones, one frame at a time, starting
+// at the context's current time. Every voice is rendered, scaled by its gain, and summed together.
+// Each frame holds one sample for every channel in the buffer's layout, interleaved, with each tone
+// panned across the channels according to its position. If there are more frames than tones or
+// more tones than frames, only the shorter length is rendered. The context's time is advanced by
+// the number of frames rendered.
+//
+// The renderer keeps track of the phase of each partial, and each voice is rendered with the
+// renderer's matching voice, so the same renderer should be used for consecutive buffers in the
+// same stream of audio. If r is nil, a new renderer is used.
+func (buffer *Buffer) Render(ctx context.Context, r *render.Renderer, samples []float32) {
+	if buffer == nil || ctx == nil {
+		return
+	}
+	if buffer.id != 0 {
+		checkBuffer(buffer.id)
+	}
+
+	if r == nil {
+		r = &render.Renderer{}
+	}
+
+	numChannels := max(buffer.Layout.Channels(), 1)
+	numVoices := buffer.NumVoices()
+	if numChannels == 1 && numVoices == 1 {
+		buffer.getBackend().Render(ctx, buffer, r, samples)
+		if gain := buffer.voiceGain(0); gain != 1 {
+			for i := range min(len(buffer.Tones), len(samples)) {
+				samples[i] *= gain
+			}
+		}
+		return
+	}
+
+	n := min(buffer.Frames(), len(samples)/numChannels)
+	if cap(buffer.scratch) < n {
+		buffer.scratch = make([]float32, n)
+	}
+	mono := buffer.scratch[:n]
+	clear(samples[:n*numChannels])
+
+	gains := make([]float32, numChannels)
+	for v := range numVoices {
+		// Each voice starts at the same time, so it gets its own copy of the context's time.
+		voice := Buffer{
+			Tones:   buffer.Voice(v),
+			backend: buffer.backend,
+		}
+		buffer.getBackend().Render(context.WithTime(ctx, ctx.Time()), &voice, r.Voice(v), mono)
+
+		voiceGain := buffer.voiceGain(v)
+		if numChannels == 1 {
+			for i, sample := range mono {
+				samples[i] += sample * voiceGain
+			}
+			continue
+		}
+
+		positions := buffer.voicePositions(v)
+		for i, sample := range mono {
+			var position Position
+			if i < len(positions) {
+				position = positions[i]
+			}
+			buffer.Layout.Gains(position, buffer.PanLaw, gains)
+
+			frame := samples[i*numChannels : (i+1)*numChannels]
+			for c, gain := range gains {
+				frame[c] += sample * voiceGain * gain
+			}
+		}
+	}
+
+	context.Advance(ctx, n)
+}
+
+// voiceGain returns the gain of the voice.
+func (buffer *Buffer) voiceGain(voice int) float32 {
+	if voice < len(buffer.VoiceGains) {
+		return buffer.VoiceGains[voice]
+	}
+
+	return 1
+}
+
+// voicePositions returns the positions of the tones in the voice. It can be shorter than the
+// voice, or nil, if the buffer doesn't have a position for every tone.
+func (buffer *Buffer) voicePositions(voice int) []Position {
+	frames := buffer.Frames()
+	start := min(voice*frames, len(buffer.Positions))
+	end := min(start+frames, len(buffer.Positions))
+
+	return buffer.Positions[start:end]
+}
+
+// getBackend returns the buffer's backend, or the default CPU backend if it doesn't have one.
+func (buffer *Buffer) getBackend() Backend {
+	if buffer.backend == nil {
+		return &CPUBackend{}
+	}
+
+	return buffer.backend
 }
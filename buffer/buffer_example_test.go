@@ -0,0 +1,105 @@
+package buffer_test
+
+import (
+	"fmt"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/note"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+)
+
+func ExampleBuffer_Render() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8,
+	})
+	pool := buffer.BufferPool(ctx)
+
+	var r render.Renderer
+	samples := make([]float32, ctx.SampleRate())
+	for range 2 {
+		// Generate a second of tones.
+		buf := pool.Get()
+		for i := range buf.Tones {
+			buf.Tones[i].Frequency = 2
+			buf.Tones[i].Gain = 1
+		}
+
+		// Render them to samples.
+		buf.Render(ctx, &r, samples)
+		fmt.Printf("%.2f\n", samples)
+
+		// Recycle the buffer for the next second.
+		buf.Release()
+	}
+
+	fmt.Println(pool.Count())
+
+	// Output:
+	// [0.00 1.00 0.00 -1.00 0.00 1.00 0.00 -1.00]
+	// [0.00 1.00 0.00 -1.00 0.00 1.00 0.00 -1.00]
+	// 1
+}
+
+func ExampleBuffer_Render_stereo() {
+	defer buffer.SetBufferLayout(buffer.DefaultLayout)
+
+	buffer.SetBufferLayout(buffer.LayoutStereo)
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 4,
+	})
+
+	buf := buffer.NewBuffer(ctx)
+	defer buf.Release()
+
+	// Sweep a tone from the left speaker to the right speaker.
+	buf.PanLaw = buffer.PanLawLinear
+	for i := range buf.Tones {
+		buf.Tones[i].Frequency = 0.5
+		buf.Tones[i].Gain = 1
+		buf.Tones[i].HarmonicGains[0] = 0
+		buf.Positions[i].Pan = float32(i)*2/3 - 1
+	}
+
+	// Each frame holds a left sample and a right sample.
+	samples := make([]float32, len(buf.Tones)*buffer.LayoutStereo.Channels())
+	buf.Render(ctx, nil, samples)
+	fmt.Printf("%.2f\n", samples)
+
+	// Output:
+	// [0.00 0.00 0.47 0.24 0.33 0.67 0.00 0.71]
+}
+
+func ExampleBuffer_Voice() {
+	defer buffer.SetNumVoices(buffer.DefaultNumVoices)
+
+	chord := note.NewChord(note.C, note.Major)
+	buffer.SetNumVoices(len(chord.Notes()))
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	buf := buffer.NewBuffer(ctx)
+	defer buf.Release()
+
+	// Play one note of the chord in each voice, with the root note a little louder.
+	for v, n := range chord.Notes() {
+		for i := range buf.Voice(v) {
+			buf.Voice(v)[i] = tone.NewTriangleTone(ctx, n.Frequency(4))
+			buf.Voice(v)[i].Gain = 0.25
+		}
+		fmt.Printf("%s: %.2f\n", n, n.Frequency(4))
+	}
+	buf.VoiceGains[0] = 1.5
+
+	samples := make([]float32, buf.Frames())
+	buf.Render(ctx, nil, samples)
+	fmt.Printf("%.3f\n", samples[1:5])
+
+	// Output:
+	// C: 261.63
+	// E: 329.63
+	// G: 392.00
+	// [0.172 0.343 0.516 0.687]
+}
@@ -0,0 +1,360 @@
+package buffer
+
+import (
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewBuffer tests that NewBuffer allocates a buffer with one tone per sample that belongs to
+// the context's buffer pool.
+func Test_NewBuffer(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	buffer := NewBuffer(ctx)
+	require.Len(t, buffer.Tones, 1_000)
+	for i := range buffer.Tones {
+		require.True(t, buffer.Tones[i].Empty())
+		require.Len(t, buffer.Tones[i].HarmonicGains, tone.NumHarmGains(ctx))
+	}
+	require.Same(t, BufferPool(ctx), buffer.pool)
+
+	// Buffers from a context without a pool don't belong to any pool.
+	buffer = NewBuffer(context.NewTestContext())
+	require.Empty(t, buffer.Tones)
+	require.Nil(t, buffer.pool)
+}
+
+// Test_Buffer_Reset tests that Buffer's Reset method resets every tone in the buffer.
+func Test_Buffer_Reset(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var buffer *Buffer
+		require.NotPanics(t, func() { buffer.Reset() })
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 100,
+	})
+	buffer := NewBuffer(ctx)
+	for i := range buffer.Tones {
+		buffer.Tones[i] = tone.NewSawtoothTone(ctx, float32(i+1))
+		buffer.Tones[i].Gain = 0.5
+	}
+	harmGains := buffer.Tones[0].HarmonicGains
+
+	buffer.Positions[3] = Position{Pan: 0.5, Rear: 1}
+	buffer.PanLaw = PanLawLinear
+	buffer.VoiceGains[0] = 0.25
+
+	buffer.Reset()
+	require.Len(t, buffer.Tones, 100)
+	require.Len(t, buffer.Positions, 100)
+	for i := range buffer.Positions {
+		require.Zero(t, buffer.Positions[i])
+	}
+	require.Equal(t, PanLawConstantPower, buffer.PanLaw)
+	require.Equal(t, []float32{1}, buffer.VoiceGains)
+	for i := range buffer.Tones {
+		require.True(t, buffer.Tones[i].Empty())
+		require.Len(t, buffer.Tones[i].HarmonicGains, tone.NumHarmGains(ctx))
+	}
+
+	// The tones keep their memory.
+	require.Same(t, &harmGains[0], &buffer.Tones[0].HarmonicGains[0])
+}
+
+// Test_Buffer_Release tests that Buffer's Release method returns the buffer to its pool.
+func Test_Buffer_Release(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var buffer *Buffer
+		require.NotPanics(t, func() { buffer.Release() })
+	})
+
+	t.Run("pooled", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 123,
+		})
+		bufferPool := BufferPool(ctx)
+		bufferPool.Clear()
+
+		buffer := bufferPool.Get()
+		require.Len(t, buffer.Tones, 123)
+		buffer.Tones[5].Frequency = 440
+		first := &buffer.Tones[0]
+
+		buffer.Release()
+		require.Nil(t, buffer.Tones)
+		require.Equal(t, 1, bufferPool.Count())
+
+		// Releasing again does nothing.
+		buffer.Release()
+		require.Equal(t, 1, bufferPool.Count())
+
+		// The next buffer from the pool is the same one, and it has been reset.
+		buffer = bufferPool.Get()
+		require.Zero(t, bufferPool.Count())
+		require.Same(t, first, &buffer.Tones[0])
+		require.Zero(t, buffer.Tones[5].Frequency)
+
+		buffer.Release()
+		require.Equal(t, 1, bufferPool.Count())
+	})
+
+	t.Run("unpooled", func(t *testing.T) {
+		buffer := Buffer{Tones: make([]tone.Tone, 10)}
+		require.NotPanics(t, func() { buffer.Release() })
+		require.Nil(t, buffer.Tones)
+	})
+}
+
+// Test_Buffer_Voice tests that Buffer's Voice method returns the tones of each voice.
+func Test_Buffer_Voice(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var buffer *Buffer
+		require.Zero(t, buffer.NumVoices())
+		require.Zero(t, buffer.Frames())
+		require.Nil(t, buffer.Voice(0))
+	})
+
+	t.Run("literal", func(t *testing.T) {
+		buffer := Buffer{Tones: make([]tone.Tone, 10)}
+		require.Equal(t, 1, buffer.NumVoices())
+		require.Equal(t, 10, buffer.Frames())
+		require.Len(t, buffer.Voice(0), 10)
+		require.Nil(t, buffer.Voice(1))
+	})
+
+	defer SetNumVoices(DefaultNumVoices)
+	SetNumVoices(3)
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 50,
+	})
+	buffer := NewBuffer(ctx)
+	defer buffer.Release()
+	require.Equal(t, 3, buffer.NumVoices())
+	require.Equal(t, 50, buffer.Frames())
+
+	require.Nil(t, buffer.Voice(-1))
+	require.Nil(t, buffer.Voice(3))
+	for v := range 3 {
+		voice := buffer.Voice(v)
+		require.Len(t, voice, 50)
+		require.Same(t, &buffer.Tones[v*50], &voice[0])
+	}
+
+	// Fill indexes the tones one voice after another.
+	buffer.Fill(ctx, func(index int, t *tone.Tone) {
+		t.Frequency = float32(index / buffer.Frames())
+	})
+	for v := range 3 {
+		for _, tn := range buffer.Voice(v) {
+			require.Equal(t, float32(v), tn.Frequency)
+		}
+	}
+}
+
+// Test_Buffer_Fill tests that Buffer's Fill method sets every tone in the buffer through its
+// backend.
+func Test_Buffer_Fill(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var buffer *Buffer
+		require.NotPanics(t, func() { buffer.Fill(context.NewContext(), func(int, *tone.Tone) {}) })
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 5_000,
+	})
+	buffer := NewBuffer(ctx)
+	defer buffer.Release()
+
+	require.NotPanics(t, func() { buffer.Fill(ctx, nil) })
+
+	buffer.Fill(ctx, func(index int, t *tone.Tone) {
+		t.Frequency = float32(index)
+		t.Gain = 0.5
+	})
+	for i := range buffer.Tones {
+		require.Equal(t, float32(i), buffer.Tones[i].Frequency)
+		require.Equal(t, float32(0.5), buffer.Tones[i].Gain)
+	}
+}
+
+// Test_Buffer_Render tests that Buffer's Render method renders one tone per sample.
+func Test_Buffer_Render(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var buffer *Buffer
+		require.NotPanics(t, func() { buffer.Render(context.NewContext(), nil, make([]float32, 10)) })
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+	buffer := NewBuffer(ctx)
+	defer buffer.Release()
+
+	for i := range buffer.Tones {
+		buffer.Tones[i].Frequency = 100 + float32(i)/10
+		buffer.Tones[i].Gain = 0.5
+		buffer.Tones[i].HarmonicGains[1] = 0.3
+	}
+
+	var want []float32
+	{
+		var r render.Renderer
+		want = make([]float32, len(buffer.Tones))
+		for i, tn := range buffer.Tones {
+			want[i] = r.Sample(ctx, tn)
+		}
+	}
+
+	t.Run("new renderer", func(t *testing.T) {
+		start := ctx.Time()
+		have := make([]float32, len(buffer.Tones))
+		buffer.Render(ctx, nil, have)
+		require.Equal(t, want, have)
+		require.Equal(t, start.ShiftBy(len(have)), ctx.Time())
+	})
+
+	t.Run("shared renderer", func(t *testing.T) {
+		var r render.Renderer
+		have := make([]float32, len(buffer.Tones))
+		buffer.Render(ctx, &r, have[:400])
+		buffer.Render(ctx, &r, have[400:])
+		require.Equal(t, want[:400], have[:400])
+
+		// The second call picks up the phases where the first one left off, but it starts from the
+		// first tone in the buffer again.
+		var r2 render.Renderer
+		r2.RenderTones(ctx, buffer.Tones[:400], make([]float32, 400))
+		rest := make([]float32, 600)
+		r2.RenderTones(ctx, buffer.Tones, rest)
+		require.Equal(t, rest, have[400:])
+	})
+
+	t.Run("multichannel", func(t *testing.T) {
+		for _, layout := range []Layout{LayoutStereo, LayoutQuad, Layout5_1} {
+			buffer := Buffer{
+				Tones:     buffer.Tones,
+				Positions: make([]Position, len(buffer.Tones)),
+				Layout:    layout,
+				PanLaw:    PanLawLinear,
+			}
+			for i := range buffer.Positions {
+				buffer.Positions[i] = Position{
+					Pan:  float32(i)/500 - 1,
+					Rear: float32(i) / 1_000,
+				}
+			}
+
+			numChannels := layout.Channels()
+			start := ctx.Time()
+			have := make([]float32, len(buffer.Tones)*numChannels)
+			buffer.Render(ctx, nil, have)
+			require.Equal(t, start.ShiftBy(len(buffer.Tones)), ctx.Time())
+
+			gains := make([]float32, numChannels)
+			for i := range buffer.Tones {
+				layout.Gains(buffer.Positions[i], PanLawLinear, gains)
+				for c, gain := range gains {
+					require.Equal(t, want[i]*gain, have[i*numChannels+c], "%s: %d, %d", layout, i, c)
+				}
+			}
+
+			// Only whole frames are rendered.
+			short := make([]float32, 10*numChannels+1)
+			short[len(short)-1] = 5
+			buffer.Render(ctx, nil, short)
+			require.Equal(t, float32(5), short[len(short)-1])
+			require.Equal(t, start.ShiftBy(len(buffer.Tones)+10), ctx.Time())
+		}
+	})
+
+	t.Run("voice gain", func(t *testing.T) {
+		buffer := Buffer{
+			Tones:      buffer.Tones,
+			VoiceGains: []float32{0.5},
+		}
+
+		have := make([]float32, len(buffer.Tones))
+		buffer.Render(ctx, nil, have)
+		for i := range have {
+			require.Equal(t, want[i]*0.5, have[i])
+		}
+	})
+
+	t.Run("voices", func(t *testing.T) {
+		defer SetNumVoices(DefaultNumVoices)
+		SetNumVoices(3)
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 1_000,
+			Time:       context.NewTime(),
+		})
+
+		chord := NewBuffer(ctx)
+		defer chord.Release()
+		chord.Fill(ctx, func(index int, t *tone.Tone) {
+			*t = tone.NewSquareTone(ctx, []float32{261.63, 329.63, 392}[index/chord.Frames()])
+			t.Gain = 0.25
+		})
+		chord.VoiceGains = []float32{1, 0.5, 2}
+
+		// Each voice is rendered on its own, so render them separately for reference.
+		want := make([][]float32, 3)
+		for v := range want {
+			var r render.Renderer
+			want[v] = make([]float32, 2*chord.Frames())
+			voiceCtx := context.NewContextWith(context.ContextOptions{
+				SampleRate: ctx.SampleRate(),
+			})
+			r.RenderTones(voiceCtx, chord.Voice(v), want[v][:chord.Frames()])
+			r.RenderTones(voiceCtx, chord.Voice(v), want[v][chord.Frames():])
+		}
+
+		for _, layout := range []Layout{LayoutMono, LayoutStereo} {
+			chord.Layout = layout
+			for i := range chord.Positions {
+				chord.Positions[i].Pan = float32(i/chord.Frames()) - 1
+			}
+
+			var r render.Renderer
+			numChannels := layout.Channels()
+			have := make([]float32, 2*chord.Frames()*numChannels)
+			start := ctx.Time()
+			chord.Render(ctx, &r, have[:chord.Frames()*numChannels])
+			chord.Render(ctx, &r, have[chord.Frames()*numChannels:])
+			require.Equal(t, start.ShiftBy(2*chord.Frames()), ctx.Time())
+
+			gains := make([]float32, numChannels)
+			for i := range 2 * chord.Frames() {
+				sums := make([]float32, numChannels)
+				for v := range 3 {
+					layout.Gains(Position{Pan: float32(v) - 1}, PanLawConstantPower, gains)
+					for c := range sums {
+						sums[c] += want[v][i] * chord.VoiceGains[v] * gains[c]
+					}
+				}
+				require.InDeltaSlice(t, sums, have[i*numChannels:(i+1)*numChannels], 1e-6, "%s: %d", layout, i)
+			}
+		}
+	})
+
+	t.Run("missing positions", func(t *testing.T) {
+		buffer := Buffer{
+			Tones:  buffer.Tones,
+			Layout: LayoutStereo,
+			PanLaw: PanLawLinear,
+		}
+
+		have := make([]float32, 2*len(buffer.Tones))
+		buffer.Render(ctx, nil, have)
+		for i := range buffer.Tones {
+			require.Equal(t, want[i]/2, have[2*i])
+			require.Equal(t, want[i]/2, have[2*i+1])
+		}
+	})
+}
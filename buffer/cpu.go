@@ -0,0 +1,191 @@
+package buffer
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+)
+
+const (
+	// Default number of tones in each chunk of a buffer that the CPU backend works on at a time.
+	// Below this, the cost of handing chunks to workers outweighs the work that they do.
+	DefaultChunkSize = 1_024
+)
+
+// A CPUBackend is a portable backend that keeps buffers in regular Go memory and splits work on
+// them across a pool of worker goroutines. The zero value is ready to use.
+//
+// A buffer is always split into chunks of the same size no matter how many workers there are, and
+// the backend renders bit-identical audio with any number of workers, which keeps output
+// reproducible from one machine to the next.
+type CPUBackend struct {
+	// Workers is the maximum number of goroutines that fill or render a single buffer. If it's 0,
+	// the backend uses one goroutine per CPU.
+	Workers int
+
+	// ChunkSize is the number of tones in each chunk of a buffer. If it's 0, the backend uses
+	// DefaultChunkSize.
+	ChunkSize int
+}
+
+// Allocate allocates the specified number of tones and positions for the buffer.
+func (backend *CPUBackend) Allocate(ctx context.Context, buffer *Buffer, numTones int) {
+	if buffer == nil {
+		return
+	}
+
+	tones := make([]tone.Tone, max(numTones, 0))
+	for i := range tones {
+		tones[i] = tone.NewTone(ctx)
+	}
+
+	buffer.Tones = tones
+	buffer.Positions = make([]Position, len(tones))
+}
+
+// Fill calls fill once for every tone in the buffer, splitting the tones across the workers.
+func (backend *CPUBackend) Fill(ctx context.Context, buffer *Buffer, fill FillFunc) {
+	if buffer == nil || fill == nil {
+		return
+	}
+
+	backend.parallel(len(buffer.Tones), func(start, end int) {
+		for i := start; i < end; i++ {
+			fill(i, &buffer.Tones[i])
+		}
+	})
+}
+
+// Render fills samples with audio rendered from the buffer's tones, one tone per sample, starting
+// at the context's current time.
+//
+// Each sample depends on the phases left behind by every sample before it, so the work is split
+// into two passes. The first pass steps every partial's phase through the tones in order without
+// rendering anything, which is cheap, and notes the phases at the start of each chunk. With those,
+// the second pass renders all the chunks at the same time. Because the phases are worked out the
+// same way that a single renderer would work them out, the audio is bit-identical to rendering the
+// tones in order, no matter how many workers or how large the chunks are.
+func (backend *CPUBackend) Render(ctx context.Context, buffer *Buffer, r *render.Renderer, samples []float32) {
+	if buffer == nil || r == nil || ctx == nil {
+		return
+	}
+
+	n := min(len(buffer.Tones), len(samples))
+	chunks := backend.chunks(n)
+	if len(chunks) <= 1 {
+		r.RenderTones(ctx, buffer.Tones, samples)
+		return
+	}
+
+	// Find the starting phases of each chunk. This also leaves the renderer at the phases after
+	// the last chunk, ready for the next call.
+	starts := make([][]float64, len(chunks))
+	for i, chunk := range chunks {
+		starts[i] = r.Phases()
+		r.Advance(offsetContext(ctx, chunk[0]), buffer.Tones[chunk[0]:chunk[1]])
+	}
+
+	// Render every chunk from its starting phases.
+	backend.each(chunks, func(i int, chunk [2]int) {
+		chunkRenderer := render.Renderer{Window: r.Window}
+		chunkRenderer.SetPhases(starts[i])
+		chunkRenderer.RenderTones(offsetContext(ctx, chunk[0]), buffer.Tones[chunk[0]:chunk[1]], samples[chunk[0]:chunk[1]])
+	})
+
+	context.Advance(ctx, n)
+}
+
+// Release releases the buffer's tones and positions to the garbage collector.
+func (backend *CPUBackend) Release(buffer *Buffer) {
+	if buffer == nil {
+		return
+	}
+
+	buffer.Tones = nil
+	buffer.Positions = nil
+}
+
+// workers returns the number of goroutines to use.
+func (backend *CPUBackend) workers() int {
+	if backend != nil && backend.Workers > 0 {
+		return backend.Workers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// chunkSize returns the number of items in each chunk.
+func (backend *CPUBackend) chunkSize() int {
+	if backend != nil && backend.ChunkSize > 0 {
+		return backend.ChunkSize
+	}
+
+	return DefaultChunkSize
+}
+
+// chunks splits n items into contiguous chunks of the chunk size. The last chunk holds whatever is
+// left over. Each chunk holds its start and end index.
+func (backend *CPUBackend) chunks(n int) [][2]int {
+	if n <= 0 {
+		return nil
+	}
+
+	size := backend.chunkSize()
+	chunks := make([][2]int, 0, (n+size-1)/size)
+	for start := 0; start < n; start += size {
+		chunks = append(chunks, [2]int{start, min(start+size, n)})
+	}
+
+	return chunks
+}
+
+// parallel splits n items into chunks and hands them out to the workers.
+func (backend *CPUBackend) parallel(n int, f func(start, end int)) {
+	backend.each(backend.chunks(n), func(_ int, chunk [2]int) {
+		f(chunk[0], chunk[1])
+	})
+}
+
+// each calls f for every chunk and waits for them all to finish. The chunks are handed out to a
+// pool of worker goroutines, each of which keeps taking the next chunk until there are none left.
+func (backend *CPUBackend) each(chunks [][2]int, f func(i int, chunk [2]int)) {
+	numWorkers := min(backend.workers(), len(chunks))
+	if numWorkers <= 1 {
+		for i, chunk := range chunks {
+			f(i, chunk)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range numWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(chunks) {
+					return
+				}
+				f(i, chunks[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// offsetContext returns a context that shares everything with its parent context except for its
+// time, which starts the specified number of samples after the parent's current time. This lets a
+// chunk of a buffer that is being rendered on its own goroutine, or one voice out of many, move its
+// own time forward without touching its parent's time or racing with the others.
+func offsetContext(ctx context.Context, offset int) context.Context {
+	offsetCtx := context.WithTime(ctx, ctx.Time())
+	context.Advance(offsetCtx, offset)
+
+	return offsetCtx
+}
@@ -0,0 +1,338 @@
+package buffer
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_CPUBackend_Allocate tests that CPUBackend's Allocate method allocates empty tones.
+func Test_CPUBackend_Allocate(t *testing.T) {
+	var backend CPUBackend
+	require.NotPanics(t, func() { backend.Allocate(context.NewContext(), nil, 10) })
+
+	ctx := context.NewContext()
+	var buffer Buffer
+	backend.Allocate(ctx, &buffer, 25)
+	require.Len(t, buffer.Tones, 25)
+	for i := range buffer.Tones {
+		require.True(t, buffer.Tones[i].Empty())
+		require.Len(t, buffer.Tones[i].HarmonicGains, tone.NumHarmGains(ctx))
+	}
+
+	backend.Allocate(ctx, &buffer, -1)
+	require.Empty(t, buffer.Tones)
+}
+
+// Test_CPUBackend_Fill tests that CPUBackend's Fill method calls the fill function exactly once for
+// every tone.
+func Test_CPUBackend_Fill(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var backend CPUBackend
+		require.NotPanics(t, func() { backend.Fill(context.NewContext(), nil, func(int, *tone.Tone) {}) })
+		require.NotPanics(t, func() { backend.Fill(context.NewContext(), &Buffer{}, nil) })
+	})
+
+	ctx := context.NewContext()
+	for _, workers := range []int{0, 1, 2, 4, 7} {
+		backend := CPUBackend{Workers: workers}
+		var buffer Buffer
+		backend.Allocate(ctx, &buffer, 10_000)
+
+		var calls atomic.Int64
+		backend.Fill(ctx, &buffer, func(index int, t *tone.Tone) {
+			calls.Add(1)
+			t.Frequency += float32(index)
+		})
+		require.Equal(t, int64(len(buffer.Tones)), calls.Load())
+		for i := range buffer.Tones {
+			require.Equal(t, float32(i), buffer.Tones[i].Frequency)
+		}
+	}
+}
+
+// Test_CPUBackend_Render tests that CPUBackend's Render method renders the same audio as a single
+// renderer working through the tones in order, no matter how many workers it uses.
+func Test_CPUBackend_Render(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var backend CPUBackend
+		require.NotPanics(t, func() { backend.Render(context.NewContext(), nil, &render.Renderer{}, make([]float32, 10)) })
+		require.NotPanics(t, func() { backend.Render(context.NewContext(), &Buffer{}, nil, make([]float32, 10)) })
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		Time:       context.NewTime(),
+		SampleRate: 10_000,
+	})
+	tones := make([]tone.Tone, 10_000)
+	for i := range tones {
+		tones[i] = tone.NewSawtoothTone(ctx, 100+float32(i)/20)
+		tones[i].Gain = 0.5
+	}
+
+	// Render twice in a row so that the second render has to pick up the phases from the first.
+	want := make([]float32, 2*len(tones))
+	{
+		var r render.Renderer
+		wantCtx := context.NewContextWith(context.ContextOptions{
+			Time:       ctx.Time(),
+			SampleRate: ctx.SampleRate(),
+		})
+		r.RenderTones(wantCtx, tones, want[:len(tones)])
+		r.RenderTones(wantCtx, tones, want[len(tones):])
+	}
+
+	for _, workers := range []int{1, 2, 4, 7} {
+		backend := CPUBackend{Workers: workers}
+		buffer := Buffer{Tones: tones}
+
+		var r render.Renderer
+		start := ctx.Time()
+		have := make([]float32, len(want))
+		backend.Render(ctx, &buffer, &r, have[:len(tones)])
+		require.Equal(t, start.ShiftBy(len(tones)), ctx.Time())
+		backend.Render(ctx, &buffer, &r, have[len(tones):])
+		require.Equal(t, start.ShiftBy(len(want)), ctx.Time())
+
+		require.Equal(t, want, have, "workers: %d", workers)
+	}
+
+	t.Run("short samples", func(t *testing.T) {
+		backend := CPUBackend{Workers: 4}
+		buffer := Buffer{Tones: tones}
+
+		samples := make([]float32, 5_000)
+		start := ctx.Time()
+		backend.Render(ctx, &buffer, &render.Renderer{}, samples)
+		require.Equal(t, start.ShiftBy(len(samples)), ctx.Time())
+		require.Equal(t, want[:len(samples)], samples)
+	})
+}
+
+// Test_CPUBackend_Release tests that CPUBackend's Release method drops the buffer's tones.
+func Test_CPUBackend_Release(t *testing.T) {
+	var backend CPUBackend
+	require.NotPanics(t, func() { backend.Release(nil) })
+
+	buffer := Buffer{Tones: make([]tone.Tone, 10)}
+	backend.Release(&buffer)
+	require.Nil(t, buffer.Tones)
+}
+
+// Test_CPUBackend_Render_deterministic tests that CPUBackend's Render method renders bit-identical
+// audio no matter how many workers it uses or how large its chunks are.
+func Test_CPUBackend_Render_deterministic(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 44_100,
+	})
+	tones := make([]tone.Tone, 10_000)
+	for i := range tones {
+		tones[i] = tone.NewSquareTone(ctx, 55*float32(1+(i/1_500)%7))
+		tones[i].Gain = 0.25
+	}
+	buffer := Buffer{Tones: tones}
+
+	render := func(backend CPUBackend) []float32 {
+		var r render.Renderer
+		samples := make([]float32, 3*len(tones))
+		for i := range 3 {
+			backend.Render(ctx, &buffer, &r, samples[i*len(tones):(i+1)*len(tones)])
+		}
+		return samples
+	}
+
+	want := render(CPUBackend{Workers: 1})
+	for _, workers := range []int{0, 2, 3, 4, 8, 16} {
+		require.Equal(t, want, render(CPUBackend{Workers: workers}), "workers: %d", workers)
+	}
+
+	for _, chunkSize := range []int{10, 100, 2_500, 20_000} {
+		require.Equal(t, want, render(CPUBackend{Workers: 4, ChunkSize: chunkSize}), "chunk size: %d", chunkSize)
+	}
+}
+
+// Test_CPUBackend_chunks tests that CPUBackend's chunks method splits items into contiguous chunks
+// of the same size that cover every item, no matter how many workers there are.
+func Test_CPUBackend_chunks(t *testing.T) {
+	type test struct {
+		backend CPUBackend
+		n       int
+		want    [][2]int
+	}
+
+	for _, subtest := range []test{
+		{CPUBackend{}, 0, nil},
+		{CPUBackend{}, -5, nil},
+		{CPUBackend{}, 100, [][2]int{{0, 100}}},
+		{CPUBackend{}, 1_024, [][2]int{{0, 1_024}}},
+		{CPUBackend{Workers: 1}, 2_500, [][2]int{{0, 1_024}, {1_024, 2_048}, {2_048, 2_500}}},
+		{CPUBackend{Workers: 8}, 2_500, [][2]int{{0, 1_024}, {1_024, 2_048}, {2_048, 2_500}}},
+		{CPUBackend{ChunkSize: 2_000}, 5_000, [][2]int{{0, 2_000}, {2_000, 4_000}, {4_000, 5_000}}},
+		{CPUBackend{ChunkSize: 1}, 3, [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+	} {
+		require.Equal(t, subtest.want, subtest.backend.chunks(subtest.n), "%+v", subtest)
+	}
+}
+
+// Test_CPUBackend_each tests that CPUBackend's each method calls the function exactly once for
+// every chunk.
+func Test_CPUBackend_each(t *testing.T) {
+	for _, workers := range []int{0, 1, 2, 5, 100} {
+		backend := CPUBackend{Workers: workers}
+		chunks := backend.chunks(50_000)
+
+		calls := make([]atomic.Int64, len(chunks))
+		backend.each(chunks, func(i int, chunk [2]int) {
+			require.Equal(t, chunks[i], chunk)
+			calls[i].Add(1)
+		})
+		for i := range calls {
+			require.Equal(t, int64(1), calls[i].Load(), "workers: %d, chunk: %d", workers, i)
+		}
+	}
+}
+
+// BenchmarkCPUBackend_Render measures how many samples per second CPUBackend's Render method can
+// render with different numbers of workers and harmonics.
+func BenchmarkCPUBackend_Render(b *testing.B) {
+	defer tone.SetNumHarmGains(tone.DefaultNumHarmGains)
+
+	for _, config := range []struct {
+		sampleRate   int
+		numHarmGains int
+	}{
+		{48_000, tone.DefaultNumHarmGains},
+		{96_000, 100},
+	} {
+		tone.SetNumHarmGains(config.numHarmGains)
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: config.sampleRate,
+		})
+
+		// Play a new note every tenth of a second.
+		notes := make([]tone.Tone, 10)
+		for i := range notes {
+			notes[i] = tone.NewSawtoothTone(ctx, 110*float32(i+1))
+			notes[i].Gain = 0.5
+		}
+
+		buffer := NewBuffer(ctx)
+		buffer.Fill(ctx, func(index int, t *tone.Tone) {
+			note := notes[index*len(notes)/len(buffer.Tones)]
+			t.Frequency = note.Frequency
+			t.Gain = note.Gain
+			copy(t.HarmonicGains, note.HarmonicGains)
+			for i, phase := range note.HarmonicPhases {
+				t.SetHarmonicPhase(i, phase)
+			}
+		})
+		samples := make([]float32, len(buffer.Tones))
+
+		for _, workers := range benchmarkWorkers() {
+			name := fmt.Sprintf("rate=%d/harmonics=%d/workers=%d", config.sampleRate, config.numHarmGains, workers)
+			b.Run(name, func(b *testing.B) {
+				backend := CPUBackend{Workers: workers}
+				var r render.Renderer
+				b.ResetTimer()
+				for range b.N {
+					backend.Render(ctx, &buffer, &r, samples)
+				}
+				b.ReportMetric(float64(b.N*len(samples))/b.Elapsed().Seconds(), "samples/s")
+			})
+		}
+
+		buffer.Release()
+	}
+}
+
+// BenchmarkCPUBackend_Render_changing measures how quickly CPUBackend's Render method can render a
+// buffer whose tones change on every sample, which means that the frequencies of the partials have
+// to be worked out again for every tone.
+func BenchmarkCPUBackend_Render_changing(b *testing.B) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 48_000,
+	})
+	buffer := NewBuffer(ctx)
+	defer buffer.Release()
+
+	sawtooth := tone.NewSawtoothTone(ctx, 1)
+	for _, config := range []struct {
+		name string
+		freq func(i int) float32
+	}{
+		// Glide up two octaves over the buffer.
+		{"glide", func(i int) float32 {
+			return 110 * float32(math.Exp2(2*float64(i)/float64(len(buffer.Tones))))
+		}},
+		// Wobble a quarter of a semitone above and below 440Hz six times a second.
+		{"vibrato", func(i int) float32 {
+			lfo := math.Sin(2 * math.Pi * 6 * float64(i) / float64(ctx.SampleRate()))
+			return 440 * float32(math.Exp2(lfo/48))
+		}},
+	} {
+		buffer.Fill(ctx, func(index int, t *tone.Tone) {
+			t.Frequency = config.freq(index)
+			t.Gain = 0.5
+			copy(t.HarmonicGains, sawtooth.HarmonicGains)
+		})
+		samples := make([]float32, len(buffer.Tones))
+
+		for _, workers := range benchmarkWorkers() {
+			b.Run(fmt.Sprintf("%s/workers=%d", config.name, workers), func(b *testing.B) {
+				backend := CPUBackend{Workers: workers}
+				var r render.Renderer
+				b.ResetTimer()
+				for range b.N {
+					backend.Render(ctx, &buffer, &r, samples)
+				}
+				b.ReportMetric(float64(b.N*len(samples))/b.Elapsed().Seconds(), "samples/s")
+			})
+		}
+	}
+}
+
+// BenchmarkCPUBackend_Fill measures how quickly CPUBackend's Fill method can set every tone in a
+// buffer.
+func BenchmarkCPUBackend_Fill(b *testing.B) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 48_000,
+	})
+	buffer := NewBuffer(ctx)
+	defer buffer.Release()
+
+	for _, workers := range benchmarkWorkers() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			backend := CPUBackend{Workers: workers}
+			for range b.N {
+				backend.Fill(ctx, &buffer, func(index int, t *tone.Tone) {
+					t.Frequency = 440
+					t.Gain = 0.5
+					for i := range t.HarmonicGains {
+						t.HarmonicGains[i] = 1 / float32(i+2)
+					}
+				})
+			}
+			b.ReportMetric(float64(b.N*len(buffer.Tones))/b.Elapsed().Seconds(), "tones/s")
+		})
+	}
+}
+
+// benchmarkWorkers returns the numbers of workers to benchmark with: powers of 2 up to the number
+// of CPUs, and the number of CPUs itself.
+func benchmarkWorkers() []int {
+	numCPUs := runtime.GOMAXPROCS(0)
+
+	var workers []int
+	for n := 1; n < numCPUs; n *= 2 {
+		workers = append(workers, n)
+	}
+
+	return append(workers, numCPUs)
+}
@@ -0,0 +1,175 @@
+package buffer
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// Whether buffers are being tracked
+	debug atomic.Bool
+
+	// Stack of every tracked buffer that hasn't been released yet, by buffer ID
+	liveBuffers      = make(map[uint64]string)
+	liveBuffersMutex sync.Mutex
+
+	// ID of the last tracked buffer
+	lastID atomic.Uint64
+)
+
+// SetDebug turns debug mode on or off. In debug mode, every buffer handed out by a pool or
+// allocated with NewBuffer is tracked until it's released, along with the stack that got it.
+// Releasing a buffer more than once or using a buffer after releasing it panics, and Leaks reports
+// buffers that were never released. Tracking has a cost, so debug mode is meant for tests.
+//
+// Buffers that were handed out before debug mode was turned on aren't tracked. Buffers that were
+// handed out while it was on are still checked when they're released, even if it's been turned off
+// since.
+func SetDebug(enabled bool) {
+	debug.Store(enabled)
+}
+
+// Debug reports if debug mode is on.
+func Debug() bool {
+	return debug.Load()
+}
+
+// A Leak is a buffer that was never released.
+type Leak struct {
+	// Stack is the stack trace of the goroutine that got the buffer.
+	Stack string
+}
+
+// String returns a description of the leak.
+func (leak Leak) String() string {
+	return "buffer: buffer never released, got at:\n" + leak.Stack
+}
+
+// A Checkpoint marks a point in time. Buffers that are handed out after a checkpoint and never
+// released are reported by its Leaks method.
+type Checkpoint uint64
+
+// NewCheckpoint returns a checkpoint for the current point in time. Debug mode must be on for
+// buffers to be tracked.
+func NewCheckpoint() Checkpoint {
+	return Checkpoint(lastID.Load())
+}
+
+// Leaks returns every buffer that was handed out after the checkpoint while debug mode was on and
+// that hasn't been released yet, in the order that they were handed out.
+func (checkpoint Checkpoint) Leaks() []Leak {
+	liveBuffersMutex.Lock()
+	ids := make([]uint64, 0, len(liveBuffers))
+	for id := range liveBuffers {
+		if id > uint64(checkpoint) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	leaks := make([]Leak, len(ids))
+	for i, id := range ids {
+		leaks[i] = Leak{Stack: liveBuffers[id]}
+	}
+	liveBuffersMutex.Unlock()
+
+	return leaks
+}
+
+// A TestingT is the part of testing.TB that CheckLeaks needs.
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+// CheckLeaks turns on debug mode for the rest of the test and reports an error for every buffer
+// that the test doesn't release by the time it ends. Debug mode is restored to its previous state
+// when the test ends. Because debug mode is global, tests that check for leaks shouldn't run in
+// parallel with tests that get buffers without releasing them.
+//
+//	func TestSomething(t *testing.T) {
+//		buffer.CheckLeaks(t)
+//		...
+//	}
+func CheckLeaks(t TestingT) {
+	t.Helper()
+
+	enabled := debug.Swap(true)
+	checkpoint := NewCheckpoint()
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		for _, leak := range checkpoint.Leaks() {
+			t.Errorf("%s", leak)
+		}
+		debug.Store(enabled)
+	})
+}
+
+// trackBuffer starts tracking a buffer that is being handed out and returns its ID, or 0 if debug
+// mode is off.
+func trackBuffer() uint64 {
+	if !debug.Load() {
+		return 0
+	}
+
+	id := lastID.Add(1)
+	stack := callers()
+
+	liveBuffersMutex.Lock()
+	liveBuffers[id] = stack
+	liveBuffersMutex.Unlock()
+
+	return id
+}
+
+// untrackBuffer stops tracking a buffer that is being released. It panics if the buffer was
+// already released.
+func untrackBuffer(id uint64) {
+	liveBuffersMutex.Lock()
+	_, ok := liveBuffers[id]
+	delete(liveBuffers, id)
+	liveBuffersMutex.Unlock()
+
+	if !ok {
+		panic("buffer: buffer released more than once")
+	}
+}
+
+// checkBuffer panics if a tracked buffer has already been released.
+func checkBuffer(id uint64) {
+	liveBuffersMutex.Lock()
+	_, ok := liveBuffers[id]
+	liveBuffersMutex.Unlock()
+
+	if !ok {
+		panic("buffer: buffer used after release")
+	}
+}
+
+// callers returns the stack trace of the code that is getting a buffer, leaving out the frames
+// inside this package.
+func callers() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/green-aloe/enobox/buffer.") || strings.HasSuffix(frame.File, "_test.go") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package buffer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_SetDebug tests that SetDebug turns debug mode on and off.
+func Test_SetDebug(t *testing.T) {
+	defer SetDebug(false)
+
+	require.False(t, Debug())
+
+	SetDebug(true)
+	require.True(t, Debug())
+
+	SetDebug(false)
+	require.False(t, Debug())
+}
+
+// Test_Checkpoint_Leaks tests that Checkpoint's Leaks method reports every buffer that was handed
+// out after the checkpoint and never released.
+func Test_Checkpoint_Leaks(t *testing.T) {
+	defer SetDebug(false)
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	bufferPool := BufferPool(ctx)
+
+	// Buffers aren't tracked when debug mode is off.
+	checkpoint := NewCheckpoint()
+	untracked := bufferPool.Get()
+	require.Empty(t, checkpoint.Leaks())
+
+	SetDebug(true)
+	before := bufferPool.Get()
+
+	checkpoint = NewCheckpoint()
+	first := bufferPool.Get()
+	second := NewBuffer(ctx)
+
+	leaks := checkpoint.Leaks()
+	require.Len(t, leaks, 2)
+	for _, leak := range leaks {
+		require.Contains(t, leak.Stack, "Test_Checkpoint_Leaks")
+		require.Contains(t, leak.Stack, "debug_test.go")
+		require.NotContains(t, leak.Stack, "buffer.trackBuffer")
+		require.Contains(t, leak.String(), "buffer: buffer never released")
+	}
+
+	first.Release()
+	require.Len(t, checkpoint.Leaks(), 1)
+	second.Release()
+	require.Empty(t, checkpoint.Leaks())
+
+	// Buffers from before the checkpoint aren't reported.
+	before.Release()
+	untracked.Release()
+}
+
+// Test_Buffer_Release_debug tests that releasing a buffer more than once panics in debug mode.
+func Test_Buffer_Release_debug(t *testing.T) {
+	defer SetDebug(false)
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	bufferPool := BufferPool(ctx)
+
+	t.Run("debug off", func(t *testing.T) {
+		SetDebug(false)
+
+		buffer := bufferPool.Get()
+		buffer.Release()
+		require.NotPanics(t, func() { buffer.Release() })
+	})
+
+	t.Run("same buffer", func(t *testing.T) {
+		SetDebug(true)
+
+		buffer := bufferPool.Get()
+		buffer.Release()
+		require.PanicsWithValue(t, "buffer: buffer released more than once", func() { buffer.Release() })
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		SetDebug(true)
+
+		buffer := bufferPool.Get()
+		buffer2 := buffer
+		buffer.Release()
+		require.PanicsWithValue(t, "buffer: buffer released more than once", func() { buffer2.Release() })
+	})
+
+	t.Run("turned off after getting", func(t *testing.T) {
+		SetDebug(true)
+		buffer := bufferPool.Get()
+		SetDebug(false)
+
+		buffer.Release()
+		require.Panics(t, func() { buffer.Release() })
+	})
+
+	t.Run("reused", func(t *testing.T) {
+		SetDebug(true)
+
+		// A buffer that comes back out of the pool is a new buffer as far as tracking goes.
+		buffer := bufferPool.Get()
+		buffer.Release()
+		buffer = bufferPool.Get()
+		require.NotPanics(t, func() { buffer.Release() })
+	})
+
+	t.Run("zero buffer", func(t *testing.T) {
+		SetDebug(true)
+
+		var buffer Buffer
+		require.NotPanics(t, func() { buffer.Release() })
+		require.NotPanics(t, func() { buffer.Release() })
+	})
+}
+
+// Test_Buffer_useAfterRelease tests that using a buffer after releasing it panics in debug mode.
+func Test_Buffer_useAfterRelease(t *testing.T) {
+	defer SetDebug(false)
+	SetDebug(true)
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	bufferPool := BufferPool(ctx)
+
+	buffer := bufferPool.Get()
+	buffer2 := buffer
+	require.NotPanics(t, func() { buffer2.Render(ctx, nil, make([]float32, 10)) })
+	require.NotPanics(t, func() { buffer2.Fill(ctx, func(int, *tone.Tone) {}) })
+
+	buffer.Release()
+	for _, b := range []*Buffer{&buffer, &buffer2} {
+		require.PanicsWithValue(t, "buffer: buffer used after release", func() { b.Render(ctx, nil, make([]float32, 10)) })
+		require.PanicsWithValue(t, "buffer: buffer used after release", func() { b.Fill(ctx, func(int, *tone.Tone) {}) })
+	}
+}
+
+// fakeT records the errors reported by CheckLeaks and runs its cleanup functions on demand.
+type fakeT struct {
+	cleanups []func()
+	errors   []string
+}
+
+func (t *fakeT) Helper()                   {}
+func (t *fakeT) Cleanup(f func())          { t.cleanups = append(t.cleanups, f) }
+func (t *fakeT) Errorf(f string, a ...any) { t.errors = append(t.errors, fmt.Sprintf(f, a...)) }
+
+// Test_CheckLeaks tests that CheckLeaks reports buffers that aren't released by the end of a test.
+func Test_CheckLeaks(t *testing.T) {
+	defer SetDebug(false)
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	bufferPool := BufferPool(ctx)
+
+	t.Run("no leaks", func(t *testing.T) {
+		var ft fakeT
+		CheckLeaks(&ft)
+		require.True(t, Debug())
+
+		buffer := bufferPool.Get()
+		buffer.Release()
+
+		require.Len(t, ft.cleanups, 1)
+		ft.cleanups[0]()
+		require.Empty(t, ft.errors)
+		require.False(t, Debug())
+	})
+
+	t.Run("leaks", func(t *testing.T) {
+		SetDebug(true)
+
+		var ft fakeT
+		CheckLeaks(&ft)
+
+		buffer := bufferPool.Get()
+		_ = NewBuffer(ctx)
+		buffer.Release()
+		_ = bufferPool.Get()
+
+		ft.cleanups[0]()
+		require.Len(t, ft.errors, 2)
+		for _, err := range ft.errors {
+			require.Contains(t, err, "buffer: buffer never released")
+			require.Contains(t, err, "Test_CheckLeaks")
+		}
+
+		// Debug mode stays on, because it was on before.
+		require.True(t, Debug())
+	})
+
+	t.Run("real test", func(t *testing.T) {
+		CheckLeaks(t)
+
+		buffer := bufferPool.Get()
+		defer buffer.Release()
+	})
+}
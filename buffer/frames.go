@@ -0,0 +1,88 @@
+package buffer
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+const (
+	// DefaultBufferDuration is the default length of audio that a buffer holds.
+	DefaultBufferDuration = time.Second
+)
+
+var (
+	// Size of each buffer, either as a number of frames or as a length of time. Only one of these
+	// is set at a time.
+	bufferFrames      int
+	bufferDuration    = DefaultBufferDuration
+	bufferFramesMutex sync.RWMutex
+	bufferFramesKey   bufferFramesCtxKey
+)
+
+type bufferFramesCtxKey struct{}
+
+// BufferFrames returns the number of frames (tones) in a buffer for this context, or 0 if no value
+// is set.
+func BufferFrames(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+
+	if v := ctx.Value(bufferFramesKey); v != nil {
+		if n, ok := v.(int); ok && n > 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// SetBufferFrames sets the global size of a buffer to a fixed number of frames, no matter what the
+// sample rate is. This is useful for real-time playback, which needs small blocks of audio to keep
+// latency low. All contexts created after this is called will use the value set here. The number
+// of frames must be positive.
+func SetBufferFrames(n int) {
+	bufferFramesMutex.Lock()
+	defer bufferFramesMutex.Unlock()
+
+	if n > 0 {
+		bufferFrames = n
+		bufferDuration = 0
+	}
+}
+
+// SetBufferDuration sets the global size of a buffer to a length of time. The number of frames in
+// each buffer is the number of samples in that length of time at the context's sample rate,
+// rounded to the nearest frame, but never less than one frame. All contexts created after this is
+// called will use the value set here. The duration must be positive.
+func SetBufferDuration(d time.Duration) {
+	bufferFramesMutex.Lock()
+	defer bufferFramesMutex.Unlock()
+
+	if d > 0 {
+		bufferFrames = 0
+		bufferDuration = d
+	}
+}
+
+// numFrames returns the number of frames in a buffer at the specified sample rate, according to the
+// global size of a buffer.
+func numFrames(sampleRate int) int {
+	bufferFramesMutex.RLock()
+	defer bufferFramesMutex.RUnlock()
+
+	if bufferFrames > 0 {
+		return bufferFrames
+	}
+
+	if sampleRate <= 0 {
+		return 0
+	}
+
+	n := int(math.Round(bufferDuration.Seconds() * float64(sampleRate)))
+
+	return max(n, 1)
+}
@@ -0,0 +1,43 @@
+package buffer_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+)
+
+func ExampleSetBufferFrames() {
+	defer buffer.SetBufferDuration(buffer.DefaultBufferDuration)
+
+	// Use small buffers for real-time playback.
+	buffer.SetBufferFrames(256)
+	ctx := context.NewContext()
+
+	buf := buffer.BufferPool(ctx).Get()
+	defer buf.Release()
+
+	fmt.Println(buffer.BufferFrames(ctx), len(buf.Tones))
+
+	// Output:
+	// 256 256
+}
+
+func ExampleSetBufferDuration() {
+	defer buffer.SetBufferDuration(buffer.DefaultBufferDuration)
+
+	for _, d := range []time.Duration{10 * time.Millisecond, buffer.DefaultBufferDuration, 5 * time.Second} {
+		buffer.SetBufferDuration(d)
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 48_000,
+		})
+
+		fmt.Println(d, buffer.BufferFrames(ctx))
+	}
+
+	// Output:
+	// 10ms 480
+	// 1s 48000
+	// 5s 240000
+}
@@ -0,0 +1,157 @@
+package buffer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_BufferFrames tests that BufferFrames returns the correct number of frames in a buffer for
+// the given context.
+func Test_BufferFrames(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		require.Zero(t, BufferFrames(nil))
+	})
+
+	t.Run("no value set", func(t *testing.T) {
+		ctx := context.NewTestContext()
+		require.Zero(t, BufferFrames(ctx))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, v := range []any{uint64(20), "20", 20.0, 0, -1} {
+			ctx := context.NewTestContext()
+			ctx = ctx.WithValue(bufferFramesKey, v)
+			require.Zero(t, BufferFrames(ctx))
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		for _, sampleRate := range []int{1, 8_000, context.DefaultSampleRate, 96_000} {
+			ctx := context.NewContextWith(context.ContextOptions{
+				SampleRate: sampleRate,
+			})
+			require.Equal(t, sampleRate, BufferFrames(ctx))
+		}
+	})
+}
+
+// Test_SetBufferFrames tests that SetBufferFrames sets the global number of frames in a buffer.
+func Test_SetBufferFrames(t *testing.T) {
+	defer SetBufferDuration(DefaultBufferDuration)
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, n := range []int{0, -1, -1_000} {
+			SetBufferFrames(n)
+
+			ctx := context.NewContext()
+			require.Equal(t, context.DefaultSampleRate, BufferFrames(ctx))
+		}
+	})
+
+	t.Run("valid values", func(t *testing.T) {
+		for _, n := range []int{1, 64, 1_024, 1_000_000} {
+			SetBufferFrames(n)
+
+			// The number of frames doesn't depend on the sample rate.
+			for _, sampleRate := range []int{8_000, 96_000} {
+				ctx := context.NewContextWith(context.ContextOptions{
+					SampleRate: sampleRate,
+				})
+				require.Equal(t, n, BufferFrames(ctx))
+
+				buffer := NewBuffer(ctx)
+				require.Len(t, buffer.Tones, n)
+				buffer.Release()
+			}
+		}
+	})
+}
+
+// Test_SetBufferDuration tests that SetBufferDuration sets the global length of time in a buffer.
+func Test_SetBufferDuration(t *testing.T) {
+	defer SetBufferDuration(DefaultBufferDuration)
+
+	type test struct {
+		duration   time.Duration
+		sampleRate int
+		want       int
+	}
+
+	for _, subtest := range []test{
+		{time.Second, 44_100, 44_100},
+		{2 * time.Second, 48_000, 96_000},
+		{10 * time.Millisecond, 48_000, 480},
+		{time.Second / 3, 1_000, 333},
+		{time.Millisecond + 600*time.Microsecond, 1_000, 2},
+		{time.Nanosecond, 44_100, 1},
+	} {
+		SetBufferDuration(subtest.duration)
+
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: subtest.sampleRate,
+		})
+		require.Equal(t, subtest.want, BufferFrames(ctx), "%+v", subtest)
+		require.Zero(t, numFrames(0))
+	}
+
+	t.Run("invalid values", func(t *testing.T) {
+		SetBufferDuration(time.Millisecond)
+		for _, d := range []time.Duration{0, -time.Second} {
+			SetBufferDuration(d)
+
+			ctx := context.NewContextWith(context.ContextOptions{
+				SampleRate: 1_000,
+			})
+			require.Equal(t, 1, BufferFrames(ctx))
+		}
+	})
+
+	t.Run("replaces frames", func(t *testing.T) {
+		SetBufferFrames(100)
+		SetBufferDuration(time.Millisecond)
+
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 48_000,
+		})
+		require.Equal(t, 48, BufferFrames(ctx))
+
+		SetBufferFrames(100)
+		ctx = context.NewContextWith(context.ContextOptions{
+			SampleRate: 48_000,
+		})
+		require.Equal(t, 100, BufferFrames(ctx))
+	})
+}
+
+// Test_BufferFrames_Concurrency tests that it's safe to concurrently get and set the global buffer
+// size.
+func Test_BufferFrames_Concurrency(t *testing.T) {
+	defer SetBufferDuration(DefaultBufferDuration)
+
+	var wg sync.WaitGroup
+	for i := range 1_000 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			switch i % 3 {
+			case 0:
+				ctx := context.NewContextWith(context.ContextOptions{
+					SampleRate: 100,
+				})
+				BufferFrames(ctx)
+			case 1:
+				SetBufferFrames(i)
+			case 2:
+				SetBufferDuration(time.Duration(i) * time.Millisecond)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,177 @@
+package buffer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Layout is the arrangement of speakers that a buffer renders audio for. Rendered samples are
+// interleaved one frame at a time, with the channels in each frame in the standard WAV order.
+type Layout int
+
+const (
+	// LayoutMono has a single channel.
+	LayoutMono Layout = iota + 1
+	// LayoutStereo has left and right channels.
+	LayoutStereo
+	// LayoutQuad has front left, front right, rear left, and rear right channels.
+	LayoutQuad
+	// Layout5_1 has front left, front right, center, low-frequency effects, rear left, and rear
+	// right channels. Tones are never panned into the low-frequency effects channel, which is
+	// always silent.
+	Layout5_1
+)
+
+const (
+	// DefaultLayout is the default channel layout of a buffer.
+	DefaultLayout = LayoutMono
+)
+
+// Channels returns the number of channels in the layout, or 0 if the layout is not valid.
+func (layout Layout) Channels() int {
+	switch layout {
+	case LayoutMono:
+		return 1
+	case LayoutStereo:
+		return 2
+	case LayoutQuad:
+		return 4
+	case Layout5_1:
+		return 6
+	}
+
+	return 0
+}
+
+// String returns the name of the layout.
+func (layout Layout) String() string {
+	switch layout {
+	case LayoutMono:
+		return "mono"
+	case LayoutStereo:
+		return "stereo"
+	case LayoutQuad:
+		return "quad"
+	case Layout5_1:
+		return "5.1"
+	}
+
+	return ""
+}
+
+// Gains fills gains with the gain of each channel in the layout for a tone at the specified
+// position, using the pan law to split the tone between neighboring speakers. gains must have at
+// least as many elements as the layout has channels.
+func (layout Layout) Gains(position Position, law PanLaw, gains []float32) {
+	pan := clamp(position.Pan, -1, 1)
+	rear := clamp(position.Rear, 0, 1)
+
+	switch layout {
+	case LayoutMono:
+		gains[0] = 1
+
+	case LayoutStereo:
+		gains[0], gains[1] = law.Gains((pan + 1) / 2)
+
+	case LayoutQuad:
+		left, right := law.Gains((pan + 1) / 2)
+		front, back := law.Gains(rear)
+		gains[0], gains[1] = front*left, front*right
+		gains[2], gains[3] = back*left, back*right
+
+	case Layout5_1:
+		// Across the front, tones move from the left speaker to the center speaker to the right
+		// speaker.
+		var frontLeft, center, frontRight float32
+		if pan < 0 {
+			frontLeft, center = law.Gains(pan + 1)
+		} else {
+			center, frontRight = law.Gains(pan)
+		}
+		left, right := law.Gains((pan + 1) / 2)
+		front, back := law.Gains(rear)
+		gains[0], gains[1], gains[2], gains[3] = front*frontLeft, front*frontRight, front*center, 0
+		gains[4], gains[5] = back*left, back*right
+	}
+}
+
+// A PanLaw is the way that a tone's level is split between two speakers as it moves from one to
+// the other.
+type PanLaw int
+
+const (
+	// PanLawConstantPower keeps the total power of the two speakers the same at every position, so
+	// a tone sounds equally loud as it moves. A tone halfway between the speakers plays at -3dB in
+	// each of them.
+	PanLawConstantPower PanLaw = iota
+	// PanLawLinear keeps the sum of the two speakers' gains the same at every position. A tone
+	// halfway between the speakers plays at -6dB in each of them, so it sounds quieter than it does
+	// at either speaker.
+	PanLawLinear
+)
+
+// Gains returns the gains of the first and second speakers for a tone at position p, where 0 is
+// entirely in the first speaker and 1 is entirely in the second speaker.
+func (law PanLaw) Gains(p float32) (float32, float32) {
+	p = clamp(p, 0, 1)
+
+	switch law {
+	case PanLawLinear:
+		return 1 - p, p
+	default:
+		angle := float64(p) * math.Pi / 2
+		return float32(math.Cos(angle)), float32(math.Sin(angle))
+	}
+}
+
+// A Position places a tone around the listener. The zero value is directly in front of the
+// listener.
+type Position struct {
+	// Pan moves the tone from left (-1) to right (1).
+	Pan float32
+
+	// Rear moves the tone from the front (0) to the back (1). It's only used by layouts with rear
+	// speakers.
+	Rear float32
+}
+
+// clamp limits v to the range lo to hi.
+func clamp(v, lo, hi float32) float32 {
+	return min(max(v, lo), hi)
+}
+
+var (
+	layout      = DefaultLayout
+	layoutMutex sync.RWMutex
+	layoutKey   layoutCtxKey
+)
+
+type layoutCtxKey struct{}
+
+// BufferLayout returns the channel layout of a buffer for this context, or 0 if no value is set.
+func BufferLayout(ctx context.Context) Layout {
+	if ctx == nil {
+		return 0
+	}
+
+	if v := ctx.Value(layoutKey); v != nil {
+		if l, ok := v.(Layout); ok && l.Channels() > 0 {
+			return l
+		}
+	}
+
+	return 0
+}
+
+// SetBufferLayout sets the global channel layout of a buffer. All contexts created after this is
+// called will use the value set here. Invalid layouts are ignored.
+func SetBufferLayout(l Layout) {
+	layoutMutex.Lock()
+	defer layoutMutex.Unlock()
+
+	if l.Channels() > 0 {
+		layout = l
+	}
+}
@@ -0,0 +1,177 @@
+package buffer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Layout_Channels tests that Layout's Channels method returns the number of channels in each
+// layout.
+func Test_Layout_Channels(t *testing.T) {
+	for layout, want := range map[Layout]int{
+		0:             0,
+		LayoutMono:    1,
+		LayoutStereo:  2,
+		LayoutQuad:    4,
+		Layout5_1:     6,
+		Layout5_1 + 1: 0,
+		-1:            0,
+	} {
+		require.Equal(t, want, layout.Channels(), layout)
+	}
+}
+
+// Test_Layout_String tests that Layout's String method returns the name of each layout.
+func Test_Layout_String(t *testing.T) {
+	for layout, want := range map[Layout]string{
+		0:             "",
+		LayoutMono:    "mono",
+		LayoutStereo:  "stereo",
+		LayoutQuad:    "quad",
+		Layout5_1:     "5.1",
+		Layout5_1 + 1: "",
+	} {
+		require.Equal(t, want, layout.String())
+	}
+}
+
+// Test_Layout_Gains tests that Layout's Gains method pans a tone across the speakers in each
+// layout.
+func Test_Layout_Gains(t *testing.T) {
+	const h = math.Sqrt2 / 2
+
+	type test struct {
+		layout   Layout
+		position Position
+		law      PanLaw
+		want     []float32
+	}
+
+	for _, subtest := range []test{
+		{LayoutMono, Position{}, PanLawConstantPower, []float32{1}},
+		{LayoutMono, Position{Pan: -1, Rear: 1}, PanLawLinear, []float32{1}},
+
+		{LayoutStereo, Position{}, PanLawConstantPower, []float32{h, h}},
+		{LayoutStereo, Position{}, PanLawLinear, []float32{0.5, 0.5}},
+		{LayoutStereo, Position{Pan: -1}, PanLawConstantPower, []float32{1, 0}},
+		{LayoutStereo, Position{Pan: 1}, PanLawConstantPower, []float32{0, 1}},
+		{LayoutStereo, Position{Pan: 0.5}, PanLawLinear, []float32{0.25, 0.75}},
+		{LayoutStereo, Position{Pan: -5}, PanLawLinear, []float32{1, 0}},
+		{LayoutStereo, Position{Pan: 5, Rear: 1}, PanLawLinear, []float32{0, 1}},
+
+		{LayoutQuad, Position{}, PanLawConstantPower, []float32{h, h, 0, 0}},
+		{LayoutQuad, Position{Pan: -1, Rear: 1}, PanLawConstantPower, []float32{0, 0, 1, 0}},
+		{LayoutQuad, Position{Pan: 1, Rear: 0.5}, PanLawLinear, []float32{0, 0.5, 0, 0.5}},
+		{LayoutQuad, Position{Rear: 0.5}, PanLawLinear, []float32{0.25, 0.25, 0.25, 0.25}},
+
+		{Layout5_1, Position{}, PanLawConstantPower, []float32{0, 0, 1, 0, 0, 0}},
+		{Layout5_1, Position{Pan: -1}, PanLawConstantPower, []float32{1, 0, 0, 0, 0, 0}},
+		{Layout5_1, Position{Pan: 1}, PanLawConstantPower, []float32{0, 1, 0, 0, 0, 0}},
+		{Layout5_1, Position{Pan: -0.5}, PanLawLinear, []float32{0.5, 0, 0.5, 0, 0, 0}},
+		{Layout5_1, Position{Pan: 0.5}, PanLawConstantPower, []float32{0, h, h, 0, 0, 0}},
+		{Layout5_1, Position{Rear: 1}, PanLawConstantPower, []float32{0, 0, 0, 0, h, h}},
+		{Layout5_1, Position{Pan: -1, Rear: 0.5}, PanLawLinear, []float32{0.5, 0, 0, 0, 0.5, 0}},
+	} {
+		gains := make([]float32, subtest.layout.Channels())
+		subtest.layout.Gains(subtest.position, subtest.law, gains)
+		require.InDeltaSlice(t, subtest.want, gains, 1e-6, "%+v", subtest)
+	}
+}
+
+// Test_PanLaw_Gains tests that PanLaw's Gains method splits a tone between two speakers.
+func Test_PanLaw_Gains(t *testing.T) {
+	t.Run("constant power", func(t *testing.T) {
+		for i := range 101 {
+			p := float32(i) / 100
+			first, second := PanLawConstantPower.Gains(p)
+			require.InDelta(t, 1, first*first+second*second, 1e-6, p)
+		}
+
+		first, second := PanLawConstantPower.Gains(0.5)
+		require.InDelta(t, -3.01, 20*math.Log10(float64(first)), 1e-2)
+		require.Equal(t, first, second)
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		for i := range 101 {
+			p := float32(i) / 100
+			first, second := PanLawLinear.Gains(p)
+			require.InDelta(t, 1, first+second, 1e-6, p)
+			require.InDelta(t, p, second, 1e-6)
+		}
+
+		first, second := PanLawLinear.Gains(0.5)
+		require.InDelta(t, -6.02, 20*math.Log10(float64(first)), 1e-2)
+		require.Equal(t, first, second)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		for _, law := range []PanLaw{PanLawConstantPower, PanLawLinear} {
+			first, second := law.Gains(-1)
+			require.Equal(t, float32(1), first)
+			require.Zero(t, second)
+
+			first, second = law.Gains(2)
+			require.InDelta(t, 0, first, 1e-7)
+			require.Equal(t, float32(1), second)
+		}
+	})
+}
+
+// Test_BufferLayout tests that BufferLayout returns the channel layout of a buffer for the given
+// context.
+func Test_BufferLayout(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		require.Zero(t, BufferLayout(nil))
+	})
+
+	t.Run("no value set", func(t *testing.T) {
+		ctx := context.NewTestContext()
+		require.Zero(t, BufferLayout(ctx))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, v := range []any{int(LayoutStereo), "stereo", Layout(0), Layout(-1), Layout5_1 + 1} {
+			ctx := context.NewTestContext()
+			ctx = ctx.WithValue(layoutKey, v)
+			require.Zero(t, BufferLayout(ctx))
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		ctx := context.NewContext()
+		require.Equal(t, LayoutMono, BufferLayout(ctx))
+	})
+}
+
+// Test_SetBufferLayout tests that SetBufferLayout sets the global channel layout of a buffer.
+func Test_SetBufferLayout(t *testing.T) {
+	defer SetBufferLayout(DefaultLayout)
+
+	for _, layout := range []Layout{LayoutStereo, LayoutQuad, Layout5_1, LayoutMono} {
+		SetBufferLayout(layout)
+
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 100,
+		})
+		require.Equal(t, layout, BufferLayout(ctx))
+
+		buffer := NewBuffer(ctx)
+		require.Equal(t, layout, buffer.Layout)
+		require.Len(t, buffer.Positions, 100)
+		buffer.Release()
+	}
+
+	t.Run("invalid values", func(t *testing.T) {
+		SetBufferLayout(LayoutQuad)
+		for _, layout := range []Layout{0, -1, Layout5_1 + 1} {
+			SetBufferLayout(layout)
+
+			ctx := context.NewContext()
+			require.Equal(t, LayoutQuad, BufferLayout(ctx))
+		}
+	})
+}
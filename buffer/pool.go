@@ -0,0 +1,308 @@
+package buffer
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/green-aloe/enobox/tone"
+	"github.com/green-aloe/utilities/pool"
+)
+
+var (
+	// ErrPoolExhausted is returned when a pool has already allocated as many buffers as its limit
+	// allows and none of them have been released.
+	ErrPoolExhausted = errors.New("buffer: pool exhausted")
+)
+
+// A Pool holds buffers that all have the same configuration so that they can be reused instead of
+// allocated again. Every context has a pool that matches its configuration, which BufferPool
+// returns. A Pool is safe for concurrent use by multiple goroutines.
+//
+// A pool can limit how many buffers it allocates. Once it reaches its limit, Get waits for another
+// buffer to be released, and TryGet returns ErrPoolExhausted.
+type Pool struct {
+	// Idle buffers that are ready to be handed out
+	idle pool.Pool[Buffer]
+
+	// Allocates a new buffer for the pool
+	newBuffer func() Buffer
+
+	// Configuration that every buffer in the pool has
+	key bufferPoolsKey
+
+	mutex     sync.Mutex
+	available sync.Cond
+	limit     int
+	evicted   bool
+	gets      int
+	puts      int
+	allocated int
+	bytes     int64
+}
+
+// PoolStats describes the configuration of a pool's buffers and how the pool is being used.
+type PoolStats struct {
+	// Configuration of every buffer in the pool
+	SampleRate   int
+	Frames       int
+	Voices       int
+	Layout       Layout
+	NumHarmGains int
+
+	// Gets is the number of buffers that have been handed out by the pool.
+	Gets int
+
+	// Puts is the number of buffers that have been released back to the pool.
+	Puts int
+
+	// Live is the number of buffers that the pool has allocated and that haven't been released.
+	Live int
+
+	// Idle is the number of buffers in the pool that are ready to be handed out.
+	Idle int
+
+	// Allocated is the number of buffers that the pool is keeping track of, live and idle.
+	Allocated int
+
+	// Bytes is the approximate amount of memory held by all of the pool's allocated buffers.
+	Bytes int64
+
+	// Limit is the maximum number of buffers that the pool will allocate, or 0 for no limit.
+	Limit int
+}
+
+// newPool creates a new pool that allocates buffers with newBuffer.
+func newPool(key bufferPoolsKey, newBuffer func() Buffer) *Pool {
+	p := &Pool{
+		newBuffer: newBuffer,
+		key:       key,
+	}
+	p.idle.PreStore = func(buffer Buffer) Buffer { buffer.Reset(); return buffer }
+	p.available.L = &p.mutex
+
+	return p
+}
+
+// Get returns an idle buffer from the pool, or allocates a new one if there aren't any. If the
+// pool has already reached its limit, this waits until another buffer is released.
+func (p *Pool) Get() Buffer {
+	buffer, _ := p.get(true)
+	return buffer
+}
+
+// TryGet returns an idle buffer from the pool, or allocates a new one if there aren't any. If the
+// pool has already reached its limit, this returns ErrPoolExhausted instead of waiting.
+func (p *Pool) TryGet() (Buffer, error) {
+	return p.get(false)
+}
+
+// get returns a buffer from the pool, either waiting for one or returning an error if the pool is
+// exhausted.
+func (p *Pool) get(wait bool) (Buffer, error) {
+	if p == nil {
+		return Buffer{}, nil
+	}
+
+	p.mutex.Lock()
+	for p.idle.Count() == 0 && p.limit > 0 && p.allocated >= p.limit {
+		if !wait {
+			p.mutex.Unlock()
+			return Buffer{}, ErrPoolExhausted
+		}
+		p.available.Wait()
+	}
+	p.gets++
+
+	if p.idle.Count() > 0 {
+		buffer := p.idle.Get()
+		p.mutex.Unlock()
+		buffer.id = trackBuffer()
+		return buffer, nil
+	}
+
+	// Reserve the new buffer before unlocking so that other goroutines see the limit, and then
+	// allocate it without holding the lock.
+	p.allocated++
+	p.mutex.Unlock()
+
+	var buffer Buffer
+	if p.newBuffer != nil {
+		buffer = p.newBuffer()
+	}
+
+	p.mutex.Lock()
+	p.bytes += bufferBytes(buffer)
+	p.mutex.Unlock()
+
+	buffer.id = trackBuffer()
+
+	return buffer, nil
+}
+
+// Store returns the buffer to the pool so that it can be handed out again. The buffer is reset
+// first. If the pool has been evicted, the buffer's memory is released instead.
+func (p *Pool) Store(buffer Buffer) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.puts++
+	if p.evicted {
+		// Freeing the buffer makes room under the limit.
+		p.free(buffer)
+		p.available.Signal()
+		return
+	}
+
+	p.idle.Store(buffer)
+	p.available.Signal()
+}
+
+// Count returns the number of idle buffers in the pool.
+func (p *Pool) Count() int {
+	if p == nil {
+		return 0
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.idle.Count()
+}
+
+// Clear releases the memory of every idle buffer in the pool. It's the same as Drain.
+func (p *Pool) Clear() {
+	p.Drain()
+}
+
+// Drain releases the memory of every idle buffer in the pool and returns how many were released.
+// Buffers that are still in use are not affected.
+func (p *Pool) Drain() int {
+	if p == nil {
+		return 0
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.drain()
+}
+
+// drain releases every idle buffer. The pool must be locked.
+func (p *Pool) drain() int {
+	n := p.idle.Count()
+	for range n {
+		p.free(p.idle.Get())
+	}
+
+	// Releasing buffers makes room under the limit.
+	p.available.Broadcast()
+
+	return n
+}
+
+// free releases a buffer's memory and stops keeping track of it. The pool must be locked.
+func (p *Pool) free(buffer Buffer) {
+	p.allocated--
+	p.bytes -= bufferBytes(buffer)
+
+	if buffer.backend != nil {
+		buffer.backend.Release(&buffer)
+	}
+}
+
+// track starts keeping track of a buffer that was allocated for the pool outside of Get.
+func (p *Pool) track(buffer Buffer) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.allocated++
+	p.bytes += bufferBytes(buffer)
+}
+
+// Limit returns the maximum number of buffers that the pool will allocate, or 0 if there is no
+// limit.
+func (p *Pool) Limit() int {
+	if p == nil {
+		return 0
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.limit
+}
+
+// SetLimit sets the maximum number of buffers, live and idle, that the pool will allocate. A limit
+// of 0 removes the limit. Buffers that were already allocated are kept even if there are more of
+// them than the new limit allows. Negative limits are ignored.
+func (p *Pool) SetLimit(n int) {
+	if p == nil || n < 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.limit = n
+	p.available.Broadcast()
+}
+
+// Stats returns the pool's current statistics.
+func (p *Pool) Stats() PoolStats {
+	if p == nil {
+		return PoolStats{}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	idle := p.idle.Count()
+
+	return PoolStats{
+		SampleRate:   p.key.sampleRate,
+		Frames:       p.key.numFrames,
+		Voices:       p.key.numVoices,
+		Layout:       p.key.layout,
+		NumHarmGains: p.key.numHarmGains,
+		Gets:         p.gets,
+		Puts:         p.puts,
+		Live:         p.allocated - idle,
+		Idle:         idle,
+		Allocated:    p.allocated,
+		Bytes:        p.bytes,
+		Limit:        p.limit,
+	}
+}
+
+// evict drains the pool and marks it so that buffers released to it later are freed instead of
+// kept.
+func (p *Pool) evict() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.evicted = true
+	p.drain()
+}
+
+// bufferBytes returns the approximate amount of memory held by the buffer.
+func bufferBytes(buffer Buffer) int64 {
+	var f32 float32
+	n := int64(cap(buffer.Tones)) * int64(unsafe.Sizeof(tone.Tone{}))
+	for i := range buffer.Tones {
+		t := &buffer.Tones[i]
+		n += int64(cap(t.HarmonicGains)+cap(t.HarmonicPhases)+cap(t.PartialRatios)) * int64(unsafe.Sizeof(f32))
+	}
+	n += int64(cap(buffer.Positions)) * int64(unsafe.Sizeof(Position{}))
+	n += int64(cap(buffer.VoiceGains)) * int64(unsafe.Sizeof(f32))
+
+	return n
+}
@@ -0,0 +1,346 @@
+package buffer
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// testPool creates a pool that isn't registered for any context and allocates buffers for the
+// context.
+func testPool(ctx context.Context) *Pool {
+	var p *Pool
+	p = newPool(bufferPoolsKey{sampleRate: ctx.SampleRate()}, func() Buffer { return newBuffer(ctx, p) })
+
+	return p
+}
+
+// Test_Pool_Get tests that Pool's Get method reuses idle buffers and allocates new ones when there
+// aren't any.
+func Test_Pool_Get(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var p *Pool
+		require.NotPanics(t, func() { p.Get() })
+		require.Empty(t, p.Get().Tones)
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 100,
+	})
+	p := testPool(ctx)
+
+	first := p.Get()
+	require.Len(t, first.Tones, 100)
+	require.Same(t, p, first.pool)
+	second := p.Get()
+	require.NotSame(t, &first.Tones[0], &second.Tones[0])
+
+	stats := p.Stats()
+	require.Equal(t, 2, stats.Gets)
+	require.Equal(t, 2, stats.Live)
+	require.Equal(t, 2, stats.Allocated)
+	require.Zero(t, stats.Idle)
+
+	// Released buffers are reset and handed out again.
+	first.Tones[0].Frequency = 440
+	tones := first.Tones
+	first.Release()
+	require.Equal(t, 1, p.Count())
+
+	third := p.Get()
+	require.Same(t, &tones[0], &third.Tones[0])
+	require.True(t, third.Tones[0].Empty())
+
+	stats = p.Stats()
+	require.Equal(t, 3, stats.Gets)
+	require.Equal(t, 1, stats.Puts)
+	require.Equal(t, 2, stats.Live)
+	require.Equal(t, 2, stats.Allocated)
+}
+
+// Test_Pool_TryGet tests that Pool's TryGet method returns an error once the pool reaches its
+// limit.
+func Test_Pool_TryGet(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var p *Pool
+		buffer, err := p.TryGet()
+		require.NoError(t, err)
+		require.Empty(t, buffer.Tones)
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	p := testPool(ctx)
+	p.SetLimit(2)
+
+	first, err := p.TryGet()
+	require.NoError(t, err)
+	second, err := p.TryGet()
+	require.NoError(t, err)
+
+	_, err = p.TryGet()
+	require.ErrorIs(t, err, ErrPoolExhausted)
+	require.Equal(t, 2, p.Stats().Gets)
+
+	// Releasing a buffer makes it available again.
+	first.Release()
+	third, err := p.TryGet()
+	require.NoError(t, err)
+	require.Len(t, third.Tones, 10)
+
+	// Raising the limit makes room for more buffers.
+	p.SetLimit(3)
+	fourth, err := p.TryGet()
+	require.NoError(t, err)
+
+	// Removing the limit allows any number of buffers.
+	p.SetLimit(0)
+	for range 10 {
+		_, err := p.TryGet()
+		require.NoError(t, err)
+	}
+	require.Equal(t, 13, p.Stats().Allocated)
+
+	second.Release()
+	fourth.Release()
+}
+
+// Test_Pool_Get_limit tests that Pool's Get method waits for a buffer to be released once the pool
+// reaches its limit.
+func Test_Pool_Get_limit(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	p := testPool(ctx)
+	p.SetLimit(1)
+
+	first := p.Get()
+
+	got := make(chan Buffer)
+	go func() {
+		got <- p.Get()
+	}()
+
+	select {
+	case <-got:
+		t.Fatal("Get returned before a buffer was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tones := first.Tones
+	first.Release()
+
+	select {
+	case second := <-got:
+		require.Same(t, &tones[0], &second.Tones[0])
+	case <-time.After(5 * time.Second):
+		t.Fatal("Get didn't return after a buffer was released")
+	}
+
+	t.Run("new buffer", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 32_109,
+		})
+		p := BufferPool(ctx)
+		p.SetLimit(1)
+
+		// NewBuffer doesn't wait for the limit, even when the pool is already at it.
+		first := p.Get()
+		other := NewBuffer(ctx)
+		first.Release()
+
+		// The buffer from NewBuffer still counts towards the limit until it's released.
+		buffer, err := p.TryGet()
+		require.NoError(t, err)
+		_, err = p.TryGet()
+		require.ErrorIs(t, err, ErrPoolExhausted)
+
+		buffer.Release()
+		other.Release()
+		require.Equal(t, 2, p.Count())
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		p := testPool(ctx)
+		p.SetLimit(3)
+
+		var mutex sync.Mutex
+		var live, maxLive int
+
+		var wg sync.WaitGroup
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				buffer := p.Get()
+
+				mutex.Lock()
+				live++
+				maxLive = max(maxLive, live)
+				mutex.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				live--
+				mutex.Unlock()
+
+				buffer.Release()
+			}()
+		}
+		wg.Wait()
+
+		require.LessOrEqual(t, maxLive, 3)
+		stats := p.Stats()
+		require.Equal(t, 100, stats.Gets)
+		require.Equal(t, 100, stats.Puts)
+		require.Zero(t, stats.Live)
+		require.LessOrEqual(t, stats.Allocated, 3)
+	})
+
+	t.Run("evicted", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 43_210,
+		})
+		p := BufferPool(ctx)
+		p.SetLimit(1)
+
+		first := p.Get()
+
+		got := make(chan Buffer)
+		go func() {
+			got <- p.Get()
+		}()
+
+		select {
+		case <-got:
+			t.Fatal("Get returned before a buffer was released")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// The evicted pool frees the buffer instead of keeping it, which still makes room for
+		// another one.
+		require.True(t, EvictBufferPool(ctx))
+		select {
+		case <-got:
+			t.Fatal("Get returned before a buffer was released")
+		case <-time.After(50 * time.Millisecond):
+		}
+		first.Release()
+
+		select {
+		case second := <-got:
+			require.NotEmpty(t, second.Tones)
+			second.Release()
+		case <-time.After(5 * time.Second):
+			t.Fatal("Get didn't return after a buffer was released to an evicted pool")
+		}
+	})
+}
+
+// Test_Pool_Drain tests that Pool's Drain method releases every idle buffer.
+func Test_Pool_Drain(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var p *Pool
+		require.Zero(t, p.Drain())
+		require.NotPanics(t, func() { p.Clear() })
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10,
+	})
+	p := testPool(ctx)
+	p.SetLimit(3)
+
+	buffers := []Buffer{p.Get(), p.Get(), p.Get()}
+	buffers[0].Release()
+	buffers[1].Release()
+
+	require.Equal(t, 2, p.Drain())
+	require.Zero(t, p.Count())
+
+	stats := p.Stats()
+	require.Equal(t, 1, stats.Live)
+	require.Equal(t, 1, stats.Allocated)
+	require.Equal(t, bufferBytes(buffers[2]), stats.Bytes)
+
+	// Draining makes room under the limit.
+	_, err := p.TryGet()
+	require.NoError(t, err)
+
+	buffers[2].Release()
+	p.Clear()
+	require.Zero(t, p.Count())
+}
+
+// Test_Pool_SetLimit tests that Pool's SetLimit method sets the maximum number of buffers.
+func Test_Pool_SetLimit(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var p *Pool
+		require.NotPanics(t, func() { p.SetLimit(5) })
+		require.Zero(t, p.Limit())
+	})
+
+	p := testPool(context.NewContext())
+	require.Zero(t, p.Limit())
+
+	p.SetLimit(5)
+	require.Equal(t, 5, p.Limit())
+	require.Equal(t, 5, p.Stats().Limit)
+
+	p.SetLimit(-1)
+	require.Equal(t, 5, p.Limit())
+
+	p.SetLimit(0)
+	require.Zero(t, p.Limit())
+}
+
+// Test_Pool_Stats tests that Pool's Stats method describes the pool's configuration and memory.
+func Test_Pool_Stats(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var p *Pool
+		require.Zero(t, p.Stats())
+	})
+
+	defer SetNumVoices(DefaultNumVoices)
+	defer SetBufferLayout(DefaultLayout)
+	defer SetBufferDuration(DefaultBufferDuration)
+	SetNumVoices(2)
+	SetBufferLayout(LayoutStereo)
+	SetBufferFrames(64)
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 22_050,
+	})
+	p := BufferPool(ctx)
+	p.Drain()
+
+	stats := p.Stats()
+	require.Equal(t, 22_050, stats.SampleRate)
+	require.Equal(t, 64, stats.Frames)
+	require.Equal(t, 2, stats.Voices)
+	require.Equal(t, LayoutStereo, stats.Layout)
+	require.Equal(t, tone.DefaultNumHarmGains, stats.NumHarmGains)
+
+	before := stats.Bytes
+	buffer := p.Get()
+	defer buffer.Release()
+
+	var f32 float32
+	toneSize := int64(unsafe.Sizeof(tone.Tone{})) + tone.DefaultNumHarmGains*int64(unsafe.Sizeof(f32))
+	want := 128*toneSize + 128*int64(unsafe.Sizeof(Position{})) + 2*int64(unsafe.Sizeof(f32))
+	require.Equal(t, want, p.Stats().Bytes-before)
+
+	// Buffers allocated with NewBuffer count towards the pool that they're released to.
+	other := NewBuffer(ctx)
+	require.Equal(t, 2*want, p.Stats().Bytes-before)
+	require.Equal(t, stats.Allocated+2, p.Stats().Allocated)
+	other.Release()
+	require.Equal(t, 1, p.Stats().Idle)
+}
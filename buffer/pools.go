@@ -1,11 +1,12 @@
 package buffer
 
 import (
+	"cmp"
+	"slices"
 	"sync"
 
 	"github.com/green-aloe/enobox/context"
 	"github.com/green-aloe/enobox/tone"
-	"github.com/green-aloe/utilities/pool"
 )
 
 var (
@@ -13,21 +14,58 @@ var (
 	// latter can drop buffers at any time. That wouldn't be good for us because we might need to
 	// close the buffer before releasing it (so that non-go garbage collectors can also release
 	// their references to the memory).
-	bufferPools      = make(map[bufferPoolsKey]*pool.Pool[Buffer])
+	bufferPools      = make(map[bufferPoolsKey]*Pool)
 	bufferPoolsMutex sync.Mutex
 	bufferPoolKey    bufferPoolCtxKey
 )
 
 type bufferPoolsKey struct {
 	sampleRate   int
+	numFrames    int
+	numVoices    int
+	layout       Layout
 	numHarmGains int
+	backend      Backend
 }
 
 type bufferPoolCtxKey struct{}
 
 func init() {
+	// Add a context decorator that sets the global backend in each new context.
+	context.AddDecorator(func(ctx context.Context) context.Context {
+		backendMutex.Lock()
+		defer backendMutex.Unlock()
+
+		return ctx.WithValue(backendKey, backend)
+	})
+
+	// Add a context decorator that sets the number of frames in a buffer in each new context
+	// depending on the global buffer size and the context's sample rate.
+	context.AddDecorator(func(ctx context.Context) context.Context {
+		return ctx.WithValue(bufferFramesKey, numFrames(ctx.SampleRate()))
+	})
+
+	// Add a context decorator that sets the global number of voices in a buffer in each new
+	// context.
+	context.AddDecorator(func(ctx context.Context) context.Context {
+		numVoicesMutex.RLock()
+		defer numVoicesMutex.RUnlock()
+
+		return ctx.WithValue(numVoicesKey, numVoices)
+	})
+
+	// Add a context decorator that sets the global channel layout of a buffer in each new context.
+	context.AddDecorator(func(ctx context.Context) context.Context {
+		layoutMutex.RLock()
+		defer layoutMutex.RUnlock()
+
+		return ctx.WithValue(layoutKey, layout)
+	})
+
 	// Add a context decorator that sets a buffer pool in each new context depending on the sample
-	// rate and number of harmonic gains in a tone configured for the context.
+	// rate, size, voices, and channel layout of a buffer, number of harmonic gains in a tone, and
+	// backend configured for the context. The decorators above must run before this one, which is
+	// why they're added first.
 	//
 	// We need to add this decorator after the decorator that sets the number of harmonic gains,
 	// since this one uses the other one. This loose ordering is guaranteed because this decorator
@@ -39,15 +77,16 @@ func init() {
 
 		key := bufferPoolsKey{
 			sampleRate:   ctx.SampleRate(),
+			numFrames:    BufferFrames(ctx),
+			numVoices:    NumVoices(ctx),
+			layout:       BufferLayout(ctx),
 			numHarmGains: tone.NumHarmGains(ctx),
+			backend:      BufferBackend(ctx),
 		}
 
 		bufferPool, ok := bufferPools[key]
 		if !ok || bufferPool == nil {
-			bufferPool = &pool.Pool[Buffer]{
-				NewItem:  func() Buffer { return NewBuffer(ctx) },
-				PreStore: func(buffer Buffer) Buffer { buffer.Reset(); return buffer },
-			}
+			bufferPool = newPool(key, func() Buffer { return newBuffer(ctx, bufferPool) })
 			bufferPools[key] = bufferPool
 		}
 
@@ -56,16 +95,100 @@ func init() {
 }
 
 // BufferPool returns the buffer pool for this context, or nil if no pool is set.
-func BufferPool(ctx context.Context) *pool.Pool[Buffer] {
+//
+// BufferPool used to return a *pool.Pool[Buffer] from github.com/green-aloe/utilities/pool, which
+// couldn't keep statistics or limit its buffers. A *Pool has the same Get, Store, Count, and Clear
+// methods, so code that only calls those keeps working, but code that names the old type has to
+// name *Pool instead.
+func BufferPool(ctx context.Context) *Pool {
 	if ctx == nil {
 		return nil
 	}
 
 	if v := ctx.Value(bufferPoolKey); v != nil {
-		if p, ok := v.(*pool.Pool[Buffer]); ok {
+		if p, ok := v.(*Pool); ok {
 			return p
 		}
 	}
 
 	return nil
 }
+
+// BufferPoolStats returns the statistics of every buffer pool, ordered by their configurations.
+func BufferPoolStats() []PoolStats {
+	bufferPoolsMutex.Lock()
+	pools := make([]*Pool, 0, len(bufferPools))
+	for _, p := range bufferPools {
+		pools = append(pools, p)
+	}
+	bufferPoolsMutex.Unlock()
+
+	stats := make([]PoolStats, len(pools))
+	for i, p := range pools {
+		stats[i] = p.Stats()
+	}
+
+	slices.SortFunc(stats, func(a, b PoolStats) int {
+		return cmp.Or(
+			cmp.Compare(a.SampleRate, b.SampleRate),
+			cmp.Compare(a.Frames, b.Frames),
+			cmp.Compare(a.Voices, b.Voices),
+			cmp.Compare(a.Layout, b.Layout),
+			cmp.Compare(a.NumHarmGains, b.NumHarmGains),
+		)
+	})
+
+	return stats
+}
+
+// EvictBufferPool removes the context's buffer pool so that its memory can be reclaimed, and
+// reports if the pool was found. Every idle buffer in the pool is released right away, and buffers
+// that are still in use are released as soon as they're returned to the pool. Contexts created
+// afterwards with the same configuration get a new, empty pool.
+//
+// Contexts that already have the pool can still use it, but it no longer keeps any buffers.
+func EvictBufferPool(ctx context.Context) bool {
+	bufferPool := BufferPool(ctx)
+	if bufferPool == nil {
+		return false
+	}
+
+	bufferPoolsMutex.Lock()
+	defer bufferPoolsMutex.Unlock()
+
+	if bufferPools[bufferPool.key] != bufferPool {
+		return false
+	}
+
+	delete(bufferPools, bufferPool.key)
+	bufferPool.evict()
+
+	return true
+}
+
+// EvictUnusedBufferPools removes every buffer pool that doesn't have any buffers in use and returns
+// how many pools were removed. This is useful to reclaim memory after switching to a different
+// configuration, like a new sample rate. Every idle buffer in the removed pools is released right
+// away, and contexts created afterwards with the same configuration get a new, empty pool.
+//
+// Unlike EvictBufferPool, this can't tell if a context still has one of the pools, so contexts that
+// already have a removed pool can keep using it as before. The pool's memory is reclaimed by the
+// garbage collector once none of them are left. To stop pooling the buffers of a configuration
+// that's still in use, evict its pool explicitly with EvictBufferPool.
+func EvictUnusedBufferPools() int {
+	bufferPoolsMutex.Lock()
+	defer bufferPoolsMutex.Unlock()
+
+	var n int
+	for key, bufferPool := range bufferPools {
+		if bufferPool.Stats().Live > 0 {
+			continue
+		}
+
+		delete(bufferPools, key)
+		bufferPool.Drain()
+		n++
+	}
+
+	return n
+}
@@ -35,3 +35,32 @@ func ExampleBufferPool() {
 	// 48000 10
 	// 96000 100
 }
+
+func ExamplePool_TryGet() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 4_321,
+	})
+	pool := buffer.BufferPool(ctx)
+	pool.SetLimit(2)
+	defer pool.SetLimit(0)
+
+	first, _ := pool.TryGet()
+	second, _ := pool.TryGet()
+
+	_, err := pool.TryGet()
+	fmt.Println(err)
+
+	stats := pool.Stats()
+	fmt.Println(stats.Gets, stats.Live, stats.Idle)
+
+	first.Release()
+	second.Release()
+
+	stats = pool.Stats()
+	fmt.Println(stats.Puts, stats.Live, stats.Idle)
+
+	// Output:
+	// buffer: pool exhausted
+	// 2 2 0
+	// 2 0 2
+}
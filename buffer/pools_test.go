@@ -1,13 +1,14 @@
 package buffer
 
 import (
+	"cmp"
+	"slices"
 	"sort"
 	"sync"
 	"testing"
 
 	"github.com/green-aloe/enobox/context"
 	"github.com/green-aloe/enobox/tone"
-	"github.com/green-aloe/utilities/pool"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,7 +21,7 @@ func Test_init(t *testing.T) {
 		v := ctx.Value(bufferPoolKey)
 		require.NotNil(t, v)
 
-		bufferPool, ok := v.(*pool.Pool[Buffer])
+		bufferPool, ok := v.(*Pool)
 		require.True(t, ok)
 		require.NotNil(t, bufferPool)
 
@@ -51,7 +52,7 @@ func Test_init(t *testing.T) {
 				v := ctx.Value(bufferPoolKey)
 				require.NotNil(t, v)
 
-				bufferPool, ok := v.(*pool.Pool[Buffer])
+				bufferPool, ok := v.(*Pool)
 				require.True(t, ok)
 				require.NotNil(t, bufferPool)
 				require.Equal(t, i, bufferPool.Count())
@@ -75,8 +76,87 @@ func Test_init(t *testing.T) {
 		}
 	})
 
+	t.Run("buffer sizes", func(t *testing.T) {
+		defer SetBufferDuration(DefaultBufferDuration)
+
+		ctxs := make([]context.Context, 0, 3)
+		for _, numFrames := range []int{64, 1_024} {
+			SetBufferFrames(numFrames)
+			ctxs = append(ctxs, context.NewContextWith(context.ContextOptions{
+				SampleRate: 48_000,
+			}))
+		}
+		SetBufferDuration(DefaultBufferDuration)
+		ctxs = append(ctxs, context.NewContextWith(context.ContextOptions{
+			SampleRate: 48_000,
+		}))
+
+		// Each size gets its own pool.
+		for i, ctx := range ctxs {
+			for _, other := range ctxs[i+1:] {
+				require.NotSame(t, BufferPool(ctx), BufferPool(other))
+			}
+		}
+
+		for i, want := range []int{64, 1_024, 48_000} {
+			buffer := BufferPool(ctxs[i]).Get()
+			require.Len(t, buffer.Tones, want)
+			buffer.Release()
+		}
+
+		// Contexts with the same size share a pool.
+		SetBufferFrames(64)
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 48_000,
+		})
+		require.Same(t, BufferPool(ctxs[0]), BufferPool(ctx))
+	})
+
+	t.Run("voices", func(t *testing.T) {
+		defer SetNumVoices(DefaultNumVoices)
+
+		var pools []*Pool
+		for _, n := range []int{1, 2, 4} {
+			SetNumVoices(n)
+			ctx := context.NewContextWith(context.ContextOptions{
+				SampleRate: 300,
+			})
+
+			bufferPool := BufferPool(ctx)
+			require.NotContains(t, pools, bufferPool)
+			pools = append(pools, bufferPool)
+
+			buffer := bufferPool.Get()
+			require.Equal(t, n, buffer.NumVoices())
+			require.Len(t, buffer.Tones, 300*n)
+			buffer.Release()
+		}
+	})
+
+	t.Run("layouts", func(t *testing.T) {
+		defer SetBufferLayout(DefaultLayout)
+
+		var pools []*Pool
+		for _, layout := range []Layout{LayoutMono, LayoutStereo, LayoutQuad, Layout5_1} {
+			SetBufferLayout(layout)
+			ctx := context.NewContextWith(context.ContextOptions{
+				SampleRate: 500,
+			})
+
+			bufferPool := BufferPool(ctx)
+			require.NotContains(t, pools, bufferPool)
+			pools = append(pools, bufferPool)
+
+			buffer := bufferPool.Get()
+			require.Equal(t, layout, buffer.Layout)
+			require.Len(t, buffer.Tones, 500)
+			require.Len(t, buffer.Positions, 500)
+			buffer.Release()
+		}
+	})
+
 	t.Run("concurrency", func(t *testing.T) {
-		bufferPools = make(map[bufferPoolsKey]*pool.Pool[Buffer])
+		bufferPools = make(map[bufferPoolsKey]*Pool)
 
 		var wg sync.WaitGroup
 		for i := range 1_000 {
@@ -146,3 +226,116 @@ func Test_BufferPool(t *testing.T) {
 		require.NotNil(t, BufferPool(ctx))
 	})
 }
+
+// Test_BufferPoolStats tests that BufferPoolStats returns the statistics of every buffer pool in
+// order.
+func Test_BufferPoolStats(t *testing.T) {
+	defer SetNumVoices(DefaultNumVoices)
+
+	for _, n := range []int{3, 1} {
+		SetNumVoices(n)
+		_ = context.NewContextWith(context.ContextOptions{
+			SampleRate: 12_345,
+		})
+	}
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 12_345,
+	})
+	buffer := BufferPool(ctx).Get()
+	defer buffer.Release()
+
+	stats := BufferPoolStats()
+	require.True(t, slices.IsSortedFunc(stats, func(a, b PoolStats) int {
+		return cmp.Or(cmp.Compare(a.SampleRate, b.SampleRate), cmp.Compare(a.Frames, b.Frames), cmp.Compare(a.Voices, b.Voices))
+	}))
+
+	var found []PoolStats
+	for _, s := range stats {
+		if s.SampleRate == 12_345 {
+			found = append(found, s)
+		}
+	}
+	require.Len(t, found, 2)
+	require.Equal(t, 1, found[0].Voices)
+	require.Equal(t, 1, found[0].Live)
+	require.Positive(t, found[0].Bytes)
+	require.Equal(t, 3, found[1].Voices)
+	require.Zero(t, found[1].Live)
+}
+
+// Test_EvictBufferPool tests that EvictBufferPool removes a context's buffer pool and releases its
+// buffers.
+func Test_EvictBufferPool(t *testing.T) {
+	t.Run("no pool", func(t *testing.T) {
+		require.False(t, EvictBufferPool(nil))
+		require.False(t, EvictBufferPool(context.NewTestContext()))
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 54_321,
+	})
+	bufferPool := BufferPool(ctx)
+
+	idle := bufferPool.Get()
+	live := bufferPool.Get()
+	idle.Release()
+	require.Equal(t, 1, bufferPool.Count())
+
+	require.True(t, EvictBufferPool(ctx))
+	require.False(t, EvictBufferPool(ctx))
+	require.Zero(t, bufferPool.Count())
+
+	// Buffers returned after the pool is evicted are released instead of kept.
+	live.Release()
+	require.Zero(t, bufferPool.Count())
+	stats := bufferPool.Stats()
+	require.Zero(t, stats.Allocated)
+	require.Zero(t, stats.Bytes)
+
+	// New contexts get a new pool.
+	ctx2 := context.NewContextWith(context.ContextOptions{
+		SampleRate: 54_321,
+	})
+	require.NotSame(t, bufferPool, BufferPool(ctx2))
+	require.Zero(t, BufferPool(ctx2).Stats().Allocated)
+}
+
+// Test_EvictUnusedBufferPools tests that EvictUnusedBufferPools removes every buffer pool without
+// any buffers in use, and that contexts that still have one of those pools can keep using it.
+func Test_EvictUnusedBufferPools(t *testing.T) {
+	used := context.NewContextWith(context.ContextOptions{
+		SampleRate: 11_111,
+	})
+	unused := context.NewContextWith(context.ContextOptions{
+		SampleRate: 22_222,
+	})
+
+	sampleRates := func() []int {
+		var sampleRates []int
+		for _, stats := range BufferPoolStats() {
+			sampleRates = append(sampleRates, stats.SampleRate)
+		}
+		return sampleRates
+	}
+
+	buffer := BufferPool(used).Get()
+	other := BufferPool(unused).Get()
+	other.Release()
+
+	require.Positive(t, EvictUnusedBufferPools())
+	require.Zero(t, BufferPool(unused).Count())
+	require.Contains(t, sampleRates(), 11_111)
+	require.NotContains(t, sampleRates(), 22_222)
+
+	// The context that still has the removed pool keeps pooling its buffers.
+	other = BufferPool(unused).Get()
+	other.Release()
+	require.Equal(t, 1, BufferPool(unused).Count())
+
+	buffer.Release()
+	require.Positive(t, EvictUnusedBufferPools())
+	require.NotContains(t, sampleRates(), 11_111)
+	for _, stats := range BufferPoolStats() {
+		require.Positive(t, stats.Live)
+	}
+}
@@ -0,0 +1,48 @@
+package buffer
+
+import (
+	"sync"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+const (
+	// DefaultNumVoices is the default number of voices in a buffer.
+	DefaultNumVoices = 1
+)
+
+var (
+	// Number of tones that play at the same time in every frame of a buffer
+	numVoices      = DefaultNumVoices
+	numVoicesMutex sync.RWMutex
+	numVoicesKey   numVoicesCtxKey
+)
+
+type numVoicesCtxKey struct{}
+
+// NumVoices returns the number of voices in a buffer for this context, or 0 if no value is set.
+func NumVoices(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+
+	if v := ctx.Value(numVoicesKey); v != nil {
+		if n, ok := v.(int); ok && n > 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// SetNumVoices sets the global number of voices in a buffer, which is the number of tones that can
+// play at the same time, like the notes in a chord. All contexts created after this is called will
+// use the value set here. The number of voices must be positive.
+func SetNumVoices(n int) {
+	numVoicesMutex.Lock()
+	defer numVoicesMutex.Unlock()
+
+	if n > 0 {
+		numVoices = n
+	}
+}
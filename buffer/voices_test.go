@@ -0,0 +1,94 @@
+package buffer
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NumVoices tests that NumVoices returns the correct number of voices in a buffer for the
+// given context.
+func Test_NumVoices(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		require.Zero(t, NumVoices(nil))
+	})
+
+	t.Run("no value set", func(t *testing.T) {
+		ctx := context.NewTestContext()
+		require.Zero(t, NumVoices(ctx))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, v := range []any{uint64(4), "4", 4.0, 0, -1} {
+			ctx := context.NewTestContext()
+			ctx = ctx.WithValue(numVoicesKey, v)
+			require.Zero(t, NumVoices(ctx))
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		ctx := context.NewContext()
+		require.Equal(t, DefaultNumVoices, NumVoices(ctx))
+	})
+}
+
+// Test_SetNumVoices tests that SetNumVoices sets the global number of voices in a buffer.
+func Test_SetNumVoices(t *testing.T) {
+	defer SetNumVoices(DefaultNumVoices)
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, n := range []int{0, -1, -100} {
+			SetNumVoices(n)
+
+			ctx := context.NewContext()
+			require.Equal(t, DefaultNumVoices, NumVoices(ctx))
+		}
+	})
+
+	t.Run("valid values", func(t *testing.T) {
+		for _, n := range []int{1, 3, 8} {
+			SetNumVoices(n)
+
+			ctx := context.NewContextWith(context.ContextOptions{
+				SampleRate: 100,
+			})
+			require.Equal(t, n, NumVoices(ctx))
+
+			buffer := NewBuffer(ctx)
+			require.Equal(t, n, buffer.NumVoices())
+			require.Equal(t, 100, buffer.Frames())
+			require.Len(t, buffer.Tones, 100*n)
+			require.Len(t, buffer.Positions, 100*n)
+			require.Len(t, buffer.VoiceGains, n)
+			buffer.Release()
+		}
+	})
+}
+
+// Test_NumVoices_Concurrency tests that it's safe to concurrently get and set the global number of
+// voices in a buffer.
+func Test_NumVoices_Concurrency(t *testing.T) {
+	defer SetNumVoices(DefaultNumVoices)
+
+	var wg sync.WaitGroup
+	for i := range 1_000 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if i%2 == 0 {
+				ctx := context.NewContextWith(context.ContextOptions{
+					SampleRate: 10,
+				})
+				NumVoices(ctx)
+			} else {
+				SetNumVoices(i%16 + 1)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -104,6 +104,35 @@ func NewTestContext() Context {
 	}
 }
 
+// WithTime returns a context that shares everything with the parent context except for its time,
+// which starts at the specified time. Setting the new context's time doesn't change the parent's
+// time, so a part of the audio can move its own time forward without moving anything else's.
+// Values added to the new context with WithValue are only seen by the new context. It returns nil
+// if the parent context is nil.
+func WithTime(ctx Context, time Time) Context {
+	if ctx == nil {
+		return nil
+	}
+
+	return &timeContext{
+		Context: ctx,
+		values:  ctx,
+		time:    time,
+	}
+}
+
+// Advance moves the context's time forward by the number of samples. Contexts without a valid time
+// are left alone.
+func Advance(ctx Context, samples int) {
+	if ctx == nil {
+		return
+	}
+
+	if time := ctx.Time(); !time.Empty() {
+		ctx.SetTime(time.ShiftBy(samples))
+	}
+}
+
 // WithValue sets an arbitrary value in the context under the provided key and returns the context.
 // The key should follow the same general guidelines for the standard library's context.WithValue.
 func (ctx *context) WithValue(key, value any) Context {
@@ -147,3 +176,37 @@ func (ctx *context) SampleRate() int {
 func (ctx *context) NyqistFrequency() float32 {
 	return float32(ctx.SampleRate() / 2)
 }
+
+// timeContext is a context that has its own time and values on top of a parent context.
+type timeContext struct {
+	Context
+
+	// Values of the parent context and any values added to this context
+	values gocontext.Context
+
+	time Time
+}
+
+// WithValue sets an arbitrary value in the context under the provided key and returns the context.
+// The parent context is not changed.
+func (ctx *timeContext) WithValue(key, value any) Context {
+	ctx.values = gocontext.WithValue(ctx.values, key, value)
+
+	return ctx
+}
+
+// Value returns the value in the context under the provided key, or the parent context's value if
+// the key wasn't set in this context.
+func (ctx *timeContext) Value(key any) any {
+	return ctx.values.Value(key)
+}
+
+// Time returns the context's own timestamp.
+func (ctx *timeContext) Time() Time {
+	return ctx.time
+}
+
+// SetTime sets the context's own timestamp.
+func (ctx *timeContext) SetTime(time Time) {
+	ctx.time = time
+}
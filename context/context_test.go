@@ -257,6 +257,80 @@ func Test_NewTestContext(t *testing.T) {
 	})
 }
 
+// Test_WithTime tests that WithTime returns a context with its own time and values on top of its
+// parent context.
+func Test_WithTime(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		require.Nil(t, WithTime(nil, NewTime()))
+	})
+
+	parent := NewContextWith(ContextOptions{
+		SampleRate: 8_000,
+	})
+	parent = parent.WithValue(ctxKey(1), "parent")
+
+	ctx := WithTime(parent, NewTimeAt(2, 3, 8_000))
+	require.Equal(t, NewTimeAt(2, 3, 8_000), ctx.Time())
+	require.Equal(t, 8_000, ctx.SampleRate())
+	require.Equal(t, float32(4_000), ctx.NyqistFrequency())
+	require.Equal(t, "parent", ctx.Value(ctxKey(1)))
+
+	t.Run("time", func(t *testing.T) {
+		ctx := WithTime(parent, parent.Time())
+		ctx.SetTime(NewTimeAt(1, 1, 8_000))
+		require.Equal(t, NewTimeAt(1, 1, 8_000), ctx.Time())
+		require.Equal(t, NewTimeWith(8_000), parent.Time())
+
+		parent.SetTime(NewTimeAt(0, 5, 8_000))
+		defer parent.SetTime(NewTimeWith(8_000))
+		require.Equal(t, NewTimeAt(1, 1, 8_000), ctx.Time())
+	})
+
+	t.Run("values", func(t *testing.T) {
+		ctx := WithTime(parent, parent.Time())
+		require.Same(t, ctx, ctx.WithValue(ctxKey(2), "child"))
+		ctx.WithValue(ctxKey(1), "overwritten")
+		require.Equal(t, "overwritten", ctx.Value(ctxKey(1)))
+		require.Equal(t, "child", ctx.Value(ctxKey(2)))
+
+		// The parent context doesn't see the values, and keeps its time.
+		require.Equal(t, "parent", parent.Value(ctxKey(1)))
+		require.Nil(t, parent.Value(ctxKey(2)))
+		require.Equal(t, NewTimeWith(8_000), parent.Time())
+
+		// Values added to the parent later are seen by the child.
+		parent.WithValue(ctxKey(3), "later")
+		require.Equal(t, "later", ctx.Value(ctxKey(3)))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		ctx := WithTime(parent, NewTimeAt(1, 1, 8_000))
+		nested := WithTime(ctx, ctx.Time())
+		Advance(nested, 10)
+		require.Equal(t, NewTimeAt(1, 11, 8_000), nested.Time())
+		require.Equal(t, NewTimeAt(1, 1, 8_000), ctx.Time())
+		require.Equal(t, "parent", nested.Value(ctxKey(1)))
+	})
+}
+
+// Test_Advance tests that Advance moves a context's time forward.
+func Test_Advance(t *testing.T) {
+	require.NotPanics(t, func() { Advance(nil, 1) })
+
+	ctx := NewContextWith(ContextOptions{
+		SampleRate: 8_000,
+	})
+	Advance(ctx, 8_010)
+	require.Equal(t, NewTimeAt(1, 11, 8_000), ctx.Time())
+	Advance(ctx, -10)
+	require.Equal(t, NewTimeAt(1, 1, 8_000), ctx.Time())
+
+	// Contexts without a valid time are left alone.
+	ctx = NewTestContext()
+	require.NotPanics(t, func() { Advance(ctx, 10) })
+	require.True(t, ctx.Time().Empty())
+}
+
 // Test_Context_WithValue tests that Context's WithValue method sets the correct value in the context.
 func Test_Context_WithValue(t *testing.T) {
 	t.Run("nil context", func(t *testing.T) {
@@ -0,0 +1,289 @@
+package effect
+
+import (
+	"io"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/wav"
+)
+
+const (
+	// DefaultBlockSize is the default size of the partitions that a convolver splits its impulse
+	// response into.
+	DefaultBlockSize = 128
+)
+
+// An ImpulseResponse is a recording of how a space or a device responds to a single click. Playing
+// audio through it with a Convolver makes the audio sound like it was played in that space or
+// through that device.
+type ImpulseResponse struct {
+	// Samples holds the frames of the impulse response. If it has more than one channel, the
+	// samples are interleaved.
+	Samples []float32
+
+	// Channels is the number of channels in each frame.
+	Channels int
+
+	// SampleRate is the number of frames in one second.
+	SampleRate int
+}
+
+// LoadImpulseResponse reads an impulse response from a WAV file and resamples it to the context's
+// sample rate if it was recorded at a different one.
+func LoadImpulseResponse(ctx context.Context, r io.Reader) (ImpulseResponse, error) {
+	format, samples, err := wav.Decode(r)
+	if err != nil {
+		return ImpulseResponse{}, err
+	}
+
+	ir := ImpulseResponse{
+		Samples:    samples,
+		Channels:   format.NumChannels,
+		SampleRate: format.SampleRate,
+	}
+	if ctx != nil {
+		ir = ir.Resample(ctx.SampleRate())
+	}
+
+	return ir, nil
+}
+
+// Frames returns the number of frames in the impulse response.
+func (ir ImpulseResponse) Frames() int {
+	return len(ir.Samples) / numChannels(ir.Channels)
+}
+
+// Resample returns a copy of the impulse response at the sample rate. An impulse response without
+// a sample rate is assumed to already be at the new one, and a sample rate of 0 keeps the impulse
+// response's own.
+func (ir ImpulseResponse) Resample(sampleRate int) ImpulseResponse {
+	if sampleRate <= 0 {
+		sampleRate = ir.SampleRate
+	}
+
+	return ImpulseResponse{
+		Samples:    resample(ir.Samples, ir.Channels, ir.SampleRate, sampleRate),
+		Channels:   numChannels(ir.Channels),
+		SampleRate: sampleRate,
+	}
+}
+
+// A Convolver plays audio through an impulse response, which is how convolution reverbs recreate
+// real spaces and how cabinet simulators recreate speakers.
+//
+// The start of the impulse response is convolved directly, one sample at a time, and the rest of
+// it is split into equal partitions that are convolved in the frequency domain with FFTs once
+// every block of samples. Because the partitions only need audio that has already played, the
+// convolver doesn't add any latency, no matter how long the impulse response is or how the audio is
+// split up into blocks.
+//
+// A Convolver is not safe for concurrent use by multiple goroutines.
+type Convolver struct {
+	// Mix is the balance between the original (dry) audio at 0 and the convolved (wet) audio at 1.
+	Mix float32
+
+	fft       *fft
+	blockSize int
+	channels  int
+	states    []convolverState
+}
+
+// A convolverState holds the impulse response and the history of one channel.
+type convolverState struct {
+	// Start of the impulse response, which is convolved directly
+	head []float32
+
+	// Frequency domain of each partition of the rest of the impulse response
+	partitions [][]complex128
+
+	// Recent input, for the direct convolution
+	history delayLine
+
+	// Input of the previous block and the current block
+	input []float64
+
+	// Frequency domain of the input of the most recent blocks, as a ring buffer
+	spectra  [][]complex128
+	spectrum int
+
+	// Output of the partitions for the current block
+	tail []float32
+
+	// Position in the current block
+	pos int
+
+	// Scratch space for the FFTs
+	scratch []complex128
+}
+
+// NewConvolver creates a convolver for the impulse response and the context's buffer layout. The
+// impulse response is resampled to the context's sample rate if necessary. If it has one channel,
+// every channel is convolved with it; otherwise, each channel is convolved with the matching
+// channel of the impulse response, starting over from its first channel if it has fewer channels.
+// The mix is 1, so only the convolved audio is heard.
+func NewConvolver(ctx context.Context, ir ImpulseResponse) *Convolver {
+	return NewConvolverWith(ctx, ir, DefaultBlockSize)
+}
+
+// NewConvolverWith creates a convolver like NewConvolver does, with the partitions of the impulse
+// response the specified size. Smaller blocks use less time per block but more time overall. The
+// block size is rounded up to a power of 2.
+func NewConvolverWith(ctx context.Context, ir ImpulseResponse, blockSize int) *Convolver {
+	var sampleRate int
+	if ctx != nil {
+		sampleRate = ctx.SampleRate()
+	}
+	ir = ir.Resample(sampleRate)
+
+	size := 1
+	for size < blockSize {
+		size *= 2
+	}
+
+	convolver := &Convolver{
+		Mix:       1,
+		fft:       newFFT(2 * size),
+		blockSize: size,
+		channels:  numChannels(buffer.BufferLayout(ctx).Channels()),
+	}
+
+	convolver.states = make([]convolverState, convolver.channels)
+	for c := range convolver.states {
+		convolver.states[c] = convolver.newState(channel(ir, c%numChannels(ir.Channels)))
+	}
+
+	return convolver
+}
+
+// Channels returns the number of channels in each frame of audio that the convolver processes.
+func (convolver *Convolver) Channels() int {
+	if convolver == nil {
+		return 0
+	}
+
+	return convolver.channels
+}
+
+// Process convolves the samples in place.
+func (convolver *Convolver) Process(ctx context.Context, samples []float32) {
+	if convolver == nil {
+		return
+	}
+
+	channels := convolver.channels
+	for f := 0; f+channels <= len(samples); f += channels {
+		frame := samples[f : f+channels]
+		for c, sample := range frame {
+			wet := convolver.states[c].next(convolver, sample)
+			frame[c] = (1-convolver.Mix)*sample + convolver.Mix*wet
+		}
+	}
+}
+
+// Reset clears the convolver, so that it's silent until it gets more audio.
+func (convolver *Convolver) Reset() {
+	if convolver == nil {
+		return
+	}
+
+	for c := range convolver.states {
+		state := &convolver.states[c]
+		state.history.reset()
+		clear(state.input)
+		for _, spectrum := range state.spectra {
+			clear(spectrum)
+		}
+		state.spectrum = 0
+		clear(state.tail)
+		state.pos = 0
+	}
+}
+
+// newState splits one channel of an impulse response into its head and partitions.
+func (convolver *Convolver) newState(ir []float32) convolverState {
+	size := convolver.blockSize
+	state := convolverState{
+		head:    ir[:min(size, len(ir))],
+		input:   make([]float64, 2*size),
+		tail:    make([]float32, size),
+		scratch: make([]complex128, 2*size),
+	}
+	state.history.grow(float64(size))
+
+	for start := size; start < len(ir); start += size {
+		partition := make([]complex128, 2*size)
+		for i, sample := range ir[start:min(start+size, len(ir))] {
+			partition[i] = complex(float64(sample), 0)
+		}
+		convolver.fft.transform(partition)
+
+		state.partitions = append(state.partitions, partition)
+		state.spectra = append(state.spectra, make([]complex128, 2*size))
+	}
+
+	return state
+}
+
+// next convolves one sample.
+func (state *convolverState) next(convolver *Convolver, sample float32) float32 {
+	state.history.write(sample)
+
+	out := state.tail[state.pos]
+	for k, h := range state.head {
+		out += h * state.history.at(k+1)
+	}
+
+	state.input[convolver.blockSize+state.pos] = float64(sample)
+	state.pos++
+	if state.pos == convolver.blockSize {
+		state.pos = 0
+		state.convolveTail(convolver)
+	}
+
+	return out
+}
+
+// convolveTail convolves the partitions with the last block of input, which gives the output of
+// the partitions for the next block.
+func (state *convolverState) convolveTail(convolver *Convolver) {
+	size := convolver.blockSize
+	if len(state.partitions) > 0 {
+		// The newest spectrum replaces the oldest one.
+		state.spectrum = (state.spectrum + len(state.spectra) - 1) % len(state.spectra)
+		spectrum := state.spectra[state.spectrum]
+		for i, sample := range state.input {
+			spectrum[i] = complex(sample, 0)
+		}
+		convolver.fft.transform(spectrum)
+
+		// Each partition is convolved with the input from as many blocks ago as it is from the
+		// start of the tail.
+		clear(state.scratch)
+		for p, partition := range state.partitions {
+			spectrum := state.spectra[(state.spectrum+p)%len(state.spectra)]
+			for i := range state.scratch {
+				state.scratch[i] += spectrum[i] * partition[i]
+			}
+		}
+		convolver.fft.inverse(state.scratch)
+
+		// The second half of the block holds the output that didn't wrap around.
+		for i := range state.tail {
+			state.tail[i] = float32(real(state.scratch[size+i]))
+		}
+	}
+
+	copy(state.input, state.input[size:])
+}
+
+// channel returns one channel of the impulse response.
+func channel(ir ImpulseResponse, c int) []float32 {
+	channels := numChannels(ir.Channels)
+	samples := make([]float32, ir.Frames())
+	for i := range samples {
+		samples[i] = ir.Samples[i*channels+c]
+	}
+
+	return samples
+}
@@ -0,0 +1,243 @@
+package effect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/wav"
+	"github.com/stretchr/testify/require"
+)
+
+// convolve returns the first len(samples) samples of the convolution of the samples with the
+// impulse response, calculated directly.
+func convolve(samples []float32, ir []float32) []float32 {
+	out := make([]float32, len(samples))
+	for n := range out {
+		var sum float64
+		for k := 0; k < len(ir) && k <= n; k++ {
+			sum += float64(ir[k]) * float64(samples[n-k])
+		}
+		out[n] = float32(sum)
+	}
+
+	return out
+}
+
+// decay returns an impulse response of n samples that decays and alternates in sign.
+func decay(n int) []float32 {
+	ir := make([]float32, n)
+	gain := float32(1)
+	for i := range ir {
+		ir[i] = gain
+		gain *= -0.99
+	}
+
+	return ir
+}
+
+// Test_LoadImpulseResponse tests that LoadImpulseResponse decodes an impulse response and
+// resamples it to the context's sample rate.
+func Test_LoadImpulseResponse(t *testing.T) {
+	ir := sine(800, 100, 8_000)
+	format := wav.Format{SampleRate: 8_000, NumChannels: 2, Encoding: wav.Float32}
+	samples := make([]float32, 0, 2*len(ir))
+	for _, sample := range ir {
+		samples = append(samples, sample, -sample)
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, wav.Encode(&b, format, samples))
+	data := b.Bytes()
+
+	t.Run("same rate", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{SampleRate: 8_000})
+		loaded, err := LoadImpulseResponse(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, ImpulseResponse{Samples: samples, Channels: 2, SampleRate: 8_000}, loaded)
+		require.Equal(t, 800, loaded.Frames())
+	})
+
+	t.Run("resampled", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{SampleRate: 16_000})
+		loaded, err := LoadImpulseResponse(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, 2, loaded.Channels)
+		require.Equal(t, 16_000, loaded.SampleRate)
+		require.Equal(t, 1_600, loaded.Frames())
+		require.Equal(t, resample(samples, 2, 8_000, 16_000), loaded.Samples)
+	})
+
+	t.Run("no context", func(t *testing.T) {
+		loaded, err := LoadImpulseResponse(nil, bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, 8_000, loaded.SampleRate)
+		require.Equal(t, samples, loaded.Samples)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := LoadImpulseResponse(context.NewContext(), bytes.NewReader([]byte("not a wav file")))
+		require.ErrorIs(t, err, wav.ErrInvalidFile)
+	})
+}
+
+// Test_ImpulseResponse_Resample tests that ImpulseResponse.Resample changes the sample rate of an
+// impulse response.
+func Test_ImpulseResponse_Resample(t *testing.T) {
+	ir := ImpulseResponse{Samples: sine(100, 100, 8_000), Channels: 1, SampleRate: 8_000}
+
+	resampled := ir.Resample(4_000)
+	require.Equal(t, ImpulseResponse{Samples: resample(ir.Samples, 1, 8_000, 4_000), Channels: 1, SampleRate: 4_000}, resampled)
+
+	require.Equal(t, ir, ir.Resample(8_000))
+	require.Equal(t, ir, ir.Resample(0))
+
+	// Impulse responses without a sample rate or channels are taken as they are.
+	require.Equal(t, ImpulseResponse{Samples: []float32{1, 2}, Channels: 1, SampleRate: 4_000},
+		ImpulseResponse{Samples: []float32{1, 2}}.Resample(4_000))
+}
+
+// Test_NewConvolver tests that NewConvolver creates a convolver for a context and impulse response.
+func Test_NewConvolver(t *testing.T) {
+	defer buffer.SetBufferLayout(buffer.DefaultLayout)
+	buffer.SetBufferLayout(buffer.LayoutStereo)
+
+	ctx := context.NewContextWith(context.ContextOptions{SampleRate: 8_000})
+	ir := ImpulseResponse{Samples: decay(300), Channels: 1, SampleRate: 8_000}
+
+	convolver := NewConvolver(ctx, ir)
+	require.Equal(t, float32(1), convolver.Mix)
+	require.Equal(t, 2, convolver.Channels())
+	require.Equal(t, DefaultBlockSize, convolver.blockSize)
+	require.Len(t, convolver.states, 2)
+	require.Len(t, convolver.states[0].head, DefaultBlockSize)
+	require.Len(t, convolver.states[0].partitions, 2)
+
+	// Block sizes are rounded up to a power of 2.
+	convolver = NewConvolverWith(ctx, ir, 100)
+	require.Equal(t, 128, convolver.blockSize)
+	convolver = NewConvolverWith(ctx, ir, 0)
+	require.Equal(t, 1, convolver.blockSize)
+
+	var nilConvolver *Convolver
+	require.Zero(t, nilConvolver.Channels())
+}
+
+// Test_Convolver_Process tests that Convolver convolves audio with its impulse response.
+func Test_Convolver_Process(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{SampleRate: 8_000})
+
+	t.Run("nil", func(t *testing.T) {
+		var convolver *Convolver
+		convolver.Process(ctx, []float32{1})
+		convolver.Reset()
+	})
+
+	t.Run("impulse", func(t *testing.T) {
+		ir := decay(1_000)
+		convolver := NewConvolverWith(ctx, ImpulseResponse{Samples: ir, Channels: 1, SampleRate: 8_000}, 64)
+
+		// There's no latency, so an impulse plays back the impulse response exactly where it is.
+		samples := impulse(1_200)
+		convolver.Process(ctx, samples)
+		require.InDeltaSlice(t, ir, samples[:1_000], 1e-5)
+		require.InDeltaSlice(t, make([]float32, 200), samples[1_000:], 1e-5)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		ir := decay(700)
+		input := sawtooth(3_000)
+		want := convolve(input, ir)
+
+		for _, blockSize := range []int{1, 16, 128, 1_024} {
+			for _, split := range []int{1, 7, 64, 500, 3_000} {
+				convolver := NewConvolverWith(ctx, ImpulseResponse{Samples: ir, Channels: 1, SampleRate: 8_000}, blockSize)
+
+				samples := append([]float32(nil), input...)
+				for start := 0; start < len(samples); start += split {
+					convolver.Process(ctx, samples[start:min(start+split, len(samples))])
+				}
+				require.InDeltaSlice(t, want, samples, 1e-4, "block size %d, split %d", blockSize, split)
+			}
+		}
+	})
+
+	t.Run("short impulse response", func(t *testing.T) {
+		convolver := NewConvolver(ctx, ImpulseResponse{Samples: []float32{0.5, 0.25}, Channels: 1, SampleRate: 8_000})
+		samples := []float32{1, 0, 0, 2, 0}
+		convolver.Process(ctx, samples)
+		require.Equal(t, []float32{0.5, 0.25, 0, 1, 0.5}, samples)
+
+		convolver = NewConvolver(ctx, ImpulseResponse{})
+		samples = []float32{1, 2}
+		convolver.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0}, samples)
+	})
+
+	t.Run("mix", func(t *testing.T) {
+		convolver := NewConvolver(ctx, ImpulseResponse{Samples: []float32{0, 1}, Channels: 1, SampleRate: 8_000})
+		convolver.Mix = 0.25
+		samples := impulse(3)
+		convolver.Process(ctx, samples)
+		require.Equal(t, []float32{0.75, 0.25, 0}, samples)
+	})
+
+	t.Run("channels", func(t *testing.T) {
+		defer buffer.SetBufferLayout(buffer.DefaultLayout)
+		buffer.SetBufferLayout(buffer.LayoutStereo)
+		ctx := context.NewContextWith(context.ContextOptions{SampleRate: 8_000})
+
+		left, right := decay(300), sine(300, 100, 8_000)
+		input := sawtooth(1_000)
+		stereo := make([]float32, 0, 2*len(input))
+		for _, sample := range input {
+			stereo = append(stereo, sample, -sample)
+		}
+
+		// A mono impulse response is used for every channel.
+		convolver := NewConvolverWith(ctx, ImpulseResponse{Samples: left, Channels: 1, SampleRate: 8_000}, 32)
+		samples := append([]float32(nil), stereo...)
+		convolver.Process(ctx, samples)
+		want := convolve(input, left)
+		for i := range want {
+			require.InDelta(t, want[i], samples[2*i], 1e-4)
+			require.InDelta(t, -want[i], samples[2*i+1], 1e-4)
+		}
+
+		// A stereo impulse response has one channel for each channel of audio.
+		ir := make([]float32, 0, 2*len(left))
+		for i := range left {
+			ir = append(ir, left[i], right[i])
+		}
+		convolver = NewConvolverWith(ctx, ImpulseResponse{Samples: ir, Channels: 2, SampleRate: 8_000}, 32)
+		samples = append([]float32(nil), stereo...)
+		convolver.Process(ctx, samples)
+		wantLeft, wantRight := convolve(input, left), convolve(input, right)
+		for i := range wantLeft {
+			require.InDelta(t, wantLeft[i], samples[2*i], 1e-4)
+			require.InDelta(t, -wantRight[i], samples[2*i+1], 1e-4)
+		}
+	})
+
+	t.Run("resampled", func(t *testing.T) {
+		ir := ImpulseResponse{Samples: sine(400, 200, 16_000), Channels: 1, SampleRate: 16_000}
+		convolver := NewConvolver(ctx, ir)
+		samples := impulse(300)
+		convolver.Process(ctx, samples)
+		require.InDeltaSlice(t, ir.Resample(8_000).Samples, samples[:200], 1e-5)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		ir := decay(500)
+		convolver := NewConvolverWith(ctx, ImpulseResponse{Samples: ir, Channels: 1, SampleRate: 8_000}, 64)
+
+		samples := sawtooth(300)
+		convolver.Process(ctx, samples)
+		convolver.Reset()
+
+		samples = impulse(600)
+		convolver.Process(ctx, samples)
+		require.InDeltaSlice(t, ir, samples[:500], 1e-5)
+	})
+}
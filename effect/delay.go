@@ -0,0 +1,189 @@
+package effect
+
+import (
+	"time"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Delay plays audio back again after a delay. With feedback, the delayed audio is fed back into
+// the delay so that it repeats as a series of echoes that fade away.
+//
+// A Delay is not safe for concurrent use by multiple goroutines.
+type Delay struct {
+	// Length is the delay time in samples. It can be fractional, in which case the delayed audio is
+	// interpolated between samples. It's at least 1 sample. Samples and TimeSamples convert real
+	// time and context time into samples, and NoteValue syncs the length to a tempo.
+	Length float64
+
+	// Feedback is how much of the delayed audio is fed back into the delay. Values between 0 and 1
+	// make echoes that fade away, 0 makes a single echo, and values of 1 or more never fade away.
+	Feedback float32
+
+	// Mix is the balance between the original (dry) audio at 0 and the delayed (wet) audio at 1.
+	Mix float32
+
+	// PingPong makes the echoes bounce between the channels. The input is mixed down to mono and
+	// delayed into the first channel, and each channel's echo is delayed into the next channel.
+	PingPong bool
+
+	// Channels is the number of channels in each frame of audio. It must not change once the delay
+	// has been used.
+	Channels int
+
+	lines   []delayLine
+	delayed []float32
+}
+
+// NewDelay creates a delay with the duration and feedback for the context's buffer layout. The mix
+// is 0.5.
+func NewDelay(ctx context.Context, d time.Duration, feedback float32) *Delay {
+	return &Delay{
+		Length:   Samples(ctx, d),
+		Feedback: feedback,
+		Mix:      0.5,
+		Channels: buffer.BufferLayout(ctx).Channels(),
+	}
+}
+
+// Process delays the samples in place.
+func (delay *Delay) Process(ctx context.Context, samples []float32) {
+	if delay == nil {
+		return
+	}
+
+	channels := numChannels(delay.Channels)
+	length := max(delay.Length, 1)
+	if len(delay.lines) != channels {
+		delay.lines = make([]delayLine, channels)
+		delay.delayed = make([]float32, channels)
+	}
+	for c := range delay.lines {
+		delay.lines[c].grow(length)
+	}
+
+	for f := 0; f+channels <= len(samples); f += channels {
+		frame := samples[f : f+channels]
+		for c := range delay.lines {
+			delay.delayed[c] = delay.lines[c].read(length)
+		}
+
+		if delay.PingPong {
+			var in float32
+			for _, sample := range frame {
+				in += sample
+			}
+			in /= float32(channels)
+
+			for c := range delay.lines {
+				prev := (c + channels - 1) % channels
+				sample := delay.Feedback * delay.delayed[prev]
+				if c == 0 {
+					sample += in
+				}
+				delay.lines[c].write(sample)
+			}
+		} else {
+			for c := range delay.lines {
+				delay.lines[c].write(frame[c] + delay.Feedback*delay.delayed[c])
+			}
+		}
+
+		for c := range frame {
+			frame[c] = (1-delay.Mix)*frame[c] + delay.Mix*delay.delayed[c]
+		}
+	}
+}
+
+// Reset clears the delay.
+func (delay *Delay) Reset() {
+	if delay == nil {
+		return
+	}
+
+	for c := range delay.lines {
+		delay.lines[c].reset()
+	}
+}
+
+// A Tap is one of the delayed copies of the audio in a multi-tap delay.
+type Tap struct {
+	// Length is the tap's delay time in samples. It can be fractional, and it's at least 1 sample.
+	Length float64
+
+	// Gain is the level of the tap.
+	Gain float32
+}
+
+// A MultiTap delay plays audio back several times after different delays, which makes rhythmic
+// patterns of echoes. The taps don't feed back into the delay.
+//
+// A MultiTap delay is not safe for concurrent use by multiple goroutines.
+type MultiTap struct {
+	// Taps are the delayed copies of the audio.
+	Taps []Tap
+
+	// Mix is the balance between the original (dry) audio at 0 and the delayed (wet) audio at 1.
+	Mix float32
+
+	// Channels is the number of channels in each frame of audio. It must not change once the delay
+	// has been used.
+	Channels int
+
+	lines []delayLine
+}
+
+// NewMultiTap creates a multi-tap delay with the taps for the context's buffer layout. The mix is
+// 0.5.
+func NewMultiTap(ctx context.Context, taps ...Tap) *MultiTap {
+	return &MultiTap{
+		Taps:     taps,
+		Mix:      0.5,
+		Channels: buffer.BufferLayout(ctx).Channels(),
+	}
+}
+
+// Process delays the samples in place.
+func (multiTap *MultiTap) Process(ctx context.Context, samples []float32) {
+	if multiTap == nil {
+		return
+	}
+
+	channels := numChannels(multiTap.Channels)
+	if len(multiTap.lines) != channels {
+		multiTap.lines = make([]delayLine, channels)
+	}
+	for c := range multiTap.lines {
+		multiTap.lines[c].grow(1)
+		for _, tap := range multiTap.Taps {
+			multiTap.lines[c].grow(max(tap.Length, 1))
+		}
+	}
+
+	for f := 0; f+channels <= len(samples); f += channels {
+		frame := samples[f : f+channels]
+		for c, sample := range frame {
+			line := &multiTap.lines[c]
+
+			var wet float32
+			for _, tap := range multiTap.Taps {
+				wet += tap.Gain * line.read(max(tap.Length, 1))
+			}
+			line.write(sample)
+
+			frame[c] = (1-multiTap.Mix)*sample + multiTap.Mix*wet
+		}
+	}
+}
+
+// Reset clears the delay.
+func (multiTap *MultiTap) Reset() {
+	if multiTap == nil {
+		return
+	}
+
+	for c := range multiTap.lines {
+		multiTap.lines[c].reset()
+	}
+}
@@ -0,0 +1,214 @@
+package effect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// impulse returns n samples that are all 0 except for the first one.
+func impulse(n int) []float32 {
+	samples := make([]float32, n)
+	samples[0] = 1
+
+	return samples
+}
+
+// Test_NewDelay tests that NewDelay creates a delay for a context.
+func Test_NewDelay(t *testing.T) {
+	defer buffer.SetBufferLayout(buffer.DefaultLayout)
+	buffer.SetBufferLayout(buffer.LayoutStereo)
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+	require.Equal(t, &Delay{
+		Length:   2_000,
+		Feedback: 0.25,
+		Mix:      0.5,
+		Channels: 2,
+	}, NewDelay(ctx, 250*time.Millisecond, 0.25))
+}
+
+// Test_Delay_Process tests that Delay delays audio and feeds it back into itself.
+func Test_Delay_Process(t *testing.T) {
+	ctx := context.NewContext()
+
+	t.Run("nil", func(t *testing.T) {
+		var delay *Delay
+		delay.Process(ctx, []float32{1})
+		delay.Reset()
+	})
+
+	t.Run("echo", func(t *testing.T) {
+		delay := Delay{Length: 3, Mix: 1}
+		samples := impulse(8)
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0, 0, 1, 0, 0, 0, 0}, samples)
+	})
+
+	t.Run("feedback", func(t *testing.T) {
+		delay := Delay{Length: 3, Feedback: 0.5, Mix: 1}
+		samples := impulse(11)
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0, 0, 1, 0, 0, 0.5, 0, 0, 0.25, 0}, samples)
+	})
+
+	t.Run("mix", func(t *testing.T) {
+		delay := Delay{Length: 2, Mix: 0.25}
+		samples := impulse(4)
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0.75, 0, 0.25, 0}, samples)
+
+		delay = Delay{Length: 2}
+		samples = impulse(4)
+		delay.Process(ctx, samples)
+		require.Equal(t, impulse(4), samples)
+	})
+
+	t.Run("fractional", func(t *testing.T) {
+		delay := Delay{Length: 2.25, Mix: 1}
+		samples := impulse(5)
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0, 0.75, 0.25, 0}, samples)
+	})
+
+	t.Run("minimum length", func(t *testing.T) {
+		delay := Delay{Length: 0.1, Mix: 1}
+		samples := []float32{1, 2, 3}
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 1, 2}, samples)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		want := sawtooth(1_000)
+		delay := Delay{Length: 123.4, Feedback: 0.7, Mix: 0.5}
+		delay.Process(ctx, want)
+
+		// The delay remembers the audio from one block to the next.
+		have := sawtooth(1_000)
+		delay = Delay{Length: 123.4, Feedback: 0.7, Mix: 0.5}
+		for i := 0; i < len(have); i += 50 {
+			delay.Process(ctx, have[i:i+50])
+		}
+		require.Equal(t, want, have)
+
+		delay.Reset()
+		have = sawtooth(1_000)
+		delay.Process(ctx, have)
+		require.Equal(t, want, have)
+	})
+
+	t.Run("changing length", func(t *testing.T) {
+		delay := Delay{Length: 3, Mix: 1}
+		samples := []float32{1, 2, 3, 4}
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0, 0, 1}, samples)
+
+		// Lengthening the delay reaches further back into the audio that has already played.
+		delay.Length = 5
+		samples = []float32{5, 6, 7, 8}
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 1, 2, 3}, samples)
+	})
+
+	t.Run("stereo", func(t *testing.T) {
+		delay := Delay{Length: 2, Feedback: 0.5, Mix: 1, Channels: 2}
+		samples := []float32{1, 0.5, 0, 0, 0, 0, 0, 0, 0, 0}
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0, 0, 0, 1, 0.5, 0, 0, 0.5, 0.25}, samples)
+
+		// Incomplete frames are left alone.
+		samples = []float32{0, 0, 9}
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0, 9}, samples)
+	})
+
+	t.Run("ping-pong", func(t *testing.T) {
+		delay := Delay{Length: 2, Feedback: 0.5, Mix: 1, Channels: 2, PingPong: true}
+		samples := make([]float32, 16)
+		samples[0], samples[1] = 1, 0.5
+		delay.Process(ctx, samples)
+		require.Equal(t, []float32{
+			0, 0,
+			0, 0,
+			0.75, 0,
+			0, 0,
+			0, 0.375,
+			0, 0,
+			0.1875, 0,
+			0, 0,
+		}, samples)
+	})
+}
+
+// Test_NewMultiTap tests that NewMultiTap creates a multi-tap delay for a context.
+func Test_NewMultiTap(t *testing.T) {
+	taps := []Tap{{Length: 10, Gain: 1}, {Length: 20, Gain: 0.5}}
+	require.Equal(t, &MultiTap{
+		Taps:     taps,
+		Mix:      0.5,
+		Channels: 1,
+	}, NewMultiTap(context.NewContext(), taps...))
+}
+
+// Test_MultiTap_Process tests that MultiTap plays audio back after each of its taps.
+func Test_MultiTap_Process(t *testing.T) {
+	ctx := context.NewContext()
+
+	t.Run("nil", func(t *testing.T) {
+		var multiTap *MultiTap
+		multiTap.Process(ctx, []float32{1})
+		multiTap.Reset()
+	})
+
+	t.Run("no taps", func(t *testing.T) {
+		multiTap := MultiTap{Mix: 0.5}
+		samples := []float32{1, 2}
+		multiTap.Process(ctx, samples)
+		require.Equal(t, []float32{0.5, 1}, samples)
+	})
+
+	t.Run("taps", func(t *testing.T) {
+		multiTap := MultiTap{
+			Taps: []Tap{{Length: 2, Gain: 1}, {Length: 3.5, Gain: 0.5}, {Length: 0, Gain: 0.25}},
+			Mix:  1,
+		}
+		samples := impulse(7)
+		multiTap.Process(ctx, samples)
+		require.Equal(t, []float32{0, 0.25, 1, 0.25, 0.25, 0, 0}, samples)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		taps := []Tap{{Length: 100, Gain: 0.5}, {Length: 333.3, Gain: 0.25}}
+
+		want := sawtooth(1_000)
+		multiTap := MultiTap{Taps: taps, Mix: 0.5, Channels: 2}
+		multiTap.Process(ctx, want)
+
+		have := sawtooth(1_000)
+		multiTap = MultiTap{Taps: taps, Mix: 0.5, Channels: 2}
+		for i := 0; i < len(have); i += 40 {
+			multiTap.Process(ctx, have[i:i+40])
+		}
+		require.Equal(t, want, have)
+
+		multiTap.Reset()
+		have = sawtooth(1_000)
+		multiTap.Process(ctx, have)
+		require.Equal(t, want, have)
+	})
+}
+
+// sawtooth returns n samples of a sawtooth wave.
+func sawtooth(n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(i%37)/18 - 1
+	}
+
+	return samples
+}
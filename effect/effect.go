@@ -0,0 +1,120 @@
+package effect
+
+import (
+	"math"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/filter"
+	"github.com/green-aloe/enobox/source"
+)
+
+// An Effect changes audio that has already been rendered, like a delay or a reverb. Effects remember
+// the audio that they've processed, so consecutive blocks of the same stream of audio should be
+// processed by the same effect, in order.
+type Effect interface {
+	// Process processes the samples in place. The samples are frames that hold one sample for
+	// every channel that the effect was created for, interleaved. The first frame is at the
+	// context's current time. This does not advance the context's time.
+	Process(ctx context.Context, samples []float32)
+
+	// Reset clears the effect's memory of the audio that it has processed, as if it had never been
+	// used.
+	Reset()
+}
+
+// Apply returns a source that processes the audio from the source with a mono effect.
+func Apply(src source.Source, e Effect) source.Source {
+	return filter.Apply(src, e)
+}
+
+// Samples returns the number of samples in the duration at the context's sample rate. The number
+// can be fractional. This is useful for setting the length of a delay in real time.
+func Samples(ctx context.Context, d time.Duration) float64 {
+	if ctx == nil {
+		return 0
+	}
+
+	return d.Seconds() * float64(ctx.SampleRate())
+}
+
+// TimeSamples returns the number of samples between the beginning of time and the timestamp. This
+// is useful for setting the length of a delay in context.Time units.
+func TimeSamples(t context.Time) float64 {
+	if t.Empty() {
+		return 0
+	}
+
+	return float64(t.Second()*t.SampleRate() + t.Sample() - 1)
+}
+
+// numChannels returns the number of channels, which is at least 1.
+func numChannels(channels int) int {
+	return max(channels, 1)
+}
+
+// delayLine is a circular buffer that holds the recent history of a single channel of audio.
+type delayLine struct {
+	buf []float32
+
+	// Index that the next sample is written to
+	pos int
+}
+
+// read returns the sample that was written length samples ago, where 1 is the last sample written.
+// Fractional lengths are linearly interpolated between the two closest samples. The line must be
+// able to hold the length.
+func (line *delayLine) read(length float64) float32 {
+	i := int(length)
+	frac := float32(length - float64(i))
+
+	a := line.at(i)
+	if frac == 0 {
+		return a
+	}
+
+	return a + frac*(line.at(i+1)-a)
+}
+
+// at returns the sample that was written n samples ago.
+func (line *delayLine) at(n int) float32 {
+	i := (line.pos - n) % len(line.buf)
+	if i < 0 {
+		i += len(line.buf)
+	}
+
+	return line.buf[i]
+}
+
+// write adds a sample to the line.
+func (line *delayLine) write(sample float32) {
+	line.buf[line.pos] = sample
+	line.pos++
+	if line.pos == len(line.buf) {
+		line.pos = 0
+	}
+}
+
+// grow makes sure that the line can be read at the length, keeping the samples that it has
+// already been written.
+func (line *delayLine) grow(length float64) {
+	n := int(math.Ceil(length)) + 1
+	if n <= len(line.buf) {
+		return
+	}
+
+	// Lay out the existing samples oldest to newest. The new space after them is silence from
+	// before the line started.
+	buf := make([]float32, max(n, 2*len(line.buf)))
+	for k := 1; k <= len(line.buf); k++ {
+		buf[len(line.buf)-k] = line.at(k)
+	}
+	line.pos = len(line.buf)
+	line.buf = buf
+}
+
+// reset clears the line.
+func (line *delayLine) reset() {
+	clear(line.buf)
+	line.pos = 0
+}
@@ -0,0 +1,71 @@
+package effect_test
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/effect"
+)
+
+func ExampleDelay() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	// Echo every dotted eighth note at 120 bpm, fading by half each time.
+	d := effect.EighthNote.Dotted().Duration(120)
+	delay := effect.NewDelay(ctx, d, 0.5)
+	delay.Mix = 1
+
+	samples := make([]float32, 1_200)
+	samples[0] = 1
+	delay.Process(ctx, samples)
+
+	fmt.Println(d)
+	for i, sample := range samples {
+		if sample != 0 {
+			fmt.Println(i, sample)
+		}
+	}
+
+	// Output:
+	// 375ms
+	// 375 1
+	// 750 0.5
+	// 1125 0.25
+}
+
+func ExampleConvolver() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	// A room that echoes twice, at half and quarter volume. Impulse responses are usually loaded
+	// from a WAV file with LoadImpulseResponse.
+	ir := effect.ImpulseResponse{
+		Samples:    make([]float32, 500),
+		Channels:   1,
+		SampleRate: 1_000,
+	}
+	ir.Samples[0] = 1
+	ir.Samples[200] = 0.5
+	ir.Samples[400] = 0.25
+
+	convolver := effect.NewConvolver(ctx, ir)
+
+	samples := make([]float32, 600)
+	samples[0] = 1
+	convolver.Process(ctx, samples)
+
+	for i, sample := range samples {
+		if math.Abs(float64(sample)) > 1e-6 {
+			fmt.Printf("%d %.2f\n", i, sample)
+		}
+	}
+
+	// Output:
+	// 0 1.00
+	// 200 0.50
+	// 400 0.25
+}
@@ -0,0 +1,79 @@
+package effect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Apply tests that Apply processes the audio from a source with an effect.
+func Test_Apply(t *testing.T) {
+	ctx := context.NewContext()
+	delay := &Delay{Length: 2, Mix: 1}
+
+	src := Apply(source.Samples([]float32{1, 2, 3, 4}), delay)
+	samples := make([]float32, 3)
+	require.Equal(t, 3, src.Fill(ctx, samples))
+	require.Equal(t, []float32{0, 0, 1}, samples)
+	require.Equal(t, 1, src.Fill(ctx, samples))
+	require.Equal(t, float32(2), samples[0])
+	require.Equal(t, context.NewTime().ShiftBy(4), ctx.Time())
+}
+
+// Test_Samples tests that Samples converts a duration into a number of samples.
+func Test_Samples(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 48_000,
+	})
+
+	require.Zero(t, Samples(nil, time.Second))
+	require.Zero(t, Samples(ctx, 0))
+	require.Equal(t, 48_000.0, Samples(ctx, time.Second))
+	require.Equal(t, 12_000.0, Samples(ctx, 250*time.Millisecond))
+	require.InDelta(t, 0.5, Samples(ctx, time.Second/96_000), 1e-4)
+}
+
+// Test_TimeSamples tests that TimeSamples converts a timestamp into a number of samples.
+func Test_TimeSamples(t *testing.T) {
+	require.Zero(t, TimeSamples(context.Time{}))
+	require.Zero(t, TimeSamples(context.NewTimeWith(44_100)))
+	require.Equal(t, 10.0, TimeSamples(context.NewTimeWith(44_100).ShiftBy(10)))
+	require.Equal(t, 44_110.0, TimeSamples(context.NewTimeAt(1, 11, 44_100)))
+}
+
+// Test_delayLine tests that delayLine remembers the samples written to it.
+func Test_delayLine(t *testing.T) {
+	var line delayLine
+	line.grow(3)
+	require.Len(t, line.buf, 4)
+
+	for _, sample := range []float32{1, 2, 3, 4, 5} {
+		line.write(sample)
+	}
+	require.Equal(t, float32(5), line.read(1))
+	require.Equal(t, float32(4), line.read(2))
+	require.Equal(t, float32(2), line.read(4))
+	require.Equal(t, float32(4.5), line.read(1.5))
+	require.Equal(t, float32(2.75), line.read(3.25))
+
+	// Growing the line keeps its history, and anything older than that is silence.
+	line.grow(10)
+	require.Len(t, line.buf, 11)
+	for n, want := range []float32{5, 4, 3, 2, 0, 0, 0, 0, 0, 0} {
+		require.Equal(t, want, line.read(float64(n+1)), n+1)
+	}
+	line.write(6)
+	require.Equal(t, float32(6), line.read(1))
+	require.Equal(t, float32(2), line.read(5))
+
+	// Growing by a little at a time doubles the line.
+	line.grow(11)
+	require.Len(t, line.buf, 22)
+
+	line.reset()
+	require.Zero(t, line.read(1))
+	require.Zero(t, line.pos)
+}
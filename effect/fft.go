@@ -0,0 +1,82 @@
+package effect
+
+import (
+	"math"
+	"math/bits"
+)
+
+// An fft calculates the discrete Fourier transform of blocks of a fixed size with the iterative
+// radix-2 Cooley-Tukey algorithm.
+type fft struct {
+	// Size of each block, which is a power of 2
+	n int
+
+	// Twiddle factors e^(-2*pi*i*k/n) for the first half of the block
+	twiddles []complex128
+
+	// Bit-reversed index of every element in the block
+	reversed []int
+}
+
+// newFFT creates an fft for blocks of n elements. n must be a power of 2.
+func newFFT(n int) *fft {
+	f := &fft{
+		n:        n,
+		twiddles: make([]complex128, n/2),
+		reversed: make([]int, n),
+	}
+	for k := range f.twiddles {
+		angle := -2 * math.Pi * float64(k) / float64(n)
+		f.twiddles[k] = complex(math.Cos(angle), math.Sin(angle))
+	}
+
+	shift := bits.UintSize - bits.TrailingZeros(uint(n))
+	for i := range f.reversed {
+		if n > 1 {
+			f.reversed[i] = int(bits.Reverse(uint(i)) >> shift)
+		}
+	}
+
+	return f
+}
+
+// transform replaces x with its discrete Fourier transform.
+func (f *fft) transform(x []complex128) {
+	f.run(x, false)
+}
+
+// inverse replaces x with its inverse discrete Fourier transform.
+func (f *fft) inverse(x []complex128) {
+	f.run(x, true)
+
+	scale := complex(1/float64(f.n), 0)
+	for i := range x {
+		x[i] *= scale
+	}
+}
+
+// run does the butterflies of the transform in place. The inverse transform uses the conjugates of
+// the twiddle factors and isn't scaled.
+func (f *fft) run(x []complex128, inverse bool) {
+	for i, j := range f.reversed {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= f.n; size *= 2 {
+		half := size / 2
+		step := f.n / size
+		for start := 0; start < f.n; start += size {
+			for k := range half {
+				w := f.twiddles[k*step]
+				if inverse {
+					w = complex(real(w), -imag(w))
+				}
+
+				a, b := x[start+k], x[start+k+half]*w
+				x[start+k], x[start+k+half] = a+b, a-b
+			}
+		}
+	}
+}
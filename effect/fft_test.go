@@ -0,0 +1,41 @@
+package effect
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_fft tests that fft matches the discrete Fourier transform and that its inverse undoes it.
+func Test_fft(t *testing.T) {
+	for _, n := range []int{1, 2, 4, 8, 64} {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(math.Sin(float64(i*i)), math.Cos(float64(3*i)))
+		}
+
+		// Discrete Fourier transform, calculated directly from its definition
+		want := make([]complex128, n)
+		for k := range want {
+			for i, v := range x {
+				want[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*i)/float64(n)))
+			}
+		}
+
+		f := newFFT(n)
+		got := append([]complex128(nil), x...)
+		f.transform(got)
+		for k := range want {
+			require.InDelta(t, real(want[k]), real(got[k]), 1e-9, "n=%d k=%d", n, k)
+			require.InDelta(t, imag(want[k]), imag(got[k]), 1e-9, "n=%d k=%d", n, k)
+		}
+
+		f.inverse(got)
+		for i := range x {
+			require.InDelta(t, real(x[i]), real(got[i]), 1e-9, "n=%d i=%d", n, i)
+			require.InDelta(t, imag(x[i]), imag(got[i]), 1e-9, "n=%d i=%d", n, i)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package effect
+
+import (
+	"math"
+)
+
+// Number of zero crossings of the sinc function on each side of a resampled sample
+const resampleZeroCrossings = 16
+
+// resample converts interleaved frames of audio from one sample rate to another with band-limited
+// (windowed sinc) interpolation. When lowering the sample rate, frequencies above the new Nyquist
+// frequency are filtered out first so that they don't alias.
+func resample(samples []float32, channels int, from, to int) []float32 {
+	channels = numChannels(channels)
+	if from <= 0 || to <= 0 || from == to {
+		return append([]float32(nil), samples...)
+	}
+
+	frames := len(samples) / channels
+	ratio := float64(from) / float64(to)
+	out := make([]float32, int(math.Ceil(float64(frames)/ratio))*channels)
+
+	// Lowering the sample rate widens the sinc function so that it also works as a low-pass filter.
+	cutoff := min(1, 1/ratio)
+	width := resampleZeroCrossings / cutoff
+
+	for j := range len(out) / channels {
+		t := float64(j) * ratio
+		first := max(int(math.Ceil(t-width)), 0)
+		last := min(int(math.Floor(t+width)), frames-1)
+
+		for k := first; k <= last; k++ {
+			x := t - float64(k)
+			weight := float32(cutoff * sinc(cutoff*x) * blackman(x/width))
+			if weight == 0 {
+				continue
+			}
+			for c := range channels {
+				out[j*channels+c] += weight * samples[k*channels+c]
+			}
+		}
+	}
+
+	return out
+}
+
+// sinc returns the normalized sinc function sin(pi*x)/(pi*x).
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman returns the Blackman window at x, which goes from 1 at 0 to 0 at -1 and 1.
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
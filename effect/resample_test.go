@@ -0,0 +1,75 @@
+package effect
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sine returns n samples of a sine wave at the frequency and sample rate.
+func sine(n int, frequency float64, sampleRate int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(math.Sin(2 * math.Pi * frequency * float64(i) / float64(sampleRate)))
+	}
+
+	return samples
+}
+
+// Test_resample tests that resample changes the sample rate of audio without changing its pitch.
+func Test_resample(t *testing.T) {
+	t.Run("same rate", func(t *testing.T) {
+		samples := []float32{1, 2, 3}
+		resampled := resample(samples, 1, 8_000, 8_000)
+		require.Equal(t, samples, resampled)
+
+		resampled[0] = 0
+		require.Equal(t, []float32{1, 2, 3}, samples)
+
+		require.Equal(t, samples, resample(samples, 1, 0, 8_000))
+		require.Equal(t, samples, resample(samples, 1, 8_000, 0))
+	})
+
+	t.Run("length", func(t *testing.T) {
+		require.Len(t, resample(make([]float32, 1_000), 1, 8_000, 16_000), 2_000)
+		require.Len(t, resample(make([]float32, 1_000), 1, 16_000, 8_000), 500)
+		require.Len(t, resample(make([]float32, 2_000), 2, 8_000, 12_000), 3_000)
+		require.Empty(t, resample(nil, 1, 8_000, 16_000))
+	})
+
+	t.Run("pitch", func(t *testing.T) {
+		for _, to := range []int{6_000, 11_025, 16_000} {
+			resampled := resample(sine(2_000, 440, 8_000), 1, 8_000, to)
+			want := sine(len(resampled), 440, to)
+
+			// The edges fade in and out because there's no audio past them.
+			margin := 2 * resampleZeroCrossings * to / 6_000
+			require.InDeltaSlice(t, want[margin:len(want)-margin], resampled[margin:len(resampled)-margin], 0.01, "to=%d", to)
+		}
+	})
+
+	t.Run("aliasing", func(t *testing.T) {
+		// 5kHz is above the new Nyquist frequency of 4kHz, so it's filtered out.
+		resampled := resample(sine(4_000, 5_000, 16_000), 1, 16_000, 8_000)
+		margin := 4 * resampleZeroCrossings
+		require.Less(t, energy(resampled[margin:len(resampled)-margin])/float64(len(resampled)-2*margin), 1e-4)
+	})
+
+	t.Run("channels", func(t *testing.T) {
+		left := sine(1_000, 440, 8_000)
+		right := sine(1_000, 220, 8_000)
+		stereo := make([]float32, 0, 2_000)
+		for i := range left {
+			stereo = append(stereo, left[i], -right[i])
+		}
+
+		resampled := resample(stereo, 2, 8_000, 16_000)
+		wantLeft := resample(left, 1, 8_000, 16_000)
+		wantRight := resample(right, 1, 8_000, 16_000)
+		for i := range wantLeft {
+			require.Equal(t, wantLeft[i], resampled[2*i])
+			require.Equal(t, -wantRight[i], resampled[2*i+1])
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package effect
+
+import (
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+)
+
+// Tuning of Jezar's Freeverb, which was designed for a sample rate of 44.1kHz. The delay lengths
+// are scaled to other sample rates.
+const (
+	freeverbSampleRate = 44_100
+	freeverbGain       = 0.015
+	freeverbScaleWet   = 3
+	freeverbScaleDamp  = 0.4
+	freeverbScaleRoom  = 0.28
+	freeverbOffsetRoom = 0.7
+	freeverbAllpass    = 0.5
+	freeverbSpread     = 23
+)
+
+var (
+	// Lengths of the comb filters, in samples at 44.1kHz
+	freeverbCombs = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+
+	// Lengths of the all-pass filters, in samples at 44.1kHz
+	freeverbAllpasses = []int{556, 441, 341, 225}
+)
+
+// A Reverb simulates the sound of a room with Jezar's Freeverb algorithm, a Schroeder reverb with a
+// bank of damped comb filters in parallel followed by all-pass filters in series. Each channel has
+// its own bank with slightly different delay lengths, which makes the reverb sound wide.
+//
+// The reverb only uses basic arithmetic on its own state, so the same input always produces exactly
+// the same output. A Reverb is not safe for concurrent use by multiple goroutines.
+type Reverb struct {
+	// RoomSize is the size of the room, from 0 to 1. Bigger rooms take longer to decay.
+	RoomSize float32
+
+	// Damping is how much the room absorbs high frequencies, from 0 to 1. More damping makes the
+	// reverb sound darker.
+	Damping float32
+
+	// Mix is the balance between the original (dry) audio at 0 and the reverb (wet) at 1.
+	Mix float32
+
+	// Width is how different the channels of the reverb are from each other, from 0 (mono) to 1.
+	Width float32
+
+	// PreDelay is how long the reverb waits before it starts, in samples. It can be fractional.
+	// Samples and TimeSamples convert real time and context time into samples.
+	PreDelay float64
+
+	// Channels is the number of channels in each frame of audio. It must not change once the
+	// reverb has been used.
+	Channels int
+
+	// Sample rate that the filters were created for
+	sampleRate int
+
+	banks    []reverbBank
+	preDelay delayLine
+	wet      []float32
+}
+
+// A reverbBank is the set of filters that make the reverb for one channel.
+type reverbBank struct {
+	combs     []reverbComb
+	allpasses []reverbAllpass
+}
+
+// A reverbComb is a comb filter with a low-pass filter in its feedback loop.
+type reverbComb struct {
+	buf   []float32
+	pos   int
+	store float32
+}
+
+// A reverbAllpass is a Schroeder all-pass filter.
+type reverbAllpass struct {
+	buf []float32
+	pos int
+}
+
+// NewReverb creates a medium-sized reverb for the context's buffer layout.
+func NewReverb(ctx context.Context) *Reverb {
+	return &Reverb{
+		RoomSize: 0.5,
+		Damping:  0.5,
+		Mix:      0.3,
+		Width:    1,
+		Channels: buffer.BufferLayout(ctx).Channels(),
+	}
+}
+
+// Process adds reverb to the samples in place.
+func (reverb *Reverb) Process(ctx context.Context, samples []float32) {
+	if reverb == nil || ctx == nil {
+		return
+	}
+
+	channels := numChannels(reverb.Channels)
+	reverb.setup(ctx.SampleRate(), channels)
+	if reverb.PreDelay > 0 {
+		reverb.preDelay.grow(max(reverb.PreDelay, 1))
+	}
+
+	feedback := reverb.RoomSize*freeverbScaleRoom + freeverbOffsetRoom
+	damp := reverb.Damping * freeverbScaleDamp
+	wet := reverb.Mix * freeverbScaleWet
+	wet1 := wet * (reverb.Width/2 + 0.5)
+	wet2 := wet * (1 - reverb.Width) / 2
+	dry := 1 - reverb.Mix
+
+	for f := 0; f+channels <= len(samples); f += channels {
+		frame := samples[f : f+channels]
+
+		// Every channel's reverb is fed with the same mono input.
+		var in float32
+		for _, sample := range frame {
+			in += sample
+		}
+		in *= 2 * freeverbGain / float32(channels)
+
+		if reverb.PreDelay > 0 {
+			delayed := reverb.preDelay.read(max(reverb.PreDelay, 1))
+			reverb.preDelay.write(in)
+			in = delayed
+		}
+
+		for c := range reverb.banks {
+			reverb.wet[c] = reverb.banks[c].next(in, feedback, damp)
+		}
+
+		if channels == 1 {
+			frame[0] = frame[0]*dry + reverb.wet[0]*(wet1+wet2)
+			continue
+		}
+		for c := range frame {
+			frame[c] = frame[c]*dry + reverb.wet[c]*wet1 + reverb.wet[(c+1)%channels]*wet2
+		}
+	}
+}
+
+// Reset clears the reverb, so that it's silent until it gets more audio.
+func (reverb *Reverb) Reset() {
+	if reverb == nil {
+		return
+	}
+
+	for _, bank := range reverb.banks {
+		for i := range bank.combs {
+			clear(bank.combs[i].buf)
+			bank.combs[i].pos = 0
+			bank.combs[i].store = 0
+		}
+		for i := range bank.allpasses {
+			clear(bank.allpasses[i].buf)
+			bank.allpasses[i].pos = 0
+		}
+	}
+	reverb.preDelay.reset()
+}
+
+// setup creates the filters for the sample rate and number of channels, if they haven't been
+// created already.
+func (reverb *Reverb) setup(sampleRate int, channels int) {
+	if reverb.sampleRate == sampleRate && len(reverb.banks) == channels {
+		return
+	}
+
+	scale := func(length int) int {
+		return max(length*sampleRate/freeverbSampleRate, 1)
+	}
+
+	reverb.sampleRate = sampleRate
+	reverb.banks = make([]reverbBank, channels)
+	reverb.wet = make([]float32, channels)
+	for c := range reverb.banks {
+		bank := &reverb.banks[c]
+		bank.combs = make([]reverbComb, len(freeverbCombs))
+		for i, length := range freeverbCombs {
+			bank.combs[i].buf = make([]float32, scale(length+c*freeverbSpread))
+		}
+		bank.allpasses = make([]reverbAllpass, len(freeverbAllpasses))
+		for i, length := range freeverbAllpasses {
+			bank.allpasses[i].buf = make([]float32, scale(length+c*freeverbSpread))
+		}
+	}
+}
+
+// next runs one sample through the bank and returns its output.
+func (bank *reverbBank) next(in float32, feedback float32, damp float32) float32 {
+	var out float32
+	for i := range bank.combs {
+		comb := &bank.combs[i]
+
+		delayed := comb.buf[comb.pos]
+		comb.store = delayed*(1-damp) + comb.store*damp
+		comb.buf[comb.pos] = in + comb.store*feedback
+		if comb.pos++; comb.pos == len(comb.buf) {
+			comb.pos = 0
+		}
+
+		out += delayed
+	}
+
+	for i := range bank.allpasses {
+		allpass := &bank.allpasses[i]
+
+		delayed := allpass.buf[allpass.pos]
+		allpass.buf[allpass.pos] = out + delayed*freeverbAllpass
+		if allpass.pos++; allpass.pos == len(allpass.buf) {
+			allpass.pos = 0
+		}
+
+		out = delayed - out
+	}
+
+	return out
+}
@@ -0,0 +1,216 @@
+package effect
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/wav"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// Test_NewReverb tests that NewReverb creates a reverb for a context.
+func Test_NewReverb(t *testing.T) {
+	defer buffer.SetBufferLayout(buffer.DefaultLayout)
+	buffer.SetBufferLayout(buffer.LayoutStereo)
+
+	require.Equal(t, &Reverb{
+		RoomSize: 0.5,
+		Damping:  0.5,
+		Mix:      0.3,
+		Width:    1,
+		Channels: 2,
+	}, NewReverb(context.NewContext()))
+}
+
+// burst returns n frames of audio that start with a short burst of sound and then go silent.
+func burst(n int, channels int) []float32 {
+	samples := make([]float32, n*channels)
+	copy(samples, sawtooth(200*channels))
+
+	return samples
+}
+
+// energy returns the sum of the squares of the samples.
+func energy(samples []float32) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+
+	return sum
+}
+
+// Test_Reverb_Process tests that Reverb adds a decaying tail to audio.
+func Test_Reverb_Process(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var reverb *Reverb
+		reverb.Process(ctx, []float32{1})
+		reverb.Reset()
+
+		samples := []float32{1}
+		NewReverb(ctx).Process(nil, samples)
+		require.Equal(t, []float32{1}, samples)
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		samples := make([]float32, 1_000)
+		NewReverb(ctx).Process(ctx, samples)
+		require.Equal(t, make([]float32, 1_000), samples)
+	})
+
+	t.Run("dry", func(t *testing.T) {
+		reverb := NewReverb(ctx)
+		reverb.Mix = 0
+
+		samples := burst(1_000, 1)
+		reverb.Process(ctx, samples)
+		require.Equal(t, burst(1_000, 1), samples)
+	})
+
+	t.Run("tail", func(t *testing.T) {
+		tail := func(roomSize float32) float64 {
+			reverb := NewReverb(ctx)
+			reverb.RoomSize = roomSize
+			reverb.Mix = 1
+
+			samples := burst(16_000, 1)
+			reverb.Process(ctx, samples)
+			return energy(samples[8_000:])
+		}
+
+		// The reverb rings on after the input goes silent, and bigger rooms ring for longer.
+		small, large := tail(0.2), tail(0.9)
+		require.Positive(t, small)
+		require.Greater(t, large, 10*small)
+	})
+
+	t.Run("damping", func(t *testing.T) {
+		reverb := NewReverb(ctx)
+		reverb.Mix = 1
+		reverb.Damping = 1
+		damped := burst(8_000, 1)
+		reverb.Process(ctx, damped)
+
+		reverb = NewReverb(ctx)
+		reverb.Mix = 1
+		reverb.Damping = 0
+		bright := burst(8_000, 1)
+		reverb.Process(ctx, bright)
+
+		require.Less(t, energy(damped), energy(bright))
+	})
+
+	t.Run("pre-delay", func(t *testing.T) {
+		reverb := NewReverb(ctx)
+		reverb.Mix = 1
+		want := burst(2_000, 1)
+		reverb.Process(ctx, want)
+
+		reverb = NewReverb(ctx)
+		reverb.Mix = 1
+		reverb.PreDelay = Samples(ctx, 25_000_000)
+		have := burst(2_200, 1)
+		reverb.Process(ctx, have)
+
+		require.Equal(t, make([]float32, 200), have[:200])
+		require.Equal(t, want, have[200:])
+	})
+
+	t.Run("width", func(t *testing.T) {
+		reverb := NewReverb(ctx)
+		reverb.Channels = 2
+		reverb.Mix = 1
+		reverb.Width = 0
+
+		samples := burst(2_000, 2)
+		reverb.Process(ctx, samples)
+		for i := 0; i < len(samples); i += 2 {
+			require.Equal(t, samples[i], samples[i+1])
+		}
+
+		reverb.Width = 1
+		reverb.Reset()
+		samples = burst(2_000, 2)
+		reverb.Process(ctx, samples)
+		require.NotEqual(t, samples[1_000], samples[1_001])
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		for _, channels := range []int{1, 2} {
+			reverb := NewReverb(ctx)
+			reverb.Channels = channels
+			reverb.PreDelay = 10.5
+			want := burst(2_000, channels)
+			reverb.Process(ctx, want)
+
+			// The reverb remembers the audio from one block to the next.
+			reverb = NewReverb(ctx)
+			reverb.Channels = channels
+			reverb.PreDelay = 10.5
+			have := burst(2_000, channels)
+			for i := 0; i < len(have); i += 100 * channels {
+				reverb.Process(ctx, have[i:i+100*channels])
+			}
+			require.Equal(t, want, have)
+
+			reverb.Reset()
+			have = burst(2_000, channels)
+			reverb.Process(ctx, have)
+			require.Equal(t, want, have)
+		}
+	})
+
+	t.Run("sample rate", func(t *testing.T) {
+		reverb := NewReverb(ctx)
+		reverb.Process(ctx, make([]float32, 1))
+		require.Len(t, reverb.banks[0].combs[0].buf, 1116*8_000/44_100)
+
+		reverb.Process(context.NewContextWith(context.ContextOptions{SampleRate: 44_100}), make([]float32, 1))
+		require.Len(t, reverb.banks[0].combs[0].buf, 1116)
+	})
+}
+
+// Test_Reverb_golden tests that Reverb's output doesn't change. Run the tests with -update to
+// regenerate the golden files after an intentional change.
+func Test_Reverb_golden(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	for _, channels := range []int{1, 2} {
+		reverb := NewReverb(ctx)
+		reverb.Channels = channels
+		reverb.RoomSize = 0.8
+		reverb.PreDelay = Samples(ctx, 10_000_000)
+
+		samples := burst(4_000, channels)
+		reverb.Process(ctx, samples)
+
+		path := filepath.Join("testdata", []string{"", "reverb_mono.wav", "reverb_stereo.wav"}[channels])
+		if *update {
+			f, err := os.Create(path)
+			require.NoError(t, err)
+			require.NoError(t, wav.Encode(f, wav.NewFormat(ctx, channels, wav.Float32), samples))
+			require.NoError(t, f.Close())
+		}
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		format, golden, err := wav.Decode(f)
+		require.NoError(t, err)
+		require.Equal(t, wav.NewFormat(ctx, channels, wav.Float32), format)
+		require.InDeltaSlice(t, golden, samples, 1e-6)
+	}
+}
@@ -0,0 +1,40 @@
+package effect
+
+import (
+	"time"
+)
+
+// A NoteValue is the length of a note relative to a whole note. Note values make it possible to
+// sync the length of a delay to a tempo.
+type NoteValue float64
+
+const (
+	WholeNote        NoteValue = 1
+	HalfNote         NoteValue = 1.0 / 2
+	QuarterNote      NoteValue = 1.0 / 4
+	EighthNote       NoteValue = 1.0 / 8
+	SixteenthNote    NoteValue = 1.0 / 16
+	ThirtySecondNote NoteValue = 1.0 / 32
+)
+
+// Dotted returns the note value lengthened by half, as if it had a dot after it.
+func (value NoteValue) Dotted() NoteValue {
+	return value * 3 / 2
+}
+
+// Triplet returns the note value shortened so that three of them fit in the time of two.
+func (value NoteValue) Triplet() NoteValue {
+	return value * 2 / 3
+}
+
+// Duration returns how long the note lasts at the tempo, in quarter notes (beats) per minute. This
+// returns 0 if the tempo isn't positive.
+func (value NoteValue) Duration(bpm float64) time.Duration {
+	if bpm <= 0 {
+		return 0
+	}
+
+	beats := float64(value) / float64(QuarterNote)
+
+	return time.Duration(beats * 60 / bpm * float64(time.Second))
+}
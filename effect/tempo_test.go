@@ -0,0 +1,38 @@
+package effect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NoteValue_Duration tests that NoteValue's Duration method returns the length of a note at
+// a tempo.
+func Test_NoteValue_Duration(t *testing.T) {
+	type subtest struct {
+		value NoteValue
+		bpm   float64
+		want  time.Duration
+	}
+
+	subtests := []subtest{
+		{QuarterNote, 120, 500 * time.Millisecond},
+		{QuarterNote, 60, time.Second},
+		{WholeNote, 120, 2 * time.Second},
+		{HalfNote, 120, time.Second},
+		{EighthNote, 120, 250 * time.Millisecond},
+		{SixteenthNote, 120, 125 * time.Millisecond},
+		{ThirtySecondNote, 120, 62_500 * time.Microsecond},
+		{EighthNote.Dotted(), 120, 375 * time.Millisecond},
+		{QuarterNote.Triplet(), 90, 444_444_444 * time.Nanosecond},
+		{QuarterNote, 0, 0},
+		{QuarterNote, -120, 0},
+	}
+
+	for _, subtest := range subtests {
+		require.InDelta(t, subtest.want, subtest.value.Duration(subtest.bpm), 1, "%v at %v bpm", subtest.value, subtest.bpm)
+	}
+
+	require.Equal(t, QuarterNote, EighthNote.Dotted().Triplet()*2)
+}
@@ -0,0 +1,167 @@
+package envelope
+
+import (
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+)
+
+// An Envelope describes how a level changes over the life of a note. When the envelope is
+// triggered, it starts at its start level and moves through each of its segments in order. It then
+// holds the level of the final segment until it is released, at which point it moves through its
+// release segments, starting from whatever level it had reached.
+//
+// An envelope is evaluated against a context's time, so the same envelope produces the same levels
+// no matter how the audio is split up into blocks.
+type Envelope struct {
+	// Start is the level of the envelope before and at the moment it is triggered.
+	Start float32
+
+	// Segments are the stages that the envelope moves through after it is triggered.
+	Segments []Segment
+
+	// ReleaseSegments are the stages that the envelope moves through after it is released.
+	ReleaseSegments []Segment
+
+	triggered bool
+	released  bool
+	onTime    context.Time
+	offTime   context.Time
+	offLevel  float32
+}
+
+// NewADSR creates an envelope with attack, decay, sustain, and release stages. The envelope rises
+// linearly from 0 to 1 over the attack time, falls exponentially to the sustain level over the
+// decay time, holds that level until it is released, and then falls exponentially to 0 over the
+// release time.
+func NewADSR(attack, decay time.Duration, sustain float32, release time.Duration) *Envelope {
+	return NewAHDSR(attack, 0, decay, sustain, release)
+}
+
+// NewAHDSR creates an envelope with attack, hold, decay, sustain, and release stages. It is the
+// same as an ADSR envelope, except that it holds its peak level for the hold time before it starts
+// to decay.
+func NewAHDSR(attack, hold, decay time.Duration, sustain float32, release time.Duration) *Envelope {
+	segments := []Segment{{Duration: attack, Level: 1, Shape: Linear}}
+	if hold > 0 {
+		segments = append(segments, Segment{Duration: hold, Level: 1, Shape: Linear})
+	}
+	segments = append(segments, Segment{Duration: decay, Level: sustain, Shape: Exponential})
+
+	return &Envelope{
+		Segments:        segments,
+		ReleaseSegments: []Segment{{Duration: release, Level: 0, Shape: Exponential}},
+	}
+}
+
+// NewBreakpoints creates an envelope that starts at the specified level and then moves through each
+// of the segments in order. The envelope does not have any release stages.
+func NewBreakpoints(start float32, segments ...Segment) *Envelope {
+	return &Envelope{
+		Start:    start,
+		Segments: segments,
+	}
+}
+
+// Trigger starts the envelope at the context's current time. Triggering an envelope again restarts
+// it from the beginning.
+func (env *Envelope) Trigger(ctx context.Context) {
+	if env == nil || ctx == nil {
+		return
+	}
+
+	env.triggered = true
+	env.released = false
+	env.onTime = ctx.Time()
+}
+
+// Release releases the envelope at the context's current time. The release stages start from the
+// level that the envelope is at at that time. Releasing an envelope that has not been triggered or
+// has already been released has no effect.
+func (env *Envelope) Release(ctx context.Context) {
+	if env == nil || ctx == nil || !env.triggered || env.released {
+		return
+	}
+
+	env.offLevel = env.Value(ctx)
+	env.offTime = ctx.Time()
+	env.released = true
+}
+
+// Value returns the level of the envelope at the context's current time.
+func (env *Envelope) Value(ctx context.Context) float32 {
+	if env == nil || ctx == nil {
+		return 0
+	}
+
+	return env.valueAt(ctx.Time())
+}
+
+// Levels fills levels with the level of the envelope at each sample, starting at the context's
+// current time. This does not advance the context's time.
+func (env *Envelope) Levels(ctx context.Context, levels []float32) {
+	if env == nil || ctx == nil {
+		return
+	}
+
+	now := ctx.Time()
+	for i := range levels {
+		levels[i] = env.valueAt(now)
+		now = now.Increment()
+	}
+}
+
+// Done reports if the envelope has been released and has finished all of its release stages by the
+// context's current time.
+func (env *Envelope) Done(ctx context.Context) bool {
+	if env == nil || ctx == nil || !env.released {
+		return false
+	}
+
+	now := ctx.Time()
+	if now.Before(env.offTime) {
+		return false
+	}
+
+	_, done := levelAt(env.offLevel, env.ReleaseSegments, now.Duration(env.offTime))
+
+	return done
+}
+
+// ApplyGain sets the tone's gain to the specified gain scaled by the envelope's level at the
+// context's current time. A nil envelope leaves the tone unchanged.
+func (env *Envelope) ApplyGain(ctx context.Context, t *tone.Tone, gain float32) {
+	if env == nil || t == nil {
+		return
+	}
+
+	t.Gain = gain * env.Value(ctx)
+}
+
+// ApplyHarmonicGain sets the gain of one of the tone's harmonics to the specified gain scaled by the
+// envelope's level at the context's current time. The index is the index of the harmonic in the
+// tone's harmonic gains. Invalid indexes are ignored, and a nil envelope leaves the tone unchanged.
+func (env *Envelope) ApplyHarmonicGain(ctx context.Context, t *tone.Tone, index int, gain float32) {
+	if env == nil || t == nil || index < 0 || index >= len(t.HarmonicGains) {
+		return
+	}
+
+	t.HarmonicGains[index] = gain * env.Value(ctx)
+}
+
+// valueAt returns the level of the envelope at the specified time.
+func (env *Envelope) valueAt(now context.Time) float32 {
+	if !env.triggered || now.Before(env.onTime) {
+		return env.Start
+	}
+
+	if env.released && !now.Before(env.offTime) {
+		level, _ := levelAt(env.offLevel, env.ReleaseSegments, now.Duration(env.offTime))
+		return level
+	}
+
+	level, _ := levelAt(env.Start, env.Segments, now.Duration(env.onTime))
+
+	return level
+}
@@ -0,0 +1,83 @@
+package envelope_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/envelope"
+	"github.com/green-aloe/enobox/tone"
+)
+
+func ExampleNewADSR() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	env := envelope.NewADSR(100*time.Millisecond, 100*time.Millisecond, 0.5, 100*time.Millisecond)
+	env.Trigger(ctx)
+
+	for range 4 {
+		fmt.Printf("%.2f\n", env.Value(ctx))
+		ctx.SetTime(ctx.Time().ShiftBy(50))
+	}
+
+	env.Release(ctx)
+	for range 3 {
+		fmt.Printf("%.2f\n", env.Value(ctx))
+		ctx.SetTime(ctx.Time().ShiftBy(50))
+	}
+
+	// Output:
+	// 0.00
+	// 0.50
+	// 1.00
+	// 0.71
+	// 0.50
+	// 0.01
+	// 0.00
+}
+
+func ExampleEnvelope_ApplyGain() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	env := envelope.NewADSR(10*time.Millisecond, 0, 1, 0)
+	env.Trigger(ctx)
+
+	tone := tone.NewSquareTone(ctx, 440)
+	for range 3 {
+		env.ApplyGain(ctx, &tone, 0.8)
+		fmt.Printf("%.2f\n", tone.Gain)
+		ctx.SetTime(ctx.Time().ShiftBy(5))
+	}
+
+	// Output:
+	// 0.00
+	// 0.40
+	// 0.80
+}
+
+func ExampleNewStaggeredAttack() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	// Every harmonic takes another 50ms to reach its full gain, so the tone brightens as it starts.
+	spectral := envelope.NewStaggeredAttack(ctx, nil, 100*time.Millisecond, 0.5, 0)
+	spectral.Trigger(ctx)
+
+	tone := tone.NewToneAt(ctx, 220)
+	for range 4 {
+		spectral.Apply(ctx, &tone)
+		fmt.Printf("%.2f\n", tone.HarmonicGains[:3])
+		ctx.SetTime(ctx.Time().ShiftBy(50))
+	}
+
+	// Output:
+	// [0.00 0.00 0.00]
+	// [0.50 0.33 0.25]
+	// [1.00 0.67 0.50]
+	// [1.00 1.00 0.75]
+}
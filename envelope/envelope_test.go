@@ -0,0 +1,234 @@
+package envelope
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewADSR tests that NewADSR builds an envelope with the correct stages.
+func Test_NewADSR(t *testing.T) {
+	env := NewADSR(10*time.Millisecond, 20*time.Millisecond, 0.6, 30*time.Millisecond)
+	require.NotNil(t, env)
+	require.Zero(t, env.Start)
+	require.Equal(t, []Segment{
+		{Duration: 10 * time.Millisecond, Level: 1, Shape: Linear},
+		{Duration: 20 * time.Millisecond, Level: 0.6, Shape: Exponential},
+	}, env.Segments)
+	require.Equal(t, []Segment{
+		{Duration: 30 * time.Millisecond, Level: 0, Shape: Exponential},
+	}, env.ReleaseSegments)
+}
+
+// Test_NewAHDSR tests that NewAHDSR builds an envelope with the correct stages.
+func Test_NewAHDSR(t *testing.T) {
+	env := NewAHDSR(10*time.Millisecond, 5*time.Millisecond, 20*time.Millisecond, 0.6, 30*time.Millisecond)
+	require.NotNil(t, env)
+	require.Zero(t, env.Start)
+	require.Equal(t, []Segment{
+		{Duration: 10 * time.Millisecond, Level: 1, Shape: Linear},
+		{Duration: 5 * time.Millisecond, Level: 1, Shape: Linear},
+		{Duration: 20 * time.Millisecond, Level: 0.6, Shape: Exponential},
+	}, env.Segments)
+	require.Equal(t, []Segment{
+		{Duration: 30 * time.Millisecond, Level: 0, Shape: Exponential},
+	}, env.ReleaseSegments)
+}
+
+// Test_NewBreakpoints tests that NewBreakpoints builds an envelope from arbitrary segments.
+func Test_NewBreakpoints(t *testing.T) {
+	segments := []Segment{
+		{Duration: time.Second, Level: 0.5, Shape: Curved, Curvature: -2},
+		{Duration: time.Second, Level: 0.2, Shape: Exponential},
+	}
+
+	env := NewBreakpoints(0.1, segments...)
+	require.NotNil(t, env)
+	require.Equal(t, float32(0.1), env.Start)
+	require.Equal(t, segments, env.Segments)
+	require.Nil(t, env.ReleaseSegments)
+}
+
+// Test_Envelope tests that an envelope moves through its stages as the context's time advances.
+func Test_Envelope(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var env *Envelope
+		ctx := context.NewContext()
+		require.NotPanics(t, func() { env.Trigger(ctx) })
+		require.NotPanics(t, func() { env.Release(ctx) })
+		require.NotPanics(t, func() { env.Levels(ctx, make([]float32, 10)) })
+		require.Zero(t, env.Value(ctx))
+		require.False(t, env.Done(ctx))
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		env := NewADSR(time.Second, time.Second, 0.5, time.Second)
+		require.NotPanics(t, func() { env.Trigger(nil) })
+		require.NotPanics(t, func() { env.Release(nil) })
+		require.Zero(t, env.Value(nil))
+		require.False(t, env.Done(nil))
+	})
+
+	// Use a sample rate of 1kHz so that every sample is one millisecond.
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+	at := func(ms int) context.Context {
+		ctx.SetTime(context.NewTimeWith(1_000).ShiftBy(ms))
+		return ctx
+	}
+
+	t.Run("adsr", func(t *testing.T) {
+		env := NewADSR(100*time.Millisecond, 100*time.Millisecond, 0.25, 200*time.Millisecond)
+
+		// Before the envelope is triggered, it stays at its start level.
+		require.Zero(t, env.Value(at(0)))
+		require.Zero(t, env.Value(at(500)))
+		env.Release(at(500))
+		require.Zero(t, env.Value(at(600)))
+		require.False(t, env.Done(at(600)))
+
+		env.Trigger(at(1_000))
+		require.Zero(t, env.Value(at(999)))
+		require.Zero(t, env.Value(at(1_000)))
+		require.InDelta(t, 0.5, env.Value(at(1_050)), 1e-6)
+		require.InDelta(t, 1, env.Value(at(1_100)), 1e-6)
+		require.InDelta(t, 0.5, env.Value(at(1_150)), 1e-6)
+		require.InDelta(t, 0.25, env.Value(at(1_200)), 1e-6)
+		require.InDelta(t, 0.25, env.Value(at(5_000)), 1e-6)
+		require.False(t, env.Done(at(5_000)))
+
+		env.Release(at(5_000))
+		require.InDelta(t, 0.25, env.Value(at(5_000)), 1e-6)
+		require.InDelta(t, 0.005, env.Value(at(5_100)), 1e-6)
+		require.InDelta(t, 0, env.Value(at(5_200)), 1e-6)
+		require.False(t, env.Done(at(5_199)))
+		require.True(t, env.Done(at(5_200)))
+
+		// Releasing again does nothing.
+		env.Release(at(5_100))
+		require.InDelta(t, 0.005, env.Value(at(5_100)), 1e-6)
+
+		// Triggering again restarts the envelope.
+		env.Trigger(at(6_000))
+		require.False(t, env.Done(at(6_000)))
+		require.InDelta(t, 0.5, env.Value(at(6_050)), 1e-6)
+	})
+
+	t.Run("early release", func(t *testing.T) {
+		env := NewADSR(100*time.Millisecond, 100*time.Millisecond, 0.25, 100*time.Millisecond)
+		env.ReleaseSegments[0].Shape = Linear
+
+		env.Trigger(at(0))
+		env.Release(at(50))
+		require.InDelta(t, 0.5, env.Value(at(50)), 1e-6)
+		require.InDelta(t, 0.25, env.Value(at(100)), 1e-6)
+		require.InDelta(t, 0, env.Value(at(150)), 1e-6)
+		require.True(t, env.Done(at(150)))
+
+		// Times before the release still follow the original stages.
+		require.InDelta(t, 0.25, env.Value(at(25)), 1e-6)
+		require.False(t, env.Done(at(25)))
+	})
+
+	t.Run("breakpoints", func(t *testing.T) {
+		env := NewBreakpoints(0.5,
+			Segment{Duration: 0, Level: 1},
+			Segment{Duration: 100 * time.Millisecond, Level: 0.5, Shape: Linear},
+			Segment{Duration: 100 * time.Millisecond, Level: 0.005, Shape: Exponential},
+		)
+
+		env.Trigger(at(0))
+		require.InDelta(t, 1, env.Value(at(0)), 1e-6)
+		require.InDelta(t, 0.75, env.Value(at(50)), 1e-6)
+		require.InDelta(t, 0.05, env.Value(at(150)), 1e-6)
+		require.InDelta(t, 0.005, env.Value(at(1_000)), 1e-6)
+
+		// An envelope without release stages holds its level after it's released.
+		env.Release(at(1_000))
+		require.InDelta(t, 0.005, env.Value(at(2_000)), 1e-6)
+		require.True(t, env.Done(at(1_000)))
+	})
+}
+
+// Test_Envelope_Levels tests that Envelope's Levels method matches the envelope's value at every
+// sample.
+func Test_Envelope_Levels(t *testing.T) {
+	ctx := context.NewContext()
+	env := NewAHDSR(10*time.Millisecond, 5*time.Millisecond, 30*time.Millisecond, 0.3, 50*time.Millisecond)
+	env.Trigger(ctx)
+
+	ctx.SetTime(ctx.Time().ShiftBy(100))
+	start := ctx.Time()
+
+	levels := make([]float32, 2_000)
+	env.Levels(ctx, levels)
+	require.Equal(t, start, ctx.Time())
+
+	for i, level := range levels {
+		ctx.SetTime(start.ShiftBy(i))
+		require.Equal(t, env.Value(ctx), level)
+	}
+}
+
+// Test_Envelope_ApplyGain tests that Envelope's ApplyGain method scales a tone's gain.
+func Test_Envelope_ApplyGain(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+	env := NewADSR(100*time.Millisecond, 0, 1, 0)
+	env.Trigger(ctx)
+	ctx.SetTime(ctx.Time().ShiftBy(25))
+
+	require.NotPanics(t, func() { env.ApplyGain(ctx, nil, 1) })
+
+	tn := tone.NewToneAt(ctx, 440)
+	env.ApplyGain(ctx, &tn, 0.8)
+	require.InDelta(t, 0.2, tn.Gain, 1e-6)
+
+	// Applying the envelope again doesn't compound.
+	env.ApplyGain(ctx, &tn, 0.8)
+	require.InDelta(t, 0.2, tn.Gain, 1e-6)
+
+	t.Run("nil envelope", func(t *testing.T) {
+		var env *Envelope
+		tn := tone.NewToneWith(ctx, 440, 0.5, nil)
+		env.ApplyGain(ctx, &tn, 0.8)
+		require.Equal(t, float32(0.5), tn.Gain)
+	})
+}
+
+// Test_Envelope_ApplyHarmonicGain tests that Envelope's ApplyHarmonicGain method scales one of a
+// tone's harmonic gains.
+func Test_Envelope_ApplyHarmonicGain(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+	env := NewADSR(100*time.Millisecond, 0, 1, 0)
+	env.Trigger(ctx)
+	ctx.SetTime(ctx.Time().ShiftBy(50))
+
+	require.NotPanics(t, func() { env.ApplyHarmonicGain(ctx, nil, 0, 1) })
+
+	tn := tone.NewSawtoothTone(ctx, 440)
+	want := tn.Clone()
+
+	env.ApplyHarmonicGain(ctx, &tn, -1, 1)
+	env.ApplyHarmonicGain(ctx, &tn, len(tn.HarmonicGains), 1)
+	require.Equal(t, want, tn)
+
+	env.ApplyHarmonicGain(ctx, &tn, 2, 0.6)
+	want.HarmonicGains[2] = 0.3
+	require.InDeltaSlice(t, want.HarmonicGains, tn.HarmonicGains, 1e-6)
+
+	t.Run("nil envelope", func(t *testing.T) {
+		var env *Envelope
+		tn := tone.NewSawtoothTone(ctx, 440)
+		want := tn.Clone()
+		env.ApplyHarmonicGain(ctx, &tn, 2, 0.6)
+		require.Equal(t, want, tn)
+	})
+}
@@ -0,0 +1,90 @@
+package envelope
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// Lowest level that an exponential segment can reach. Exponential curves can never actually
+	// reach 0, so any level below this (-80dB) is treated as this level instead.
+	minExpLevel = 1e-4
+)
+
+// A Shape is the curve that a segment follows as it moves from one level to the next.
+type Shape int
+
+const (
+	// Linear segments change level at a constant rate.
+	Linear Shape = iota
+
+	// Exponential segments change level by a constant ratio, which sounds like a constant rate of
+	// change to the human ear. They are the most natural shape for decays and releases. If either
+	// level of an exponential segment is negative, the segment is linear instead.
+	Exponential
+
+	// Curved segments bend according to their curvature. A positive curvature starts slowly and
+	// ends quickly, while a negative curvature starts quickly and ends slowly. A curvature of 0 is
+	// the same as a linear segment.
+	Curved
+)
+
+// A Segment is one stage of an envelope, where the envelope moves from the level it is at to a new
+// level over some amount of time.
+type Segment struct {
+	// Duration is how long the segment takes to reach its level. A segment with no duration jumps
+	// to its level immediately.
+	Duration time.Duration
+
+	// Level is the level that the segment ends at.
+	Level float32
+
+	// Shape is the curve that the segment follows.
+	Shape Shape
+
+	// Curvature is how much a Curved segment bends. It is ignored for all other shapes.
+	Curvature float32
+}
+
+// levelAt returns the level of the segment after the specified amount of time, given the level
+// that the segment started at.
+func (segment Segment) levelAt(from float32, elapsed time.Duration) float32 {
+	if elapsed >= segment.Duration {
+		return segment.Level
+	}
+	if elapsed <= 0 {
+		return from
+	}
+
+	x := float64(elapsed) / float64(segment.Duration)
+	a, b := float64(from), float64(segment.Level)
+
+	switch {
+	case segment.Shape == Exponential && a >= 0 && b >= 0:
+		a, b = max(a, minExpLevel), max(b, minExpLevel)
+		return float32(a * math.Pow(b/a, x))
+
+	case segment.Shape == Curved && segment.Curvature != 0:
+		c := float64(segment.Curvature)
+		return float32(a + (b-a)*(1-math.Exp(c*x))/(1-math.Exp(c)))
+
+	default:
+		return float32(a + (b-a)*x)
+	}
+}
+
+// levelAt runs through the segments in order, starting at the specified level, and returns the
+// level after the specified amount of time. It also reports if every segment has finished.
+func levelAt(from float32, segments []Segment, elapsed time.Duration) (float32, bool) {
+	level := from
+	for _, segment := range segments {
+		if elapsed < segment.Duration {
+			return segment.levelAt(level, elapsed), false
+		}
+
+		elapsed -= segment.Duration
+		level = segment.Level
+	}
+
+	return level, true
+}
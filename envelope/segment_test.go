@@ -0,0 +1,96 @@
+package envelope
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Segment_levelAt tests that Segment's levelAt method follows the segment's shape.
+func Test_Segment_levelAt(t *testing.T) {
+	t.Run("bounds", func(t *testing.T) {
+		for _, shape := range []Shape{Linear, Exponential, Curved} {
+			segment := Segment{Duration: time.Second, Level: 0.25, Shape: shape, Curvature: 3}
+			require.Equal(t, float32(0.75), segment.levelAt(0.75, -time.Second))
+			require.Equal(t, float32(0.75), segment.levelAt(0.75, 0))
+			require.Equal(t, float32(0.25), segment.levelAt(0.75, time.Second))
+			require.Equal(t, float32(0.25), segment.levelAt(0.75, time.Hour))
+		}
+	})
+
+	t.Run("no duration", func(t *testing.T) {
+		segment := Segment{Level: 0.5}
+		require.Equal(t, float32(0.5), segment.levelAt(1, 0))
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		segment := Segment{Duration: time.Second, Level: 1, Shape: Linear}
+		require.InDelta(t, 0.25, segment.levelAt(0, 250*time.Millisecond), 1e-6)
+		require.InDelta(t, 0.5, segment.levelAt(0, 500*time.Millisecond), 1e-6)
+		require.InDelta(t, -0.5, segment.levelAt(-2, 500*time.Millisecond), 1e-6)
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		segment := Segment{Duration: time.Second, Level: 0.01, Shape: Exponential}
+		require.InDelta(t, 0.1, segment.levelAt(1, 500*time.Millisecond), 1e-6)
+
+		// Levels of 0 are treated as -80dB.
+		segment = Segment{Duration: time.Second, Level: 0, Shape: Exponential}
+		require.InDelta(t, 0.01, segment.levelAt(1, 500*time.Millisecond), 1e-6)
+
+		// Negative levels fall back to a linear curve.
+		segment = Segment{Duration: time.Second, Level: -1, Shape: Exponential}
+		require.InDelta(t, 0, segment.levelAt(1, 500*time.Millisecond), 1e-6)
+	})
+
+	t.Run("curved", func(t *testing.T) {
+		linear := Segment{Duration: time.Second, Level: 1, Shape: Linear}
+		flat := Segment{Duration: time.Second, Level: 1, Shape: Curved}
+		slow := Segment{Duration: time.Second, Level: 1, Shape: Curved, Curvature: 4}
+		fast := Segment{Duration: time.Second, Level: 1, Shape: Curved, Curvature: -4}
+
+		for elapsed := 50 * time.Millisecond; elapsed < time.Second; elapsed += 50 * time.Millisecond {
+			want := linear.levelAt(0, elapsed)
+			require.Equal(t, want, flat.levelAt(0, elapsed))
+			require.Less(t, slow.levelAt(0, elapsed), want)
+			require.Greater(t, fast.levelAt(0, elapsed), want)
+		}
+
+		want := (1 - math.Exp(2)) / (1 - math.Exp(4))
+		require.InDelta(t, want, slow.levelAt(0, 500*time.Millisecond), 1e-6)
+	})
+}
+
+// Test_levelAt tests that levelAt runs through a list of segments in order.
+func Test_levelAt(t *testing.T) {
+	segments := []Segment{
+		{Duration: 100 * time.Millisecond, Level: 1},
+		{Duration: 0, Level: 0.5},
+		{Duration: 200 * time.Millisecond, Level: 0.1},
+	}
+
+	type subtest struct {
+		elapsed time.Duration
+		want    float32
+		done    bool
+	}
+
+	for _, subtest := range []subtest{
+		{0, 0, false},
+		{50 * time.Millisecond, 0.5, false},
+		{100 * time.Millisecond, 0.5, false},
+		{200 * time.Millisecond, 0.3, false},
+		{300 * time.Millisecond, 0.1, true},
+		{time.Minute, 0.1, true},
+	} {
+		have, done := levelAt(0, segments, subtest.elapsed)
+		require.InDelta(t, subtest.want, have, 1e-6)
+		require.Equal(t, subtest.done, done)
+	}
+
+	level, done := levelAt(0.7, nil, time.Second)
+	require.Equal(t, float32(0.7), level)
+	require.True(t, done)
+}
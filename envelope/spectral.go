@@ -0,0 +1,141 @@
+package envelope
+
+import (
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+)
+
+// A Spectral envelope shapes a tone's timbre over time by giving each of its harmonics its own
+// envelope. This makes it possible to build sounds whose brightness changes over the life of a
+// note, like brass that brightens as it swells or a plucked string whose upper partials die away
+// before its lower ones.
+type Spectral struct {
+	// Base holds the gain of each harmonic when its envelope is at a level of 1. A harmonic without
+	// a base gain uses a gain of 1.
+	Base []float32
+
+	// Harmonics holds the envelope for each harmonic. The first envelope is for the first harmonic
+	// (the second partial), the second envelope is for the second harmonic, and so on. A harmonic
+	// with a nil envelope always has a level of 1.
+	Harmonics []*Envelope
+}
+
+// NewSpectral creates a spectral envelope that has room for an envelope for every harmonic gain in
+// a tone for this context. The base gains are copied from base, and every harmonic starts without
+// an envelope.
+func NewSpectral(ctx context.Context, base []float32) *Spectral {
+	numHarmGains := tone.NumHarmGains(ctx)
+
+	spectral := Spectral{
+		Base:      make([]float32, numHarmGains),
+		Harmonics: make([]*Envelope, numHarmGains),
+	}
+	for i := range spectral.Base {
+		spectral.Base[i] = 1
+	}
+	copy(spectral.Base, base)
+
+	return &spectral
+}
+
+// NewStaggeredAttack creates a spectral envelope where every harmonic rises to its base gain, holds
+// it until released, and then falls back to 0. Each harmonic takes longer to rise than the one
+// before it: the first harmonic takes the attack time, and every harmonic after that takes another
+// stagger times the attack time. This produces a sound that brightens as it starts, like a brass
+// instrument.
+func NewStaggeredAttack(ctx context.Context, base []float32, attack time.Duration, stagger float32, release time.Duration) *Spectral {
+	spectral := NewSpectral(ctx, base)
+	for i := range spectral.Harmonics {
+		harmAttack := attack + time.Duration(float32(attack)*stagger*float32(i))
+		spectral.Harmonics[i] = NewADSR(harmAttack, 0, 1, release)
+	}
+
+	return spectral
+}
+
+// NewStaggeredDecay creates a spectral envelope where every harmonic starts at its base gain and
+// then decays exponentially to silence. Each harmonic decays faster than the one before it: the
+// first harmonic takes the decay time, and every harmonic after that takes the time of the previous
+// harmonic divided by 1 + stagger. This produces a sound that darkens as it rings out, like a
+// plucked string.
+func NewStaggeredDecay(ctx context.Context, base []float32, decay time.Duration, stagger float32) *Spectral {
+	spectral := NewSpectral(ctx, base)
+
+	harmDecay := decay
+	for i := range spectral.Harmonics {
+		spectral.Harmonics[i] = NewBreakpoints(1, Segment{Duration: harmDecay, Level: 0, Shape: Exponential})
+		harmDecay = time.Duration(float32(harmDecay) / (1 + stagger))
+	}
+
+	return spectral
+}
+
+// Trigger triggers every harmonic's envelope at the context's current time.
+func (spectral *Spectral) Trigger(ctx context.Context) {
+	if spectral == nil {
+		return
+	}
+
+	for _, env := range spectral.Harmonics {
+		env.Trigger(ctx)
+	}
+}
+
+// Release releases every harmonic's envelope at the context's current time.
+func (spectral *Spectral) Release(ctx context.Context) {
+	if spectral == nil {
+		return
+	}
+
+	for _, env := range spectral.Harmonics {
+		env.Release(ctx)
+	}
+}
+
+// Done reports if every harmonic's envelope has finished its release stages by the context's
+// current time. Harmonics without an envelope are always done.
+func (spectral *Spectral) Done(ctx context.Context) bool {
+	if spectral == nil || ctx == nil {
+		return false
+	}
+
+	for _, env := range spectral.Harmonics {
+		if env != nil && !env.Done(ctx) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HarmonicGains fills gains with the gain of each harmonic at the context's current time. If there
+// are more gains than harmonic envelopes, the extra gains are set to their base gains.
+func (spectral *Spectral) HarmonicGains(ctx context.Context, gains []float32) {
+	if spectral == nil || ctx == nil {
+		return
+	}
+
+	for i := range gains {
+		gain := float32(1)
+		if i < len(spectral.Base) {
+			gain = spectral.Base[i]
+		}
+
+		if i < len(spectral.Harmonics) && spectral.Harmonics[i] != nil {
+			gain *= spectral.Harmonics[i].Value(ctx)
+		}
+
+		gains[i] = gain
+	}
+}
+
+// Apply sets all of the tone's harmonic gains to their values at the context's current time.
+func (spectral *Spectral) Apply(ctx context.Context, t *tone.Tone) {
+	if t == nil {
+		return
+	}
+
+	spectral.HarmonicGains(ctx, t.HarmonicGains)
+}
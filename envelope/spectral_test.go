@@ -0,0 +1,146 @@
+package envelope
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewSpectral tests that NewSpectral builds a spectral envelope with room for every harmonic.
+func Test_NewSpectral(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := context.NewContext()
+		spectral := NewSpectral(ctx, []float32{0.5, 0.25})
+		require.NotNil(t, spectral)
+		require.Len(t, spectral.Base, tone.NumHarmGains(ctx))
+		require.Len(t, spectral.Harmonics, tone.NumHarmGains(ctx))
+		require.Equal(t, float32(0.5), spectral.Base[0])
+		require.Equal(t, float32(0.25), spectral.Base[1])
+		for i := 2; i < len(spectral.Base); i++ {
+			require.Equal(t, float32(1), spectral.Base[i])
+		}
+		for _, env := range spectral.Harmonics {
+			require.Nil(t, env)
+		}
+	})
+
+	t.Run("custom number of harmonic gains", func(t *testing.T) {
+		tone.SetNumHarmGains(5)
+		defer tone.SetNumHarmGains(tone.DefaultNumHarmGains)
+		ctx := context.NewContext()
+
+		base := []float32{1, 2, 3, 4, 5, 6, 7}
+		spectral := NewSpectral(ctx, base)
+		require.Equal(t, base[:5], spectral.Base)
+		require.Len(t, spectral.Harmonics, 5)
+	})
+}
+
+// Test_NewStaggeredAttack tests that NewStaggeredAttack gives higher harmonics longer attacks.
+func Test_NewStaggeredAttack(t *testing.T) {
+	tone.SetNumHarmGains(4)
+	defer tone.SetNumHarmGains(tone.DefaultNumHarmGains)
+	ctx := context.NewContext()
+
+	spectral := NewStaggeredAttack(ctx, nil, 100*time.Millisecond, 0.5, 20*time.Millisecond)
+	require.Len(t, spectral.Harmonics, 4)
+	for i, env := range spectral.Harmonics {
+		want := NewADSR(time.Duration(100+50*i)*time.Millisecond, 0, 1, 20*time.Millisecond)
+		require.Equal(t, want, env)
+	}
+}
+
+// Test_NewStaggeredDecay tests that NewStaggeredDecay gives higher harmonics shorter decays.
+func Test_NewStaggeredDecay(t *testing.T) {
+	tone.SetNumHarmGains(3)
+	defer tone.SetNumHarmGains(tone.DefaultNumHarmGains)
+	ctx := context.NewContext()
+
+	spectral := NewStaggeredDecay(ctx, nil, 400*time.Millisecond, 1)
+	require.Len(t, spectral.Harmonics, 3)
+	for i, decay := range []time.Duration{400, 200, 100} {
+		want := NewBreakpoints(1, Segment{Duration: decay * time.Millisecond, Level: 0, Shape: Exponential})
+		require.Equal(t, want, spectral.Harmonics[i])
+	}
+}
+
+// Test_Spectral tests that a spectral envelope moves each harmonic through its own envelope as the
+// context's time advances.
+func Test_Spectral(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var spectral *Spectral
+		ctx := context.NewContext()
+		require.NotPanics(t, func() { spectral.Trigger(ctx) })
+		require.NotPanics(t, func() { spectral.Release(ctx) })
+		require.NotPanics(t, func() { spectral.HarmonicGains(ctx, make([]float32, 10)) })
+		require.NotPanics(t, func() { spectral.Apply(ctx, nil) })
+		require.False(t, spectral.Done(ctx))
+	})
+
+	// Use a sample rate of 1kHz so that every sample is one millisecond.
+	tone.SetNumHarmGains(3)
+	defer tone.SetNumHarmGains(tone.DefaultNumHarmGains)
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+	at := func(ms int) context.Context {
+		ctx.SetTime(context.NewTimeWith(1_000).ShiftBy(ms))
+		return ctx
+	}
+
+	spectral := NewSpectral(ctx, []float32{0.5, 0.4, 0.2})
+	spectral.Harmonics[0] = NewADSR(100*time.Millisecond, 0, 1, 100*time.Millisecond)
+	spectral.Harmonics[1] = NewADSR(200*time.Millisecond, 0, 1, 100*time.Millisecond)
+	spectral.Harmonics[0].ReleaseSegments[0].Shape = Linear
+	spectral.Harmonics[1].ReleaseSegments[0].Shape = Linear
+
+	gains := make([]float32, 4)
+	spectral.Trigger(at(0))
+
+	spectral.HarmonicGains(at(50), gains)
+	require.InDeltaSlice(t, []float32{0.25, 0.1, 0.2, 1}, gains, 1e-6)
+
+	spectral.HarmonicGains(at(100), gains)
+	require.InDeltaSlice(t, []float32{0.5, 0.2, 0.2, 1}, gains, 1e-6)
+
+	spectral.HarmonicGains(at(1_000), gains)
+	require.InDeltaSlice(t, []float32{0.5, 0.4, 0.2, 1}, gains, 1e-6)
+	require.False(t, spectral.Done(at(1_000)))
+
+	spectral.Release(at(1_000))
+	spectral.HarmonicGains(at(1_050), gains)
+	require.InDeltaSlice(t, []float32{0.25, 0.2, 0.2, 1}, gains, 1e-6)
+	require.False(t, spectral.Done(at(1_050)))
+	require.True(t, spectral.Done(at(1_100)))
+
+	// The gains are evaluated against the context's time, so earlier times are unchanged.
+	spectral.HarmonicGains(at(50), gains)
+	require.InDeltaSlice(t, []float32{0.25, 0.1, 0.2, 1}, gains, 1e-6)
+}
+
+// Test_Spectral_Apply tests that Spectral's Apply method sets a tone's harmonic gains.
+func Test_Spectral_Apply(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	saw := tone.NewSawtoothTone(ctx, 110)
+	spectral := NewStaggeredDecay(ctx, saw.HarmonicGains, time.Second, 0.5)
+	spectral.Trigger(ctx)
+
+	tn := saw.Clone()
+	spectral.Apply(ctx, &tn)
+	require.Equal(t, saw, tn)
+
+	// As time passes, each harmonic that is still sounding is quieter than the one below it relative
+	// to its base gain.
+	ctx.SetTime(ctx.Time().ShiftBy(200))
+	spectral.Apply(ctx, &tn)
+	require.Equal(t, saw.Gain, tn.Gain)
+	for i := 1; i < 4; i++ {
+		require.Less(t, tn.HarmonicGains[i]/saw.HarmonicGains[i], tn.HarmonicGains[i-1]/saw.HarmonicGains[i-1])
+	}
+}
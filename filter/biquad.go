@@ -0,0 +1,214 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Kind is the shape of a biquad filter's frequency response.
+type Kind int
+
+const (
+	// LowPass passes frequencies below the cutoff frequency and attenuates frequencies above it.
+	LowPass Kind = iota + 1
+	// HighPass passes frequencies above the cutoff frequency and attenuates frequencies below it.
+	HighPass
+	// BandPass passes frequencies around the center frequency, with a peak gain of 0dB, and
+	// attenuates frequencies on either side of it.
+	BandPass
+	// Notch attenuates frequencies around the center frequency and passes the rest.
+	Notch
+	// Peaking boosts or cuts frequencies around the center frequency by the filter's gain.
+	Peaking
+	// LowShelf boosts or cuts frequencies below the corner frequency by the filter's gain.
+	LowShelf
+	// HighShelf boosts or cuts frequencies above the corner frequency by the filter's gain.
+	HighShelf
+)
+
+// String returns the name of the kind.
+func (kind Kind) String() string {
+	switch kind {
+	case LowPass:
+		return "low-pass"
+	case HighPass:
+		return "high-pass"
+	case BandPass:
+		return "band-pass"
+	case Notch:
+		return "notch"
+	case Peaking:
+		return "peaking"
+	case LowShelf:
+		return "low shelf"
+	case HighShelf:
+		return "high shelf"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultQ is the Q of a second-order Butterworth filter, which has the flattest passband
+// possible without any resonance.
+const DefaultQ = math.Sqrt2 / 2
+
+// Params describes a biquad filter from Robert Bristow-Johnson's Audio EQ Cookbook.
+type Params struct {
+	// Kind is the shape of the filter's frequency response.
+	Kind Kind
+
+	// Frequency is the filter's cutoff, center, or corner frequency, in Hz, depending on its kind.
+	Frequency float32
+
+	// Q is the filter's quality factor. Higher values make the filter's resonance or band
+	// narrower. If this is not set, DefaultQ is used. For shelves, DefaultQ gives the steepest
+	// slope without any overshoot.
+	Q float32
+
+	// GainDB is the gain of peaking and shelf filters, in decibels. Other kinds ignore it.
+	GainDB float32
+}
+
+// Coefficients calculates the coefficients of the filter at the sample rate. The frequency is
+// limited to just below the Nyquist frequency. Filters with an unknown kind, a frequency that isn't
+// positive, or a sample rate that isn't positive pass audio through unchanged.
+func (params Params) Coefficients(sampleRate int) Coefficients {
+	if sampleRate <= 0 || params.Frequency <= 0 {
+		return passthrough
+	}
+
+	q := float64(params.Q)
+	if q <= 0 {
+		q = DefaultQ
+	}
+
+	freq := min(float64(params.Frequency), 0.4999*float64(sampleRate))
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin / (2 * q)
+	a := math.Pow(10, float64(params.GainDB)/40)
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch params.Kind {
+	case LowPass:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case HighPass:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case BandPass:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case Notch:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case Peaking:
+		b0, b1, b2 = 1+alpha*a, -2*cos, 1-alpha*a
+		a0, a1, a2 = 1+alpha/a, -2*cos, 1-alpha/a
+	case LowShelf:
+		sqrtA := 2 * math.Sqrt(a) * alpha
+		b0, b1, b2 = a*((a+1)-(a-1)*cos+sqrtA), 2*a*((a-1)-(a+1)*cos), a*((a+1)-(a-1)*cos-sqrtA)
+		a0, a1, a2 = (a+1)+(a-1)*cos+sqrtA, -2*((a-1)+(a+1)*cos), (a+1)+(a-1)*cos-sqrtA
+	case HighShelf:
+		sqrtA := 2 * math.Sqrt(a) * alpha
+		b0, b1, b2 = a*((a+1)+(a-1)*cos+sqrtA), -2*a*((a-1)+(a+1)*cos), a*((a+1)+(a-1)*cos-sqrtA)
+		a0, a1, a2 = (a+1)-(a-1)*cos+sqrtA, 2*((a-1)-(a+1)*cos), (a+1)-(a-1)*cos-sqrtA
+	default:
+		return passthrough
+	}
+
+	return Coefficients{
+		B0: b0 / a0,
+		B1: b1 / a0,
+		B2: b2 / a0,
+		A1: a1 / a0,
+		A2: a2 / a0,
+	}
+}
+
+// Coefficients are the coefficients of a biquad filter's transfer function, normalized so that a0
+// is 1:
+//
+//	H(z) = (B0 + B1*z^-1 + B2*z^-2) / (1 + A1*z^-1 + A2*z^-2)
+type Coefficients struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// Coefficients of a filter that passes audio through unchanged
+var passthrough = Coefficients{B0: 1}
+
+// Response returns the filter's complex frequency response at the frequency, in Hz, at the sample
+// rate.
+func (c Coefficients) Response(freq float32, sampleRate int) complex128 {
+	if sampleRate <= 0 {
+		return 1
+	}
+
+	w := 2 * math.Pi * float64(freq) / float64(sampleRate)
+	z1 := cmplx.Exp(complex(0, -w))
+	z2 := z1 * z1
+
+	num := complex(c.B0, 0) + complex(c.B1, 0)*z1 + complex(c.B2, 0)*z2
+	den := 1 + complex(c.A1, 0)*z1 + complex(c.A2, 0)*z2
+
+	return num / den
+}
+
+// Gain returns the filter's gain at the frequency, in Hz, at the sample rate.
+func (c Coefficients) Gain(freq float32, sampleRate int) float32 {
+	return float32(cmplx.Abs(c.Response(freq, sampleRate)))
+}
+
+// A Biquad is a second-order recursive filter. It's the building block for every filter in this
+// package. A Biquad is not safe for concurrent use by multiple goroutines.
+type Biquad struct {
+	Coefficients
+
+	// State of the filter in transposed direct form II
+	z1, z2 float64
+}
+
+// NewBiquad creates a biquad filter with the parameters at the context's sample rate.
+func NewBiquad(ctx context.Context, params Params) *Biquad {
+	var sampleRate int
+	if ctx != nil {
+		sampleRate = ctx.SampleRate()
+	}
+
+	return &Biquad{
+		Coefficients: params.Coefficients(sampleRate),
+	}
+}
+
+// Process filters the samples in place.
+func (biquad *Biquad) Process(ctx context.Context, samples []float32) {
+	if biquad == nil {
+		return
+	}
+
+	for i, sample := range samples {
+		samples[i] = biquad.next(sample)
+	}
+}
+
+// Reset clears the filter's state.
+func (biquad *Biquad) Reset() {
+	if biquad == nil {
+		return
+	}
+
+	biquad.z1, biquad.z2 = 0, 0
+}
+
+// next filters a single sample.
+func (biquad *Biquad) next(sample float32) float32 {
+	x := float64(sample)
+	y := biquad.B0*x + biquad.z1
+	biquad.z1 = biquad.B1*x - biquad.A1*y + biquad.z2
+	biquad.z2 = biquad.B2*x - biquad.A2*y
+
+	return float32(y)
+}
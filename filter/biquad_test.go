@@ -0,0 +1,208 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Kind_String tests that Kind's String method returns the name of each kind.
+func Test_Kind_String(t *testing.T) {
+	require.Equal(t, "unknown", Kind(0).String())
+	require.Equal(t, "low-pass", LowPass.String())
+	require.Equal(t, "high-pass", HighPass.String())
+	require.Equal(t, "band-pass", BandPass.String())
+	require.Equal(t, "notch", Notch.String())
+	require.Equal(t, "peaking", Peaking.String())
+	require.Equal(t, "low shelf", LowShelf.String())
+	require.Equal(t, "high shelf", HighShelf.String())
+	require.Equal(t, "unknown", Kind(100).String())
+}
+
+// analogResponse returns the response of the analog prototype of a cookbook filter at s. The
+// cookbook filters are the bilinear transform of these prototypes, prewarped so that the cutoff
+// frequency lands in the same place.
+func analogResponse(params Params, s complex128) complex128 {
+	q := complex(float64(params.Q), 0)
+	if params.Q == 0 {
+		q = DefaultQ
+	}
+	a := complex(math.Pow(10, float64(params.GainDB)/40), 0)
+	sqrtA := cmplx.Sqrt(a)
+
+	switch params.Kind {
+	case LowPass:
+		return 1 / (s*s + s/q + 1)
+	case HighPass:
+		return s * s / (s*s + s/q + 1)
+	case BandPass:
+		return (s / q) / (s*s + s/q + 1)
+	case Notch:
+		return (s*s + 1) / (s*s + s/q + 1)
+	case Peaking:
+		return (s*s + s*a/q + 1) / (s*s + s/(a*q) + 1)
+	case LowShelf:
+		return a * (s*s + sqrtA/q*s + a) / (a*s*s + sqrtA/q*s + 1)
+	case HighShelf:
+		return a * (a*s*s + sqrtA/q*s + 1) / (s*s + sqrtA/q*s + a)
+	default:
+		return 1
+	}
+}
+
+// warp returns the frequency of the analog prototype that the bilinear transform maps to the
+// digital frequency, relative to the cutoff frequency.
+func warp(freq, cutoff float32, sampleRate int) float64 {
+	return math.Tan(math.Pi*float64(freq)/float64(sampleRate)) / math.Tan(math.Pi*float64(cutoff)/float64(sampleRate))
+}
+
+// Test_Params_Coefficients tests that the cookbook filters have the frequency response of their
+// analog prototypes.
+func Test_Params_Coefficients(t *testing.T) {
+	t.Run("passthrough", func(t *testing.T) {
+		require.Equal(t, passthrough, Params{Kind: LowPass, Frequency: 1_000}.Coefficients(0))
+		require.Equal(t, passthrough, Params{Kind: LowPass, Frequency: 1_000}.Coefficients(-1))
+		require.Equal(t, passthrough, Params{Kind: LowPass}.Coefficients(44_100))
+		require.Equal(t, passthrough, Params{Kind: LowPass, Frequency: -1}.Coefficients(44_100))
+		require.Equal(t, passthrough, Params{Frequency: 1_000}.Coefficients(44_100))
+		require.Equal(t, passthrough, Params{Kind: 100, Frequency: 1_000}.Coefficients(44_100))
+	})
+
+	t.Run("default Q", func(t *testing.T) {
+		want := Params{Kind: LowPass, Frequency: 1_000, Q: DefaultQ}.Coefficients(44_100)
+		have := Params{Kind: LowPass, Frequency: 1_000}.Coefficients(44_100)
+		require.InDeltaSlice(t, []float64{want.B0, want.B1, want.B2, want.A1, want.A2}, []float64{have.B0, have.B1, have.B2, have.A1, have.A2}, 1e-7)
+	})
+
+	const sampleRate = 48_000
+	kinds := []Kind{LowPass, HighPass, BandPass, Notch, Peaking, LowShelf, HighShelf}
+	for _, kind := range kinds {
+		for _, cutoff := range []float32{50, 1_000, 10_000} {
+			for _, q := range []float32{0, 0.5, 2, 10} {
+				for _, gainDB := range []float32{-12, 0, 6} {
+					params := Params{Kind: kind, Frequency: cutoff, Q: q, GainDB: gainDB}
+					c := params.Coefficients(sampleRate)
+
+					for freq := float32(10); freq < sampleRate/2; freq *= 1.5 {
+						want := analogResponse(params, complex(0, warp(freq, cutoff, sampleRate)))
+						have := c.Response(freq, sampleRate)
+						require.InDelta(t, cmplx.Abs(want), float64(c.Gain(freq, sampleRate)), 1e-4, "%v at %v Hz", params, freq)
+						require.InDelta(t, real(want), real(have), 1e-4, "%v at %v Hz", params, freq)
+						require.InDelta(t, imag(want), imag(have), 1e-4, "%v at %v Hz", params, freq)
+					}
+				}
+			}
+		}
+	}
+
+	t.Run("landmarks", func(t *testing.T) {
+		gainDB := func(params Params, freq float32) float64 {
+			return 20 * math.Log10(float64(params.Coefficients(sampleRate).Gain(freq, sampleRate)))
+		}
+
+		require.InDelta(t, 0, gainDB(Params{Kind: LowPass, Frequency: 1_000}, 0), 1e-5)
+		require.InDelta(t, -3.0103, gainDB(Params{Kind: LowPass, Frequency: 1_000}, 1_000), 1e-4)
+		require.InDelta(t, -3.0103, gainDB(Params{Kind: HighPass, Frequency: 1_000}, 1_000), 1e-4)
+		require.InDelta(t, 0, gainDB(Params{Kind: BandPass, Frequency: 1_000, Q: 4}, 1_000), 1e-5)
+		require.Less(t, gainDB(Params{Kind: Notch, Frequency: 1_000}, 1_000), -200.0)
+		require.InDelta(t, 9, gainDB(Params{Kind: Peaking, Frequency: 1_000, Q: 3, GainDB: 9}, 1_000), 1e-5)
+		require.InDelta(t, -9, gainDB(Params{Kind: LowShelf, Frequency: 1_000, GainDB: -9}, 0), 1e-5)
+		require.InDelta(t, 0, gainDB(Params{Kind: LowShelf, Frequency: 1_000, GainDB: -9}, sampleRate/2), 1e-5)
+		require.InDelta(t, 0, gainDB(Params{Kind: HighShelf, Frequency: 1_000, GainDB: 4}, 0), 1e-5)
+		require.InDelta(t, 4, gainDB(Params{Kind: HighShelf, Frequency: 1_000, GainDB: 4}, sampleRate/2), 1e-5)
+	})
+}
+
+// Test_Coefficients_Response tests that Response handles invalid sample rates.
+func Test_Coefficients_Response(t *testing.T) {
+	c := Params{Kind: LowPass, Frequency: 1_000}.Coefficients(44_100)
+	require.Equal(t, complex128(1), c.Response(1_000, 0))
+	require.Equal(t, complex128(1), passthrough.Response(1_000, 44_100))
+}
+
+// sine returns n samples of a sine wave with an amplitude of 1.
+func sine(freq float32, sampleRate int, n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(math.Sin(2 * math.Pi * float64(freq) * float64(i) / float64(sampleRate)))
+	}
+
+	return samples
+}
+
+// amplitude returns the peak value of the samples, ignoring the first few so that the filter has
+// time to settle.
+func amplitude(samples []float32) float32 {
+	var peak float32
+	for _, sample := range samples[len(samples)/2:] {
+		peak = max(peak, float32(math.Abs(float64(sample))))
+	}
+
+	return peak
+}
+
+// Test_Biquad_Process tests that Biquad filters samples with the frequency response of its
+// coefficients.
+func Test_Biquad_Process(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var biquad *Biquad
+		samples := []float32{1, 2, 3}
+		biquad.Process(context.NewContext(), samples)
+		biquad.Reset()
+		require.Equal(t, []float32{1, 2, 3}, samples)
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		biquad := NewBiquad(nil, Params{Kind: LowPass, Frequency: 1_000})
+		require.Equal(t, passthrough, biquad.Coefficients)
+
+		samples := []float32{1, 2, 3}
+		biquad.Process(nil, samples)
+		require.Equal(t, []float32{1, 2, 3}, samples)
+	})
+
+	t.Run("impulse", func(t *testing.T) {
+		// Feeding in an impulse plays back the difference equation one step at a time.
+		biquad := Biquad{Coefficients: Coefficients{B0: 0.5, B1: 0.25, B2: 0.125, A1: -0.5, A2: 0.25}}
+		samples := []float32{1, 0, 0, 0}
+		biquad.Process(nil, samples)
+		require.Equal(t, []float32{0.5, 0.5, 0.25, 0}, samples)
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 44_100,
+	})
+	for _, params := range []Params{
+		{Kind: LowPass, Frequency: 1_000},
+		{Kind: HighPass, Frequency: 500, Q: 2},
+		{Kind: Peaking, Frequency: 3_000, Q: 1, GainDB: 6},
+	} {
+		t.Run(fmt.Sprint(params), func(t *testing.T) {
+			biquad := NewBiquad(ctx, params)
+			require.Equal(t, params.Coefficients(44_100), biquad.Coefficients)
+
+			for _, freq := range []float32{100, 1_000, 3_000, 10_000} {
+				biquad.Reset()
+				samples := sine(freq, 44_100, 8_000)
+				biquad.Process(ctx, samples)
+				require.InDelta(t, biquad.Gain(freq, 44_100), amplitude(samples), 2e-3, freq)
+			}
+
+			// Splitting the samples into blocks doesn't change the result.
+			want := sine(440, 44_100, 1_000)
+			biquad.Reset()
+			biquad.Process(ctx, want)
+
+			have := sine(440, 44_100, 1_000)
+			biquad.Reset()
+			for i := 0; i < len(have); i += 77 {
+				biquad.Process(ctx, have[i:min(i+77, len(have))])
+			}
+			require.Equal(t, want, have)
+		})
+	}
+}
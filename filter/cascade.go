@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Cascade is a chain of biquad filters that audio passes through one after another. Higher-order
+// filters are built by cascading second-order sections. A Cascade is not safe for concurrent use by
+// multiple goroutines.
+type Cascade struct {
+	Sections []Biquad
+}
+
+// NewButterworth creates a low-pass or high-pass Butterworth filter of the order at the context's
+// sample rate. Butterworth filters have the flattest possible passband, and every order past the
+// first makes the slope past the cutoff frequency 6dB per octave steeper. The gain at the cutoff
+// frequency is -3dB. The kind must be LowPass or HighPass; any other kind, or an order that isn't
+// positive, makes a filter that passes audio through unchanged.
+func NewButterworth(ctx context.Context, kind Kind, freq float32, order int) *Cascade {
+	var sampleRate int
+	if ctx != nil {
+		sampleRate = ctx.SampleRate()
+	}
+
+	return &Cascade{
+		Sections: butterworth(kind, freq, order, sampleRate),
+	}
+}
+
+// NewLinkwitzRiley creates a low-pass or high-pass Linkwitz-Riley filter of the order at the
+// context's sample rate. A Linkwitz-Riley filter is two Butterworth filters of half the order in a
+// row, so the gain at the cutoff frequency is -6dB, and the low-pass and high-pass filters at the
+// same frequency add up to a flat response, which makes them a good choice for crossovers. The
+// order must be even; an odd order is rounded down. The kind must be LowPass or HighPass; any
+// other kind, or an order less than 2, makes a filter that passes audio through unchanged.
+func NewLinkwitzRiley(ctx context.Context, kind Kind, freq float32, order int) *Cascade {
+	var sampleRate int
+	if ctx != nil {
+		sampleRate = ctx.SampleRate()
+	}
+
+	sections := butterworth(kind, freq, order/2, sampleRate)
+
+	return &Cascade{
+		Sections: append(sections, sections...),
+	}
+}
+
+// Process filters the samples in place through every section.
+func (cascade *Cascade) Process(ctx context.Context, samples []float32) {
+	if cascade == nil {
+		return
+	}
+
+	for i := range cascade.Sections {
+		cascade.Sections[i].Process(ctx, samples)
+	}
+}
+
+// Reset clears the state of every section.
+func (cascade *Cascade) Reset() {
+	if cascade == nil {
+		return
+	}
+
+	for i := range cascade.Sections {
+		cascade.Sections[i].Reset()
+	}
+}
+
+// Response returns the cascade's complex frequency response at the frequency, in Hz, at the sample
+// rate.
+func (cascade *Cascade) Response(freq float32, sampleRate int) complex128 {
+	response := complex128(1)
+	if cascade == nil {
+		return response
+	}
+
+	for _, section := range cascade.Sections {
+		response *= section.Response(freq, sampleRate)
+	}
+
+	return response
+}
+
+// Gain returns the cascade's gain at the frequency, in Hz, at the sample rate.
+func (cascade *Cascade) Gain(freq float32, sampleRate int) float32 {
+	return float32(cmplx.Abs(cascade.Response(freq, sampleRate)))
+}
+
+// butterworth returns the sections of a Butterworth filter. Each pair of complex poles becomes a
+// second-order section with the pole pair's Q, and odd orders have one more first-order section for
+// the real pole.
+func butterworth(kind Kind, freq float32, order int, sampleRate int) []Biquad {
+	if (kind != LowPass && kind != HighPass) || order <= 0 {
+		return nil
+	}
+
+	sections := make([]Biquad, 0, (order+1)/2)
+	for k := 1; k <= order/2; k++ {
+		angle := math.Pi * float64(order-2*k+1) / float64(2*order)
+		params := Params{
+			Kind:      kind,
+			Frequency: freq,
+			Q:         float32(1 / (2 * math.Cos(angle))),
+		}
+		sections = append(sections, Biquad{Coefficients: params.Coefficients(sampleRate)})
+	}
+	if order%2 == 1 {
+		sections = append(sections, Biquad{Coefficients: firstOrder(kind, freq, sampleRate)})
+	}
+
+	return sections
+}
+
+// firstOrder returns the coefficients of a first-order low-pass or high-pass filter.
+func firstOrder(kind Kind, freq float32, sampleRate int) Coefficients {
+	if sampleRate <= 0 || freq <= 0 {
+		return passthrough
+	}
+
+	k := math.Tan(math.Pi * min(float64(freq), 0.4999*float64(sampleRate)) / float64(sampleRate))
+	a1 := (k - 1) / (k + 1)
+	if kind == HighPass {
+		return Coefficients{B0: 1 / (1 + k), B1: -1 / (1 + k), A1: a1}
+	}
+
+	return Coefficients{B0: k / (1 + k), B1: k / (1 + k), A1: a1}
+}
@@ -0,0 +1,139 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewButterworth tests that Butterworth filters have the analytic Butterworth frequency
+// response.
+func Test_NewButterworth(t *testing.T) {
+	const sampleRate = 48_000
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: sampleRate,
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		require.Empty(t, NewButterworth(ctx, BandPass, 1_000, 4).Sections)
+		require.Empty(t, NewButterworth(ctx, LowPass, 1_000, 0).Sections)
+		require.Empty(t, NewButterworth(ctx, LowPass, 1_000, -2).Sections)
+		require.Equal(t, float32(1), NewButterworth(ctx, Notch, 1_000, 4).Gain(1_000, sampleRate))
+
+		cascade := NewButterworth(nil, LowPass, 1_000, 3)
+		require.Len(t, cascade.Sections, 2)
+		for _, section := range cascade.Sections {
+			require.Equal(t, passthrough, section.Coefficients)
+		}
+	})
+
+	for _, kind := range []Kind{LowPass, HighPass} {
+		for order := 1; order <= 8; order++ {
+			for _, cutoff := range []float32{100, 2_000, 15_000} {
+				cascade := NewButterworth(ctx, kind, cutoff, order)
+				require.Len(t, cascade.Sections, (order+1)/2)
+
+				for freq := float32(10); freq < sampleRate/2; freq *= 1.3 {
+					r := warp(freq, cutoff, sampleRate)
+					if kind == HighPass {
+						r = 1 / r
+					}
+					want := 1 / math.Sqrt(1+math.Pow(r, float64(2*order)))
+					require.InDelta(t, want, cascade.Gain(freq, sampleRate), 1e-5, "order %d %v at %v Hz", order, kind, freq)
+				}
+
+				// Every Butterworth filter is 3dB down at its cutoff frequency.
+				require.InDelta(t, -3.0103, 20*math.Log10(float64(cascade.Gain(cutoff, sampleRate))), 1e-4)
+			}
+		}
+	}
+}
+
+// Test_NewLinkwitzRiley tests that Linkwitz-Riley filters have the analytic Linkwitz-Riley
+// frequency response and that their low-pass and high-pass filters add up to a flat response.
+func Test_NewLinkwitzRiley(t *testing.T) {
+	const sampleRate = 44_100
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: sampleRate,
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		require.Empty(t, NewLinkwitzRiley(ctx, Peaking, 1_000, 4).Sections)
+		require.Empty(t, NewLinkwitzRiley(ctx, LowPass, 1_000, 1).Sections)
+	})
+
+	for _, order := range []int{2, 4, 8} {
+		for _, cutoff := range []float32{200, 3_000} {
+			lowPass := NewLinkwitzRiley(ctx, LowPass, cutoff, order)
+			highPass := NewLinkwitzRiley(ctx, HighPass, cutoff, order)
+			require.Len(t, lowPass.Sections, 2*((order/2+1)/2))
+
+			// An odd order is rounded down.
+			require.Equal(t, lowPass, NewLinkwitzRiley(ctx, LowPass, cutoff, order+1))
+
+			for freq := float32(10); freq < sampleRate/2; freq *= 1.3 {
+				r := warp(freq, cutoff, sampleRate)
+				want := 1 / (1 + math.Pow(r, float64(order)))
+				require.InDelta(t, want, lowPass.Gain(freq, sampleRate), 1e-5, "order %d at %v Hz", order, freq)
+
+				want = 1 / (1 + math.Pow(1/r, float64(order)))
+				require.InDelta(t, want, highPass.Gain(freq, sampleRate), 1e-5, "order %d at %v Hz", order, freq)
+
+				// The crossover's outputs sum to an all-pass filter. Second-order crossovers need one
+				// of the outputs to be inverted.
+				sum := lowPass.Response(freq, sampleRate) + highPass.Response(freq, sampleRate)
+				if order%4 == 2 {
+					sum = lowPass.Response(freq, sampleRate) - highPass.Response(freq, sampleRate)
+				}
+				require.InDelta(t, 1, cmplx.Abs(sum), 1e-5, "order %d at %v Hz", order, freq)
+			}
+
+			require.InDelta(t, -6.0206, 20*math.Log10(float64(lowPass.Gain(cutoff, sampleRate))), 1e-4)
+		}
+	}
+}
+
+// Test_Cascade_Process tests that Cascade filters samples through every section.
+func Test_Cascade_Process(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var cascade *Cascade
+		samples := []float32{1, 2, 3}
+		cascade.Process(context.NewContext(), samples)
+		cascade.Reset()
+		require.Equal(t, []float32{1, 2, 3}, samples)
+		require.Equal(t, complex128(1), cascade.Response(1_000, 44_100))
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 44_100,
+	})
+	for _, order := range []int{1, 4, 5} {
+		t.Run(fmt.Sprint(order), func(t *testing.T) {
+			cascade := NewButterworth(ctx, LowPass, 2_000, order)
+
+			for _, freq := range []float32{500, 2_000, 4_000} {
+				cascade.Reset()
+				samples := sine(freq, 44_100, 8_000)
+				cascade.Process(ctx, samples)
+				require.InDelta(t, cascade.Gain(freq, 44_100), amplitude(samples), 2e-3, freq)
+			}
+
+			// The sections are the same as running each one in turn.
+			want := sine(440, 44_100, 1_000)
+			for _, section := range NewButterworth(ctx, LowPass, 2_000, order).Sections {
+				section.Process(ctx, want)
+			}
+
+			have := sine(440, 44_100, 1_000)
+			cascade.Reset()
+			for i := 0; i < len(have); i += 100 {
+				cascade.Process(ctx, have[i:i+100])
+			}
+			require.Equal(t, want, have)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+)
+
+// A Filter changes the frequency content of audio that has already been rendered.
+type Filter interface {
+	// Process filters the samples in place. The first sample is at the context's current time, and
+	// every sample after it is one sample later. This does not advance the context's time.
+	Process(ctx context.Context, samples []float32)
+
+	// Reset clears the filter's memory of the samples that it has processed, as if it had never
+	// been used.
+	Reset()
+}
+
+// Apply returns a source that filters the audio from the source with the filter.
+func Apply(src source.Source, f Filter) source.Source {
+	return &filtered{
+		source: src,
+		filter: f,
+	}
+}
+
+type filtered struct {
+	source source.Source
+	filter Filter
+}
+
+// Fill fills samples from the original source and filters them.
+func (f *filtered) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil || f.source == nil {
+		return 0
+	}
+
+	// The samples have to be filtered at the time that they started at, not the time that the
+	// source advanced the context to.
+	start := context.WithTime(ctx, ctx.Time())
+	n := f.source.Fill(ctx, samples)
+	if f.filter != nil {
+		f.filter.Process(start, samples[:n])
+	}
+
+	return n
+}
+
+// Reset resets the original source and the filter.
+func (f *filtered) Reset() {
+	if resetter, ok := f.source.(source.Resetter); ok {
+		resetter.Reset()
+	}
+	if f.filter != nil {
+		f.filter.Reset()
+	}
+}
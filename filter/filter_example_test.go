@@ -0,0 +1,41 @@
+package filter_test
+
+import (
+	"fmt"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/filter"
+	"github.com/green-aloe/enobox/tone"
+)
+
+func ExampleNewButterworth() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 48_000,
+	})
+
+	lowPass := filter.NewButterworth(ctx, filter.LowPass, 1_000, 4)
+	for _, freq := range []float32{250, 500, 1_000, 2_000, 4_000} {
+		gain := lowPass.Gain(freq, ctx.SampleRate())
+		fmt.Printf("%v Hz: %.4f\n", freq, gain)
+	}
+
+	// Output:
+	// 250 Hz: 1.0000
+	// 500 Hz: 0.9981
+	// 1000 Hz: 0.7071
+	// 2000 Hz: 0.0613
+	// 4000 Hz: 0.0036
+}
+
+func ExampleToneFilter_Apply() {
+	ctx := context.NewContext()
+
+	// Darken a sawtooth tone by cutting its harmonics above 400 Hz, without rendering any audio.
+	t := tone.NewSawtoothTone(ctx, 100)
+	filter.ToneLowPass(400, 0).Apply(ctx, &t)
+
+	fmt.Printf("%.3f\n", t.HarmonicGains[:6])
+
+	// Output:
+	// [0.486 0.291 0.177 0.108 0.068 0.044]
+}
@@ -0,0 +1,62 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Apply tests that Apply filters the audio from a source.
+func Test_Apply(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.Zero(t, Apply(nil, &Biquad{}).Fill(ctx, make([]float32, 10)))
+		require.Zero(t, Apply(source.Silence(), &Biquad{}).Fill(nil, make([]float32, 10)))
+
+		samples := make([]float32, 3)
+		require.Equal(t, 3, Apply(source.Samples([]float32{1, 2, 3}), nil).Fill(ctx, samples))
+		require.Equal(t, []float32{1, 2, 3}, samples)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		tn := tone.NewSawtoothTone(ctx, 220)
+		params := Params{Kind: HighPass, Frequency: 1_000}
+
+		want := make([]float32, 1_000)
+		source.Tone(tn).Fill(context.NewContextWith(context.ContextOptions{SampleRate: 8_000}), want)
+		NewBiquad(ctx, params).Process(ctx, want)
+
+		start := ctx.Time()
+		src := Apply(source.Tone(tn), NewBiquad(ctx, params))
+		have := make([]float32, 1_000)
+		require.Equal(t, 300, src.Fill(ctx, have[:300]))
+		require.Equal(t, 700, src.Fill(ctx, have[300:]))
+		require.Equal(t, want, have)
+		require.Equal(t, start.ShiftBy(1_000), ctx.Time())
+
+		src.(source.Resetter).Reset()
+		require.Equal(t, 1_000, src.Fill(ctx, have))
+		require.Equal(t, want, have)
+	})
+
+	t.Run("time", func(t *testing.T) {
+		var times []context.Time
+		modulated := NewModulated(func(time context.Time) Params {
+			times = append(times, time)
+			return Params{}
+		})
+
+		start := ctx.Time()
+		src := Apply(source.Samples([]float32{1, 2, 3}), modulated)
+		require.Equal(t, 2, src.Fill(ctx, make([]float32, 2)))
+		require.Equal(t, 1, src.Fill(ctx, make([]float32, 2)))
+		require.Equal(t, []context.Time{start, start.ShiftBy(1), start.ShiftBy(2)}, times)
+		require.Equal(t, start.ShiftBy(3), ctx.Time())
+	})
+}
@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Modulated filter is a biquad filter whose parameters change over time, like a filter sweep or
+// a wah. The parameters are recalculated for every sample from the sample's time, so the filter
+// sounds the same no matter how the audio is split up into blocks. A Modulated filter is not safe
+// for concurrent use by multiple goroutines.
+type Modulated struct {
+	// Params returns the filter's parameters at a point in time.
+	Params func(time context.Time) Params
+
+	biquad Biquad
+}
+
+// NewModulated creates a filter whose parameters at every point in time come from params.
+func NewModulated(params func(time context.Time) Params) *Modulated {
+	return &Modulated{
+		Params: params,
+	}
+}
+
+// Process filters the samples in place, recalculating the filter's coefficients for every sample.
+// Samples pass through unchanged if the filter doesn't have any parameters.
+func (modulated *Modulated) Process(ctx context.Context, samples []float32) {
+	if modulated == nil || ctx == nil || modulated.Params == nil {
+		return
+	}
+
+	sampleRate := ctx.SampleRate()
+	time := ctx.Time()
+	for i, sample := range samples {
+		modulated.biquad.Coefficients = modulated.Params(time).Coefficients(sampleRate)
+		samples[i] = modulated.biquad.next(sample)
+		if !time.Empty() {
+			time = time.Increment()
+		}
+	}
+}
+
+// Reset clears the filter's state.
+func (modulated *Modulated) Reset() {
+	if modulated == nil {
+		return
+	}
+
+	modulated.biquad.Reset()
+}
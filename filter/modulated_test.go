@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Modulated_Process tests that Modulated recalculates its filter for the time of every sample.
+func Test_Modulated_Process(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		samples := []float32{1, 2, 3}
+
+		var modulated *Modulated
+		modulated.Process(ctx, samples)
+		modulated.Reset()
+
+		NewModulated(nil).Process(ctx, samples)
+		NewModulated(func(context.Time) Params { return Params{} }).Process(nil, samples)
+		require.Equal(t, []float32{1, 2, 3}, samples)
+	})
+
+	t.Run("constant", func(t *testing.T) {
+		params := Params{Kind: LowPass, Frequency: 500, Q: 2}
+
+		want := sine(300, 8_000, 500)
+		NewBiquad(ctx, params).Process(ctx, want)
+
+		have := sine(300, 8_000, 500)
+		NewModulated(func(context.Time) Params { return params }).Process(ctx, have)
+		require.Equal(t, want, have)
+	})
+
+	t.Run("times", func(t *testing.T) {
+		var times []context.Time
+		modulated := NewModulated(func(time context.Time) Params {
+			times = append(times, time)
+			return Params{}
+		})
+
+		start := context.NewTimeAt(1, 5, 8_000)
+		ctx.SetTime(start)
+		modulated.Process(ctx, make([]float32, 3))
+		require.Equal(t, []context.Time{start, start.ShiftBy(1), start.ShiftBy(2)}, times)
+		require.Equal(t, start, ctx.Time())
+
+		times = nil
+		modulated.Process(context.NewTestContext(), make([]float32, 2))
+		require.Equal(t, []context.Time{{}, {}}, times)
+	})
+
+	t.Run("sweep", func(t *testing.T) {
+		// Sweep the cutoff frequency up by 1 Hz every sample.
+		sweep := func(time context.Time) Params {
+			return Params{Kind: LowPass, Frequency: float32(100 + time.Second()*8_000 + time.Sample())}
+		}
+
+		start := context.NewTime()
+		ctx.SetTime(start)
+
+		want := sine(440, 8_000, 1_000)
+		biquad := Biquad{}
+		for i := range want {
+			biquad.Coefficients = sweep(start.ShiftBy(i)).Coefficients(8_000)
+			want[i] = biquad.next(want[i])
+		}
+
+		// The result is the same no matter how the samples are split into blocks.
+		modulated := NewModulated(sweep)
+		have := sine(440, 8_000, 1_000)
+		for i := 0; i < len(have); i += 128 {
+			ctx.SetTime(start.ShiftBy(i))
+			modulated.Process(ctx, have[i:min(i+128, len(have))])
+		}
+		require.Equal(t, want, have)
+
+		// Resetting starts the filter over.
+		modulated.Reset()
+		ctx.SetTime(start)
+		have = sine(440, 8_000, 1_000)
+		modulated.Process(ctx, have)
+		require.Equal(t, want, have)
+	})
+}
@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"math"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+)
+
+const (
+	// Most that a tone filter boosts a tone's harmonics relative to its fundamental frequency
+	// (+24dB). A tone's harmonic gains are relative to its fundamental frequency, so a filter that
+	// all but silences the fundamental frequency would otherwise boost the harmonics without any
+	// limit.
+	maxHarmonicBoost = 16
+
+	// Lowest gain at the fundamental frequency that a tone filter divides by (-80dB), so that a
+	// filter that silences the fundamental frequency completely doesn't divide by 0.
+	minFundamentalGain = 1e-4
+)
+
+// A ToneFilter filters a tone in the tone domain. Tones are already a list of partials, each with a
+// frequency and a gain, so instead of filtering the rendered audio, a tone filter scales the gain of
+// every harmonic by the filter's frequency response at the harmonic's frequency, relative to its
+// response at the fundamental frequency. This costs almost nothing next to filtering audio sample by
+// sample, but it only works on tones, and it changes the tone's harmonic gains directly.
+//
+// The function returns the filter's gain at a frequency, in Hz.
+type ToneFilter func(freq float32) float32
+
+// Apply filters the tone by rewriting its harmonic gains. A tone's harmonic gains are relative to
+// its fundamental frequency, so the fundamental frequency is kept as the reference: the tone's gain
+// is left alone, and each harmonic gain is scaled by the filter's gain at the harmonic's frequency
+// divided by the filter's gain at the fundamental frequency, up to +24dB. Harmonics at or above
+// the context's Nyquist frequency can't be heard, so they are removed.
+func (f ToneFilter) Apply(ctx context.Context, t *tone.Tone) {
+	if f == nil || ctx == nil || t == nil {
+		return
+	}
+
+	fundFreq, err := t.HarmonicFreq(1)
+	if err != nil {
+		return
+	}
+	ref := float32(math.Abs(float64(f(fundFreq))))
+	ref = max(ref, minFundamentalGain)
+
+	nyquist := ctx.NyqistFrequency()
+	for i := range t.HarmonicGains {
+		freq, err := t.HarmonicFreq(i + 2)
+		if err != nil || math.Abs(float64(freq)) >= float64(nyquist) {
+			t.HarmonicGains[i] = 0
+			continue
+		}
+
+		ratio := f(freq) / ref
+		t.HarmonicGains[i] *= min(max(ratio, -maxHarmonicBoost), maxHarmonicBoost)
+	}
+}
+
+// ToneChain returns a tone filter that applies every one of the filters, one after another.
+func ToneChain(filters ...ToneFilter) ToneFilter {
+	return func(freq float32) float32 {
+		gain := float32(1)
+		for _, f := range filters {
+			if f != nil {
+				gain *= f(freq)
+			}
+		}
+		return gain
+	}
+}
+
+// ToneLowPass returns a tone filter with the response of a resonant second-order low-pass filter.
+// Partials below the cutoff frequency are left alone, and partials above it fall off by 12dB per
+// octave. Higher Qs add a resonant peak at the cutoff frequency. If q is not positive, DefaultQ is
+// used.
+func ToneLowPass(cutoff, q float32) ToneFilter {
+	if q <= 0 {
+		q = DefaultQ
+	}
+
+	return func(freq float32) float32 {
+		if cutoff <= 0 {
+			return 0
+		}
+
+		r := float64(freq / cutoff)
+		return float32(1 / math.Hypot(1-r*r, r/float64(q)))
+	}
+}
+
+// TonePeak returns a tone filter with the response of a peaking filter, which boosts or cuts
+// partials around the center frequency by the gain, in decibels. Higher Qs make the peak narrower.
+// If q is not positive, DefaultQ is used.
+func TonePeak(center, q, gainDB float32) ToneFilter {
+	if q <= 0 {
+		q = DefaultQ
+	}
+	a := math.Pow(10, float64(gainDB)/40)
+
+	return func(freq float32) float32 {
+		if center <= 0 {
+			return 1
+		}
+
+		r := float64(freq / center)
+		num := math.Hypot(1-r*r, r*a/float64(q))
+		den := math.Hypot(1-r*r, r/(a*float64(q)))
+		return float32(num / den)
+	}
+}
+
+// ToneFormant returns a tone filter whose response is the sum of the formants' peaks.
+func ToneFormant(formants ...tone.Formant) ToneFilter {
+	return func(freq float32) float32 {
+		var sum float32
+		for _, formant := range formants {
+			sum += formant.Amplitude(freq)
+		}
+		return sum
+	}
+}
+
+// ToneComb returns a tone filter with the response of a comb filter, which is what happens when a
+// sound is mixed with a copy of itself that is delayed and scaled by the gain. A positive gain
+// boosts partials at multiples of 1/delay and cuts partials halfway between them, and a negative
+// gain does the opposite. A gain of 1 or -1 cuts those partials completely.
+func ToneComb(delay time.Duration, gain float32) ToneFilter {
+	return func(freq float32) float32 {
+		phase := 2 * math.Pi * float64(freq) * delay.Seconds()
+		return float32(math.Hypot(1+float64(gain)*math.Cos(phase), float64(gain)*math.Sin(phase)))
+	}
+}
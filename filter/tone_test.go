@@ -0,0 +1,186 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// partialAmplitudes returns the amplitude of every partial in the tone, starting with the
+// fundamental frequency.
+func partialAmplitudes(t tone.Tone) []float32 {
+	amplitudes := []float32{t.Gain}
+	for _, gain := range t.HarmonicGains {
+		amplitudes = append(amplitudes, t.Gain*gain)
+	}
+
+	return amplitudes
+}
+
+// Test_ToneFilter_Apply tests that ToneFilter's Apply method scales every partial in a tone by the
+// filter's response at the partial's frequency.
+func Test_ToneFilter_Apply(t *testing.T) {
+	ctx := context.NewContext()
+
+	t.Run("nil", func(t *testing.T) {
+		tn := tone.NewSawtoothTone(ctx, 100)
+		want := tn
+		want.HarmonicGains = append([]float32(nil), tn.HarmonicGains...)
+
+		var f ToneFilter
+		f.Apply(ctx, &tn)
+		ToneFilter(func(float32) float32 { return 0.5 }).Apply(nil, &tn)
+		ToneFilter(func(float32) float32 { return 0.5 }).Apply(ctx, nil)
+		require.Equal(t, want, tn)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		tn := tone.NewSawtoothTone(ctx, 100)
+		tn.Gain = 0.5
+		before := partialAmplitudes(tn)
+
+		// A filter that cuts every partial by its order.
+		ToneFilter(func(freq float32) float32 { return 100 / freq }).Apply(ctx, &tn)
+		after := partialAmplitudes(tn)
+		for i := range before {
+			require.InDelta(t, before[i]/float32(i+1), after[i], 1e-6, i)
+		}
+	})
+
+	t.Run("inharmonic", func(t *testing.T) {
+		tn := tone.Tone{Frequency: 100, Gain: 1, HarmonicGains: []float32{1, 1, 1}}
+		tn.PartialRatios = []float32{2.76, 5.4, 8.93}
+
+		ToneFilter(func(freq float32) float32 { return freq / 1_000 }).Apply(ctx, &tn)
+		require.InDeltaSlice(t, []float32{1, 2.76, 5.4, 8.93}, partialAmplitudes(tn), 1e-5)
+	})
+
+	t.Run("fundamental filtered out", func(t *testing.T) {
+		tn := tone.Tone{Frequency: 100, Gain: 1, HarmonicGains: []float32{1, 0.5}}
+		ToneFilter(func(freq float32) float32 {
+			if freq < 150 {
+				return 0
+			}
+			return 1
+		}).Apply(ctx, &tn)
+
+		// The fundamental frequency is kept, and the harmonics are only boosted by up to +24dB.
+		require.InDeltaSlice(t, []float32{1, 16, 8}, partialAmplitudes(tn), 1e-6)
+	})
+
+	t.Run("nyquist", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{SampleRate: 1_000})
+		tn := tone.Tone{Frequency: 200, Gain: 1, HarmonicGains: []float32{1, 1, 1}}
+
+		// Harmonics at or above 500Hz are removed.
+		ToneFilter(func(float32) float32 { return 1 }).Apply(ctx, &tn)
+		require.Equal(t, []float32{1, 1, 0, 0}, partialAmplitudes(tn))
+	})
+}
+
+// Test_ToneChain tests that ToneChain multiplies the responses of its filters together.
+func Test_ToneChain(t *testing.T) {
+	require.Equal(t, float32(1), ToneChain()(1_000))
+	require.Equal(t, float32(1), ToneChain(nil)(1_000))
+
+	chain := ToneChain(
+		func(freq float32) float32 { return 0.5 },
+		nil,
+		func(freq float32) float32 { return freq / 100 },
+	)
+	require.Equal(t, float32(0.5), chain(100))
+	require.Equal(t, float32(2), chain(400))
+}
+
+// Test_ToneLowPass tests that ToneLowPass has the response of an analog low-pass filter.
+func Test_ToneLowPass(t *testing.T) {
+	for _, q := range []float32{0, 0.5, 4} {
+		f := ToneLowPass(1_000, q)
+		for freq := float32(10); freq < 20_000; freq *= 1.3 {
+			params := Params{Kind: LowPass, Q: q}
+			want := cmplx.Abs(analogResponse(params, complex(0, float64(freq/1_000))))
+			require.InDelta(t, want, f(freq), 1e-5, "Q %v at %v Hz", q, freq)
+		}
+	}
+
+	f := ToneLowPass(1_000, 0)
+	require.Equal(t, float32(1), f(0))
+	require.InDelta(t, 1/math.Sqrt2, f(1_000), 1e-6)
+	require.InDelta(t, 4, ToneLowPass(1_000, 4)(1_000), 1e-6)
+	require.Zero(t, ToneLowPass(0, 1)(1_000))
+
+	// The filter cuts a tone's high harmonics.
+	ctx := context.NewContext()
+	tn := tone.NewSawtoothTone(ctx, 200)
+	tn.Gain = 1
+	f.Apply(ctx, &tn)
+	require.Equal(t, float32(1), tn.Gain)
+	require.InDelta(t, 0.5*f(400)/f(200), tn.HarmonicGains[0], 1e-6)
+	require.InDelta(t, 0.1*f(2_000)/f(200), tn.HarmonicGains[8], 1e-6)
+}
+
+// Test_TonePeak tests that TonePeak has the response of an analog peaking filter.
+func Test_TonePeak(t *testing.T) {
+	for _, q := range []float32{0, 1, 8} {
+		for _, gainDB := range []float32{-12, 6} {
+			f := TonePeak(2_000, q, gainDB)
+			for freq := float32(10); freq < 20_000; freq *= 1.3 {
+				params := Params{Kind: Peaking, Q: q, GainDB: gainDB}
+				want := cmplx.Abs(analogResponse(params, complex(0, float64(freq/2_000))))
+				require.InDelta(t, want, f(freq), 1e-5, "Q %v, %v dB at %v Hz", q, gainDB, freq)
+			}
+
+			require.InDelta(t, math.Pow(10, float64(gainDB)/20), f(2_000), 1e-5)
+		}
+	}
+
+	require.Equal(t, float32(1), TonePeak(0, 1, 6)(1_000))
+}
+
+// Test_ToneFormant tests that ToneFormant shapes a tone the same way FormantFormula does.
+func Test_ToneFormant(t *testing.T) {
+	formants := tone.VowelA.Formants()
+	f := ToneFormant(formants...)
+	require.Equal(t, formants[0].Amplitude(600)+formants[1].Amplitude(600)+formants[2].Amplitude(600)+
+		formants[3].Amplitude(600)+formants[4].Amplitude(600), f(600))
+	require.Zero(t, ToneFormant()(600))
+
+	// Filtering a tone with flat harmonics gives it the same harmonic gains as a formant tone.
+	ctx := context.NewContext()
+	want := tone.NewVowelTone(ctx, 110, tone.VowelA)
+
+	have := tone.NewToneAt(ctx, 110)
+	have.Gain = 1
+	for i := range have.HarmonicGains {
+		have.HarmonicGains[i] = 1
+	}
+	f.Apply(ctx, &have)
+	require.Equal(t, float32(1), have.Gain)
+	require.InDeltaSlice(t, want.HarmonicGains, have.HarmonicGains, 1e-4)
+}
+
+// Test_ToneComb tests that ToneComb has peaks and notches at the right frequencies.
+func Test_ToneComb(t *testing.T) {
+	f := ToneComb(time.Millisecond, 1)
+	require.InDelta(t, 2, f(0), 1e-6)
+	require.InDelta(t, 2, f(1_000), 1e-5)
+	require.InDelta(t, 0, f(500), 1e-5)
+	require.InDelta(t, 0, f(1_500), 1e-5)
+	require.InDelta(t, math.Sqrt2, f(250), 1e-5)
+
+	f = ToneComb(time.Millisecond, -0.5)
+	require.InDelta(t, 0.5, f(1_000), 1e-5)
+	require.InDelta(t, 1.5, f(500), 1e-5)
+
+	// A tone whose fundamental frequency and second harmonic land in the notches keeps its other
+	// harmonics, boosted as far as they go relative to its fundamental frequency.
+	ctx := context.NewContext()
+	tn := tone.Tone{Frequency: 500, Gain: 1, HarmonicGains: []float32{1, 1, 1}}
+	ToneComb(time.Millisecond, 1).Apply(ctx, &tn)
+	require.InDeltaSlice(t, []float32{1, 16, 0, 16}, partialAmplitudes(tn), 1e-4)
+}
@@ -0,0 +1,86 @@
+package mixer
+
+import (
+	"math"
+)
+
+// A Meter measures the level of the audio that passes through a channel or bus, so that clipping
+// and a lack of headroom can be caught. It keeps measuring until it's reset. The zero value is
+// ready to use.
+type Meter struct {
+	peak       float32
+	sumSquares float64
+	count      int
+}
+
+// Peak returns the largest absolute value of any sample measured.
+func (meter *Meter) Peak() float32 {
+	if meter == nil {
+		return 0
+	}
+
+	return meter.peak
+}
+
+// PeakDB returns the peak in decibels relative to full scale (dBFS), where 0 is a sample with a
+// value of 1. This is negative infinity if nothing but silence has been measured.
+func (meter *Meter) PeakDB() float32 {
+	return DBFromGain(meter.Peak())
+}
+
+// RMS returns the root mean square of every sample measured, which is closer to how loud the audio
+// sounds than the peak is.
+func (meter *Meter) RMS() float32 {
+	if meter == nil || meter.count == 0 {
+		return 0
+	}
+
+	return float32(math.Sqrt(meter.sumSquares / float64(meter.count)))
+}
+
+// RMSDB returns the RMS in decibels relative to full scale (dBFS).
+func (meter *Meter) RMSDB() float32 {
+	return DBFromGain(meter.RMS())
+}
+
+// Headroom returns how many decibels the peak is below full scale. This is negative if the audio
+// clipped.
+func (meter *Meter) Headroom() float32 {
+	return -meter.PeakDB()
+}
+
+// Clipped reports if any sample measured was outside of the range -1 to 1.
+func (meter *Meter) Clipped() bool {
+	return meter.Peak() > 1
+}
+
+// Reset clears everything that the meter has measured.
+func (meter *Meter) Reset() {
+	if meter == nil {
+		return
+	}
+
+	*meter = Meter{}
+}
+
+// measure adds the samples to the meter's measurements.
+func (meter *Meter) measure(samples []float32) {
+	for _, sample := range samples {
+		if v := float32(math.Abs(float64(sample))); v > meter.peak {
+			meter.peak = v
+		}
+		meter.sumSquares += float64(sample) * float64(sample)
+	}
+	meter.count += len(samples)
+}
+
+// GainFromDB converts a level in decibels into a linear gain. 0dB is a gain of 1, and every 6dB
+// roughly doubles or halves the gain.
+func GainFromDB(dB float32) float32 {
+	return float32(math.Pow(10, float64(dB)/20))
+}
+
+// DBFromGain converts a linear gain into a level in decibels. A gain of 0 is negative infinity.
+func DBFromGain(gain float32) float32 {
+	return float32(20 * math.Log10(math.Abs(float64(gain))))
+}
@@ -0,0 +1,80 @@
+package mixer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Meter tests that Meter measures the peak and RMS levels of samples.
+func Test_Meter(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var meter *Meter
+		require.Zero(t, meter.Peak())
+		require.Zero(t, meter.RMS())
+		require.False(t, meter.Clipped())
+		meter.Reset()
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		var meter Meter
+		require.Zero(t, meter.Peak())
+		require.Zero(t, meter.RMS())
+		require.True(t, math.IsInf(float64(meter.PeakDB()), -1))
+		require.True(t, math.IsInf(float64(meter.RMSDB()), -1))
+		require.True(t, math.IsInf(float64(meter.Headroom()), 1))
+
+		meter.measure(make([]float32, 10))
+		require.Zero(t, meter.Peak())
+		require.Zero(t, meter.RMS())
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		var meter Meter
+		meter.measure([]float32{0.5, -0.5})
+		meter.measure([]float32{-0.25, 0.25, 0.5, -0.5})
+		require.Equal(t, float32(0.5), meter.Peak())
+		require.InDelta(t, math.Sqrt((4*0.25+2*0.0625)/6), meter.RMS(), 1e-6)
+		require.InDelta(t, -6.0206, meter.PeakDB(), 1e-4)
+		require.InDelta(t, 6.0206, meter.Headroom(), 1e-4)
+		require.False(t, meter.Clipped())
+
+		meter.measure([]float32{1})
+		require.False(t, meter.Clipped())
+		require.Zero(t, meter.PeakDB())
+
+		meter.measure([]float32{-1.5})
+		require.True(t, meter.Clipped())
+		require.Less(t, meter.Headroom(), float32(0))
+
+		meter.Reset()
+		require.Equal(t, Meter{}, meter)
+	})
+}
+
+// Test_GainFromDB tests that GainFromDB and DBFromGain convert between decibels and linear gains.
+func Test_GainFromDB(t *testing.T) {
+	type subtest struct {
+		dB   float32
+		gain float32
+	}
+
+	subtests := []subtest{
+		{0, 1},
+		{20, 10},
+		{-20, 0.1},
+		{-40, 0.01},
+		{-6.0206, 0.5},
+		{6.0206, 2},
+	}
+
+	for _, subtest := range subtests {
+		require.InDelta(t, subtest.gain, GainFromDB(subtest.dB), 1e-5, subtest.dB)
+		require.InDelta(t, subtest.dB, DBFromGain(subtest.gain), 1e-4, subtest.gain)
+	}
+
+	require.Zero(t, GainFromDB(float32(math.Inf(-1))))
+	require.True(t, math.IsInf(float64(DBFromGain(0)), -1))
+	require.InDelta(t, -6.0206, DBFromGain(-0.5), 1e-4)
+}
@@ -0,0 +1,291 @@
+package mixer
+
+import (
+	"slices"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+)
+
+// A Mixer combines any number of sources into one stream of audio for a channel layout. Every
+// source plays on its own channel, which has its own gain, position, and mute and solo switches.
+// Channels can be grouped together on a bus so that they can be adjusted together. Every channel
+// and bus ends up on the master bus, which is what the mixer renders.
+//
+// Every channel and bus has a meter that measures its level after its gain is applied, which
+// makes it possible to catch clipping in automated tests.
+//
+// The zero value is a mono mixer that is ready to use. A Mixer is not safe for concurrent use by
+// multiple goroutines.
+type Mixer struct {
+	// Layout is the arrangement of speakers that the mixer renders for. The default, which is also
+	// used for invalid layouts, is mono.
+	Layout buffer.Layout
+
+	// PanLaw is the way that channels are split between neighboring speakers.
+	PanLaw buffer.PanLaw
+
+	// Master is the bus that every channel and bus is mixed into.
+	Master Bus
+
+	channels []*Channel
+	buses    []*Bus
+
+	// Mono samples of a single channel
+	mono []float32
+
+	// Gains of each speaker for a single channel
+	gains []float32
+}
+
+// A Channel is one input into a mixer.
+type Channel struct {
+	// Source is the audio that the channel plays. A channel without a source is silent.
+	Source source.Source
+
+	// GainDB is the channel's gain in decibels. 0 leaves the source's level unchanged.
+	GainDB float32
+
+	// Position places the channel around the listener.
+	Position buffer.Position
+
+	// Mute silences the channel. Its source still plays, so it stays in time with the other
+	// channels.
+	Mute bool
+
+	// Solo silences every channel that isn't soloed.
+	Solo bool
+
+	// Bus is the bus that the channel is mixed into. If this is nil or a bus from another mixer, the
+	// channel is mixed straight into the master bus.
+	Bus *Bus
+
+	// Meter measures the channel's level after its gain is applied, before it's panned.
+	Meter Meter
+
+	// Whether the source has ended
+	done bool
+}
+
+// A Bus groups channels together so that their gain can be adjusted and their level measured
+// together.
+type Bus struct {
+	// Name identifies the bus.
+	Name string
+
+	// GainDB is the bus's gain in decibels. 0 leaves the level of its channels unchanged.
+	GainDB float32
+
+	// Mute silences every channel on the bus.
+	Mute bool
+
+	// Meter measures the bus's level, across all of its speakers, after its gain is applied.
+	Meter Meter
+
+	// Mixer that the bus belongs to
+	mixer *Mixer
+
+	// Interleaved samples of every channel on the bus
+	samples []float32
+}
+
+// NewMixer creates a new mixer for the context's buffer layout.
+func NewMixer(ctx context.Context) *Mixer {
+	return &Mixer{
+		Layout: buffer.BufferLayout(ctx),
+	}
+}
+
+// AddChannel adds a new channel that plays the source and returns it. The channel is mixed into the
+// master bus at full volume in the center until it's changed.
+func (mixer *Mixer) AddChannel(src source.Source) *Channel {
+	if mixer == nil {
+		return nil
+	}
+
+	channel := &Channel{Source: src}
+	mixer.channels = append(mixer.channels, channel)
+
+	return channel
+}
+
+// RemoveChannel removes the channel from the mixer and reports if it was found.
+func (mixer *Mixer) RemoveChannel(channel *Channel) bool {
+	if mixer == nil {
+		return false
+	}
+
+	i := slices.Index(mixer.channels, channel)
+	if i < 0 {
+		return false
+	}
+	mixer.channels = slices.Delete(mixer.channels, i, i+1)
+
+	return true
+}
+
+// Channels returns every channel in the mixer, in the order that they were added.
+func (mixer *Mixer) Channels() []*Channel {
+	if mixer == nil {
+		return nil
+	}
+
+	return slices.Clone(mixer.channels)
+}
+
+// AddBus adds a new bus with the name and returns it. Channels are mixed into the bus by setting
+// their Bus field to it.
+func (mixer *Mixer) AddBus(name string) *Bus {
+	if mixer == nil {
+		return nil
+	}
+
+	bus := &Bus{
+		Name:  name,
+		mixer: mixer,
+	}
+	mixer.buses = append(mixer.buses, bus)
+
+	return bus
+}
+
+// Buses returns every bus in the mixer, not including the master bus, in the order that they were
+// added.
+func (mixer *Mixer) Buses() []*Bus {
+	if mixer == nil {
+		return nil
+	}
+
+	return slices.Clone(mixer.buses)
+}
+
+// Render fills samples with the next frames of audio from every channel, mixed together, starting
+// at the context's current time. Each frame holds one sample for every speaker in the mixer's
+// layout, interleaved. Every channel's source starts at the same time, and channels whose sources
+// have ended are silent. The context's time is advanced by the number of frames rendered.
+func (mixer *Mixer) Render(ctx context.Context, samples []float32) {
+	if mixer == nil || ctx == nil {
+		return
+	}
+
+	layout := mixer.Layout
+	if layout.Channels() == 0 {
+		layout = buffer.LayoutMono
+	}
+	numChannels := layout.Channels()
+	n := len(samples) / numChannels
+	samples = samples[:n*numChannels]
+	clear(samples)
+
+	if cap(mixer.mono) < n {
+		mixer.mono = make([]float32, n)
+	}
+	mono := mixer.mono[:n]
+	if cap(mixer.gains) < numChannels {
+		mixer.gains = make([]float32, numChannels)
+	}
+	gains := mixer.gains[:numChannels]
+
+	for _, bus := range mixer.buses {
+		if cap(bus.samples) < len(samples) {
+			bus.samples = make([]float32, len(samples))
+		}
+		bus.samples = bus.samples[:len(samples)]
+		clear(bus.samples)
+	}
+
+	soloed := slices.ContainsFunc(mixer.channels, func(channel *Channel) bool { return channel.Solo })
+	for _, channel := range mixer.channels {
+		channel.fill(ctx, mono)
+
+		gain := GainFromDB(channel.GainDB)
+		if channel.Mute || (soloed && !channel.Solo) {
+			gain = 0
+		}
+		for i := range mono {
+			mono[i] *= gain
+		}
+		channel.Meter.measure(mono)
+
+		out := samples
+		if bus := channel.Bus; bus != nil && bus.mixer == mixer {
+			out = bus.samples
+		}
+		layout.Gains(channel.Position, mixer.PanLaw, gains)
+		for i, sample := range mono {
+			frame := out[i*numChannels : (i+1)*numChannels]
+			for c, gain := range gains {
+				frame[c] += sample * gain
+			}
+		}
+	}
+
+	for _, bus := range mixer.buses {
+		bus.apply(bus.samples)
+		for i, sample := range bus.samples {
+			samples[i] += sample
+		}
+	}
+	mixer.Master.apply(samples)
+
+	context.Advance(ctx, n)
+}
+
+// Done reports if every channel's source has ended.
+func (mixer *Mixer) Done() bool {
+	if mixer == nil {
+		return true
+	}
+
+	for _, channel := range mixer.channels {
+		if channel.Source != nil && !channel.done {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResetMeters resets the meters of every channel and bus, including the master bus.
+func (mixer *Mixer) ResetMeters() {
+	if mixer == nil {
+		return
+	}
+
+	for _, channel := range mixer.channels {
+		channel.Meter.Reset()
+	}
+	for _, bus := range mixer.buses {
+		bus.Meter.Reset()
+	}
+	mixer.Master.Meter.Reset()
+}
+
+// fill fills samples from the channel's source and fills the rest with silence once the source
+// ends. The source gets its own copy of the context's time so that every channel starts at the same
+// time.
+func (channel *Channel) fill(ctx context.Context, samples []float32) {
+	var n int
+	if channel.Source != nil && !channel.done && len(samples) > 0 {
+		n = channel.Source.Fill(context.WithTime(ctx, ctx.Time()), samples)
+		if n < len(samples) {
+			channel.done = true
+		}
+	}
+	clear(samples[n:])
+}
+
+// apply applies the bus's gain and mute to the samples and measures them.
+func (bus *Bus) apply(samples []float32) {
+	gain := GainFromDB(bus.GainDB)
+	if bus.Mute {
+		gain = 0
+	}
+	if gain != 1 {
+		for i := range samples {
+			samples[i] *= gain
+		}
+	}
+	bus.Meter.measure(samples)
+}
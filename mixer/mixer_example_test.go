@@ -0,0 +1,48 @@
+package mixer_test
+
+import (
+	"fmt"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/mixer"
+	"github.com/green-aloe/enobox/source"
+	"github.com/green-aloe/enobox/tone"
+)
+
+func ExampleMixer() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	m := mixer.Mixer{
+		Layout: buffer.LayoutStereo,
+	}
+
+	// Play a bass note on the left and a melody note on the right, both on the same bus.
+	bus := m.AddBus("instruments")
+	bus.GainDB = -6
+
+	bass := m.AddChannel(source.Tone(tone.NewToneWith(ctx, 110, 1, nil)))
+	bass.Position.Pan = -0.5
+	bass.Bus = bus
+
+	melody := m.AddChannel(source.Tone(tone.NewToneWith(ctx, 440, 1, nil)))
+	melody.Position.Pan = 0.5
+	melody.GainDB = -3
+	melody.Bus = bus
+
+	samples := make([]float32, 2*8_000)
+	m.Render(ctx, samples)
+
+	fmt.Printf("bass:   peak %.1f dBFS, rms %.1f dBFS\n", bass.Meter.PeakDB(), bass.Meter.RMSDB())
+	fmt.Printf("melody: peak %.1f dBFS, rms %.1f dBFS\n", melody.Meter.PeakDB(), melody.Meter.RMSDB())
+	fmt.Printf("master: peak %.1f dBFS, clipped: %t\n", m.Master.Meter.PeakDB(), m.Master.Meter.Clipped())
+	fmt.Println(ctx.Time())
+
+	// Output:
+	// bass:   peak 0.0 dBFS, rms -3.0 dBFS
+	// melody: peak -3.0 dBFS, rms -6.0 dBFS
+	// master: peak -4.9 dBFS, clipped: false
+	// 1 second, sample 1/8000
+}
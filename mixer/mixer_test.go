@@ -0,0 +1,316 @@
+package mixer
+
+import (
+	"testing"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+	"github.com/stretchr/testify/require"
+)
+
+// constant returns a source that plays the value forever.
+func constant(v float32) source.Source {
+	return source.Func(func(ctx context.Context, samples []float32) int {
+		for i := range samples {
+			samples[i] = v
+		}
+		ctx.SetTime(ctx.Time().ShiftBy(len(samples)))
+		return len(samples)
+	})
+}
+
+// Test_NewMixer tests that NewMixer creates a mixer for the context's buffer layout.
+func Test_NewMixer(t *testing.T) {
+	defer buffer.SetBufferLayout(buffer.DefaultLayout)
+
+	mixer := NewMixer(context.NewContext())
+	require.Equal(t, buffer.LayoutMono, mixer.Layout)
+
+	buffer.SetBufferLayout(buffer.LayoutQuad)
+	mixer = NewMixer(context.NewContext())
+	require.Equal(t, buffer.LayoutQuad, mixer.Layout)
+}
+
+// Test_Mixer_AddChannel tests that channels can be added to and removed from a mixer.
+func Test_Mixer_AddChannel(t *testing.T) {
+	var mixer *Mixer
+	require.Nil(t, mixer.AddChannel(source.Silence()))
+	require.False(t, mixer.RemoveChannel(nil))
+	require.Nil(t, mixer.Channels())
+
+	mixer = &Mixer{}
+	require.Empty(t, mixer.Channels())
+
+	src := source.Silence()
+	first := mixer.AddChannel(src)
+	require.Equal(t, &Channel{Source: src}, first)
+	second := mixer.AddChannel(nil)
+	third := mixer.AddChannel(nil)
+	require.Equal(t, []*Channel{first, second, third}, mixer.Channels())
+
+	require.True(t, mixer.RemoveChannel(second))
+	require.False(t, mixer.RemoveChannel(second))
+	require.False(t, mixer.RemoveChannel(&Channel{}))
+	require.Equal(t, []*Channel{first, third}, mixer.Channels())
+
+	// Changing the returned slice doesn't change the mixer.
+	mixer.Channels()[0] = nil
+	require.Equal(t, []*Channel{first, third}, mixer.Channels())
+}
+
+// Test_Mixer_AddBus tests that buses can be added to a mixer.
+func Test_Mixer_AddBus(t *testing.T) {
+	var mixer *Mixer
+	require.Nil(t, mixer.AddBus("drums"))
+	require.Nil(t, mixer.Buses())
+
+	mixer = &Mixer{}
+	require.Empty(t, mixer.Buses())
+
+	drums := mixer.AddBus("drums")
+	require.Equal(t, "drums", drums.Name)
+	vocals := mixer.AddBus("vocals")
+	require.Equal(t, []*Bus{drums, vocals}, mixer.Buses())
+}
+
+// Test_Mixer_Render tests that Render mixes every channel together.
+func Test_Mixer_Render(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var mixer *Mixer
+		mixer.Render(context.NewContext(), make([]float32, 10))
+		(&Mixer{}).Render(nil, make([]float32, 10))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ctx := context.NewContext()
+		samples := []float32{1, 2, 3}
+		(&Mixer{}).Render(ctx, samples)
+		require.Equal(t, []float32{0, 0, 0}, samples)
+		require.Equal(t, context.NewTime().ShiftBy(3), ctx.Time())
+	})
+
+	t.Run("invalid layout", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		mixer := Mixer{Layout: buffer.Layout(99)}
+		mixer.AddChannel(constant(0.25))
+
+		// Invalid layouts are rendered in mono.
+		samples := make([]float32, 3)
+		require.NotPanics(t, func() { mixer.Render(ctx, samples) })
+		require.Equal(t, []float32{0.25, 0.25, 0.25}, samples)
+		require.Equal(t, context.NewTime().ShiftBy(3), ctx.Time())
+	})
+
+	t.Run("sum", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		var mixer Mixer
+		mixer.AddChannel(constant(0.25))
+		mixer.AddChannel(constant(0.5))
+		mixer.AddChannel(nil)
+
+		samples := make([]float32, 4)
+		mixer.Render(ctx, samples)
+		require.Equal(t, []float32{0.75, 0.75, 0.75, 0.75}, samples)
+
+		// Every source starts at the same time, and the context's time only moves forward once.
+		require.Equal(t, context.NewTime().ShiftBy(4), ctx.Time())
+	})
+
+	t.Run("time", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		var times []context.Time
+		src := source.Func(func(ctx context.Context, samples []float32) int {
+			times = append(times, ctx.Time())
+			ctx.SetTime(ctx.Time().ShiftBy(len(samples)))
+			return len(samples)
+		})
+
+		var mixer Mixer
+		mixer.AddChannel(src)
+		mixer.AddChannel(src)
+		mixer.Render(ctx, make([]float32, 10))
+		mixer.Render(ctx, make([]float32, 10))
+
+		start := context.NewTime()
+		require.Equal(t, []context.Time{start, start, start.ShiftBy(10), start.ShiftBy(10)}, times)
+	})
+
+	t.Run("gain", func(t *testing.T) {
+		var mixer Mixer
+		mixer.AddChannel(constant(0.25)).GainDB = 20
+		mixer.AddChannel(constant(1)).GainDB = -20
+
+		samples := make([]float32, 2)
+		mixer.Render(context.NewContext(), samples)
+		require.InDeltaSlice(t, []float32{2.6, 2.6}, samples, 1e-5)
+	})
+
+	t.Run("mute and solo", func(t *testing.T) {
+		var mixer Mixer
+		first := mixer.AddChannel(constant(1))
+		second := mixer.AddChannel(constant(2))
+		third := mixer.AddChannel(constant(4))
+
+		render := func() float32 {
+			samples := make([]float32, 1)
+			mixer.Render(context.NewContext(), samples)
+			return samples[0]
+		}
+
+		require.Equal(t, float32(7), render())
+
+		second.Mute = true
+		require.Equal(t, float32(5), render())
+
+		third.Solo = true
+		require.Equal(t, float32(4), render())
+
+		first.Solo = true
+		require.Equal(t, float32(5), render())
+
+		// Muting wins over soloing.
+		third.Mute = true
+		require.Equal(t, float32(1), render())
+
+		first.Mute = true
+		require.Zero(t, render())
+	})
+
+	t.Run("pan", func(t *testing.T) {
+		mixer := Mixer{
+			Layout: buffer.LayoutStereo,
+			PanLaw: buffer.PanLawLinear,
+		}
+		mixer.AddChannel(constant(1)).Position.Pan = -1
+		mixer.AddChannel(constant(0.5)).Position.Pan = 1
+		mixer.AddChannel(constant(0.25))
+
+		samples := make([]float32, 5)
+		mixer.Render(context.NewContext(), samples)
+		require.Equal(t, []float32{1.125, 0.625, 1.125, 0.625, 0}, samples)
+	})
+
+	t.Run("buses", func(t *testing.T) {
+		mixer := Mixer{
+			Layout: buffer.LayoutStereo,
+			PanLaw: buffer.PanLawLinear,
+		}
+		drums := mixer.AddBus("drums")
+		drums.GainDB = -6.0206
+		vocals := mixer.AddBus("vocals")
+		foreign := (&Mixer{}).AddBus("foreign")
+
+		kick := mixer.AddChannel(constant(1))
+		kick.Bus = drums
+		kick.Position.Pan = -1
+		snare := mixer.AddChannel(constant(0.5))
+		snare.Bus = drums
+		snare.Position.Pan = 1
+		mixer.AddChannel(constant(0.25)).Bus = vocals
+		mixer.AddChannel(constant(0.125)).Bus = foreign
+
+		samples := make([]float32, 2)
+		mixer.Render(context.NewContext(), samples)
+		require.InDeltaSlice(t, []float32{0.5 + 0.125 + 0.0625, 0.25 + 0.125 + 0.0625}, samples, 1e-5)
+
+		require.InDelta(t, 0.5, drums.Meter.Peak(), 1e-5)
+		require.Equal(t, float32(0.125), vocals.Meter.Peak())
+		require.Zero(t, foreign.Meter.Peak())
+
+		drums.Mute = true
+		mixer.Render(context.NewContext(), samples)
+		require.InDeltaSlice(t, []float32{0.125 + 0.0625, 0.125 + 0.0625}, samples, 1e-5)
+	})
+
+	t.Run("master", func(t *testing.T) {
+		var mixer Mixer
+		mixer.AddChannel(constant(0.5))
+		mixer.Master.GainDB = 6.0206
+
+		samples := make([]float32, 2)
+		mixer.Render(context.NewContext(), samples)
+		require.InDeltaSlice(t, []float32{1, 1}, samples, 1e-5)
+
+		mixer.Master.Mute = true
+		mixer.Render(context.NewContext(), samples)
+		require.Equal(t, []float32{0, 0}, samples)
+	})
+
+	t.Run("sources end", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		var mixer Mixer
+		mixer.AddChannel(source.Samples([]float32{1, 1, 1}))
+		mixer.AddChannel(source.Samples([]float32{2}))
+		require.False(t, mixer.Done())
+
+		samples := make([]float32, 2)
+		mixer.Render(ctx, samples)
+		require.Equal(t, []float32{3, 1}, samples)
+		require.False(t, mixer.Done())
+
+		mixer.Render(ctx, samples)
+		require.Equal(t, []float32{1, 0}, samples)
+		require.True(t, mixer.Done())
+
+		mixer.Render(ctx, samples)
+		require.Equal(t, []float32{0, 0}, samples)
+		require.Equal(t, context.NewTime().ShiftBy(6), ctx.Time())
+	})
+
+	t.Run("partial frame", func(t *testing.T) {
+		mixer := Mixer{
+			Layout: buffer.LayoutStereo,
+		}
+		mixer.AddChannel(constant(1))
+
+		samples := []float32{5, 5, 5}
+		mixer.Render(context.NewContext(), samples)
+		require.InDeltaSlice(t, []float32{0.7071, 0.7071, 5}, samples, 1e-4)
+	})
+}
+
+// Test_Mixer_meters tests that the meters of a mixer's channels and buses measure their levels.
+func Test_Mixer_meters(t *testing.T) {
+	mixer := Mixer{
+		Layout: buffer.LayoutStereo,
+		PanLaw: buffer.PanLawLinear,
+	}
+	bus := mixer.AddBus("bus")
+	loud := mixer.AddChannel(source.Samples([]float32{0.5, -1, 0.5, -1}))
+	loud.GainDB = 6.0206
+	loud.Bus = bus
+	quiet := mixer.AddChannel(constant(-0.25))
+	muted := mixer.AddChannel(constant(1))
+	muted.Mute = true
+
+	mixer.Render(context.NewContext(), make([]float32, 8))
+
+	require.InDelta(t, 2, loud.Meter.Peak(), 1e-5)
+	require.True(t, loud.Meter.Clipped())
+	require.InDelta(t, 1.5811, loud.Meter.RMS(), 1e-4)
+	require.Equal(t, float32(0.25), quiet.Meter.Peak())
+	require.Equal(t, float32(0.25), quiet.Meter.RMS())
+	require.False(t, quiet.Meter.Clipped())
+	require.Zero(t, muted.Meter.Peak())
+
+	// The bus and master meters measure every speaker.
+	require.InDelta(t, 1, bus.Meter.Peak(), 1e-5)
+	require.False(t, bus.Meter.Clipped())
+	require.InDelta(t, 1.125, mixer.Master.Meter.Peak(), 1e-5)
+	require.True(t, mixer.Master.Meter.Clipped())
+
+	mixer.ResetMeters()
+	require.Zero(t, loud.Meter.Peak())
+	require.Zero(t, quiet.Meter.Peak())
+	require.Zero(t, bus.Meter.Peak())
+	require.Zero(t, mixer.Master.Meter.Peak())
+
+	var nilMixer *Mixer
+	nilMixer.ResetMeters()
+	require.True(t, nilMixer.Done())
+}
@@ -0,0 +1,72 @@
+//go:build audio
+
+package playback
+
+import (
+	"github.com/faiface/beep"
+	device "github.com/faiface/beep/speaker"
+)
+
+// A DeviceSpeaker is a speaker that plays audio in real time on the system's default audio device.
+// It's only available when building with the audio build tag, since it needs audio hardware and,
+// on some systems, cgo and the system's audio libraries.
+//
+// The audio device is shared by the whole program, so only one DeviceSpeaker should be open at a
+// time.
+type DeviceSpeaker struct {
+	sampleRate beep.SampleRate
+	closed     bool
+}
+
+// NewDeviceSpeaker opens the default audio device at the sample rate. The device pulls bufferSize
+// samples at once; bigger buffers are less likely to skip, and smaller buffers have less latency.
+// If bufferSize is not set, DefaultBufferSize is used.
+func NewDeviceSpeaker(sampleRate beep.SampleRate, bufferSize int) (*DeviceSpeaker, error) {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	if err := device.Init(sampleRate, bufferSize); err != nil {
+		return nil, err
+	}
+
+	return &DeviceSpeaker{
+		sampleRate: sampleRate,
+	}, nil
+}
+
+// Play plays numSamples samples from the streamer on the audio device. It blocks until the samples
+// have been played or the streamer is drained. If the streamer has a format with a different sample
+// rate than the device, its audio is resampled to the device's.
+func (speaker *DeviceSpeaker) Play(streamer beep.Streamer, numSamples int) (int, error) {
+	if speaker == nil || speaker.closed {
+		return 0, ErrClosed
+	}
+	if streamer == nil || numSamples <= 0 {
+		return 0, nil
+	}
+
+	done := make(chan struct{})
+	t, stream := deviceStream(streamer, numSamples, speaker.sampleRate, func() { close(done) })
+	device.Play(stream)
+	<-done
+
+	// The device streams the audio on its own goroutine.
+	device.Lock()
+	defer device.Unlock()
+
+	return t.played, t.err
+}
+
+// Close closes the audio device. It must not be called while audio is playing.
+func (speaker *DeviceSpeaker) Close() error {
+	if speaker == nil || speaker.closed {
+		return ErrClosed
+	}
+	speaker.closed = true
+
+	device.Clear()
+	device.Close()
+
+	return nil
+}
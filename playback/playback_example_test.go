@@ -0,0 +1,30 @@
+package playback_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/playback"
+	"github.com/green-aloe/enobox/source"
+	"github.com/green-aloe/enobox/tone"
+)
+
+func ExamplePreview() {
+	ctx := context.NewContext()
+
+	tone := tone.NewSawtoothTone(ctx, 220)
+	tone.Gain = 0.5
+
+	var speaker playback.NullSpeaker
+	if err := playback.Preview(ctx, source.Tone(tone), &speaker, 2*time.Second); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(speaker.Played())
+	fmt.Println(ctx.Time())
+
+	// Output:
+	// 88200
+	// 2 seconds, sample 1/44100
+}
@@ -0,0 +1,243 @@
+package playback
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+	"github.com/green-aloe/enobox/wav"
+)
+
+const (
+	// DefaultBufferSize is the default number of samples that a speaker pulls from a streamer at
+	// once.
+	DefaultBufferSize = 512
+
+	// Quality of the resampling when a streamer's sample rate doesn't match a device's
+	resampleQuality = 4
+)
+
+var (
+	// ErrClosed is returned when playing audio on a speaker that has already been closed.
+	ErrClosed = errors.New("playback: speaker closed")
+)
+
+// A Speaker plays audio from a beep.Streamer. DeviceSpeaker plays audio on the system's audio
+// device in real time when building with the audio build tag. Speakers that don't need any audio
+// hardware make it possible to run playback code in headless environments like CI.
+type Speaker interface {
+	// Play plays up to numSamples samples from the streamer. It blocks until all of the samples
+	// have been played or the streamer is drained, and it returns the number of samples played.
+	Play(streamer beep.Streamer, numSamples int) (int, error)
+
+	// Close stops the speaker and releases any resources that it holds.
+	Close() error
+}
+
+// A NullSpeaker is a speaker that discards all of the audio played on it as fast as it can be
+// generated. It doesn't play in real time, so Play returns as soon as the audio has been generated,
+// which is usually much sooner than the audio would take to play. The zero value is ready to use.
+type NullSpeaker struct {
+	// BufferSize is the number of samples pulled from the streamer at once. If this is not set,
+	// DefaultBufferSize is used.
+	BufferSize int
+
+	played int
+	closed bool
+}
+
+// Play pulls numSamples samples from the streamer and discards them.
+func (speaker *NullSpeaker) Play(streamer beep.Streamer, numSamples int) (int, error) {
+	if speaker == nil || speaker.closed {
+		return 0, ErrClosed
+	}
+
+	n, err := pull(streamer, numSamples, speaker.BufferSize, func([][2]float64) error { return nil })
+	speaker.played += n
+
+	return n, err
+}
+
+// Played returns the total number of samples that have been played on the speaker.
+func (speaker *NullSpeaker) Played() int {
+	if speaker == nil {
+		return 0
+	}
+
+	return speaker.played
+}
+
+// Close closes the speaker.
+func (speaker *NullSpeaker) Close() error {
+	if speaker == nil || speaker.closed {
+		return ErrClosed
+	}
+	speaker.closed = true
+
+	return nil
+}
+
+// A FileSpeaker is a speaker that writes all of the audio played on it to a WAV file.
+type FileSpeaker struct {
+	// BufferSize is the number of samples pulled from the streamer at once. If this is not set,
+	// DefaultBufferSize is used.
+	BufferSize int
+
+	encoder *wav.Encoder
+	samples []float32
+}
+
+// NewFileSpeaker returns a speaker that writes audio to w as a WAV file with the sample rate and
+// number of channels (1 or 2) of the format. The file is not complete until the speaker is closed.
+func NewFileSpeaker(w io.WriteSeeker, format beep.Format, encoding wav.Encoding) (*FileSpeaker, error) {
+	if format.NumChannels != 1 && format.NumChannels != 2 {
+		return nil, wav.ErrInvalidFormat
+	}
+
+	encoder, err := wav.NewEncoder(w, wav.Format{
+		SampleRate:  int(format.SampleRate),
+		NumChannels: format.NumChannels,
+		Encoding:    encoding,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSpeaker{
+		encoder: encoder,
+	}, nil
+}
+
+// Play pulls numSamples samples from the streamer and writes them to the file. If the file is mono,
+// the left and right channels are averaged together.
+func (speaker *FileSpeaker) Play(streamer beep.Streamer, numSamples int) (int, error) {
+	if speaker == nil || speaker.encoder == nil {
+		return 0, ErrClosed
+	}
+
+	numChannels := speaker.encoder.Format().NumChannels
+
+	return pull(streamer, numSamples, speaker.BufferSize, func(chunk [][2]float64) error {
+		speaker.samples = speaker.samples[:0]
+		for _, sample := range chunk {
+			if numChannels == 1 {
+				speaker.samples = append(speaker.samples, float32((sample[0]+sample[1])/2))
+			} else {
+				speaker.samples = append(speaker.samples, float32(sample[0]), float32(sample[1]))
+			}
+		}
+
+		return speaker.encoder.Write(speaker.samples)
+	})
+}
+
+// Close finishes writing the file. It does not close the underlying writer.
+func (speaker *FileSpeaker) Close() error {
+	if speaker == nil || speaker.encoder == nil {
+		return ErrClosed
+	}
+
+	encoder := speaker.encoder
+	speaker.encoder = nil
+
+	return encoder.Close()
+}
+
+// Preview plays the source on the speaker for the specified duration, which is converted into a
+// number of samples at the context's sample rate. It blocks until all of the audio has been
+// played, or until the source ends.
+func Preview(ctx context.Context, src source.Source, speaker Speaker, d time.Duration) error {
+	if ctx == nil || speaker == nil {
+		return ErrClosed
+	}
+
+	streamer := NewStreamer(ctx, src)
+	numSamples := streamer.Format().SampleRate.N(d)
+
+	_, err := speaker.Play(streamer, numSamples)
+
+	return err
+}
+
+// pull streams numSamples samples from the streamer in chunks of bufferSize samples and passes each
+// chunk to fn. It stops early if the streamer is drained or fn returns an error.
+func pull(streamer beep.Streamer, numSamples int, bufferSize int, fn func([][2]float64) error) (int, error) {
+	if streamer == nil {
+		return 0, nil
+	}
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	buf := make([][2]float64, bufferSize)
+
+	var played int
+	for played < numSamples {
+		n, ok := streamer.Stream(buf[:min(bufferSize, numSamples-played)])
+		if n > 0 {
+			if err := fn(buf[:n]); err != nil {
+				return played, err
+			}
+			played += n
+		}
+		if !ok {
+			return played, streamer.Err()
+		}
+	}
+
+	return played, nil
+}
+
+// A take streams up to a number of samples from a streamer and counts how many it has streamed.
+type take struct {
+	streamer  beep.Streamer
+	remaining int
+	played    int
+	err       error
+}
+
+// Stream streams samples from the underlying streamer until the take has streamed all of its
+// samples.
+func (t *take) Stream(samples [][2]float64) (int, bool) {
+	if t.remaining <= 0 {
+		return 0, false
+	}
+
+	n, ok := t.streamer.Stream(samples[:min(len(samples), t.remaining)])
+	t.remaining -= n
+	t.played += n
+	if !ok {
+		t.err = t.streamer.Err()
+		t.remaining = 0
+	}
+
+	return n, ok || n > 0
+}
+
+// Err returns the error of the underlying streamer, if it stopped because of one.
+func (t *take) Err() error {
+	return t.err
+}
+
+// deviceStream returns a streamer that plays up to numSamples samples from the streamer on a device
+// with the sample rate and then calls done. If the streamer has a format with a different sample
+// rate, its audio is resampled to the device's. The take counts the samples that were played, at
+// the streamer's sample rate.
+func deviceStream(streamer beep.Streamer, numSamples int, sampleRate beep.SampleRate, done func()) (*take, beep.Streamer) {
+	t := &take{
+		streamer:  streamer,
+		remaining: numSamples,
+	}
+
+	var s beep.Streamer = t
+	if f, ok := streamer.(interface{ Format() beep.Format }); ok {
+		if from := f.Format().SampleRate; from > 0 && sampleRate > 0 && from != sampleRate {
+			s = beep.Resample(resampleQuality, from, sampleRate, s)
+		}
+	}
+
+	return t, beep.Seq(s, beep.Callback(done))
+}
@@ -0,0 +1,241 @@
+package playback
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/source"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/green-aloe/enobox/wav"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NullSpeaker tests that NullSpeaker pulls the requested number of samples from a streamer.
+func Test_NullSpeaker(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var speaker *NullSpeaker
+		_, err := speaker.Play(beep.Silence(10), 10)
+		require.ErrorIs(t, err, ErrClosed)
+		require.Zero(t, speaker.Played())
+		require.ErrorIs(t, speaker.Close(), ErrClosed)
+	})
+
+	t.Run("nil streamer", func(t *testing.T) {
+		var speaker NullSpeaker
+		n, err := speaker.Play(nil, 10)
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("infinite streamer", func(t *testing.T) {
+		for _, bufferSize := range []int{0, 1, 100, 10_000} {
+			speaker := NullSpeaker{BufferSize: bufferSize}
+
+			n, err := speaker.Play(beep.Silence(-1), 1_234)
+			require.NoError(t, err)
+			require.Equal(t, 1_234, n)
+
+			n, err = speaker.Play(beep.Silence(-1), 766)
+			require.NoError(t, err)
+			require.Equal(t, 766, n)
+			require.Equal(t, 2_000, speaker.Played())
+		}
+	})
+
+	t.Run("finite streamer", func(t *testing.T) {
+		var speaker NullSpeaker
+		n, err := speaker.Play(beep.Silence(300), 1_000)
+		require.NoError(t, err)
+		require.Equal(t, 300, n)
+	})
+
+	t.Run("streamer error", func(t *testing.T) {
+		var speaker NullSpeaker
+		failure := errors.New("failure")
+		n, err := speaker.Play(errorStreamer{failure}, 1_000)
+		require.ErrorIs(t, err, failure)
+		require.Zero(t, n)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		var speaker NullSpeaker
+		require.NoError(t, speaker.Close())
+		require.ErrorIs(t, speaker.Close(), ErrClosed)
+
+		_, err := speaker.Play(beep.Silence(-1), 10)
+		require.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+// Test_FileSpeaker tests that FileSpeaker writes the audio played on it to a WAV file.
+func Test_FileSpeaker(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var speaker *FileSpeaker
+		_, err := speaker.Play(beep.Silence(10), 10)
+		require.ErrorIs(t, err, ErrClosed)
+		require.ErrorIs(t, speaker.Close(), ErrClosed)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(t.TempDir(), "invalid.wav"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		for _, format := range []beep.Format{
+			{SampleRate: 44_100, NumChannels: 0, Precision: 2},
+			{SampleRate: 44_100, NumChannels: 3, Precision: 2},
+			{SampleRate: 0, NumChannels: 2, Precision: 2},
+		} {
+			speaker, err := NewFileSpeaker(f, format, wav.PCM16)
+			require.ErrorIs(t, err, wav.ErrInvalidFormat)
+			require.Nil(t, speaker)
+		}
+	})
+
+	for _, numChannels := range []int{1, 2} {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 22_050,
+		})
+		tn := tone.NewTriangleTone(ctx, 330)
+		tn.Gain = 0.5
+
+		path := filepath.Join(t.TempDir(), "speaker.wav")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+
+		streamer := NewStreamer(ctx, source.Tone(tn))
+		format := streamer.Format()
+		format.NumChannels = numChannels
+
+		speaker, err := NewFileSpeaker(f, format, wav.Float32)
+		require.NoError(t, err)
+		speaker.BufferSize = 100
+
+		n, err := speaker.Play(streamer, 1_050)
+		require.NoError(t, err)
+		require.Equal(t, 1_050, n)
+		require.NoError(t, speaker.Close())
+		require.ErrorIs(t, speaker.Close(), ErrClosed)
+		require.NoError(t, f.Close())
+
+		f, err = os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		haveFormat, samples, err := wav.Decode(f)
+		require.NoError(t, err)
+		require.Equal(t, wav.Format{SampleRate: 22_050, NumChannels: numChannels, Encoding: wav.Float32}, haveFormat)
+		require.Len(t, samples, 1_050*numChannels)
+
+		var renderer render.Renderer
+		want := make([]float32, 1_050)
+		renderer.Render(context.NewContextWith(context.ContextOptions{SampleRate: 22_050}), tn, want)
+		for i := range want {
+			for c := range numChannels {
+				require.InDelta(t, want[i], samples[i*numChannels+c], 1e-6)
+			}
+		}
+	}
+}
+
+// Test_Preview tests that Preview plays a source on a speaker for the correct duration.
+func Test_Preview(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		require.ErrorIs(t, Preview(nil, source.Tone(tone.Tone{}), &NullSpeaker{}, time.Second), ErrClosed)
+		require.ErrorIs(t, Preview(context.NewContext(), source.Tone(tone.Tone{}), nil, time.Second), ErrClosed)
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+	tn := tone.NewToneAt(ctx, 440)
+
+	var speaker NullSpeaker
+	require.NoError(t, Preview(ctx, source.Tone(tn), &speaker, 1500*time.Millisecond))
+	require.Equal(t, 12_000, speaker.Played())
+	require.Equal(t, context.NewTimeAt(1, 4_001, 8_000), ctx.Time())
+
+	t.Run("source ends", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 8_000,
+		})
+
+		var speaker NullSpeaker
+		require.NoError(t, Preview(ctx, source.Take(source.Tone(tn), 250*time.Millisecond), &speaker, time.Second))
+		require.Equal(t, 2_000, speaker.Played())
+		require.Equal(t, context.NewTimeAt(0, 2_001, 8_000), ctx.Time())
+	})
+}
+
+// Test_deviceStream tests that deviceStream plays the requested number of samples at a device's
+// sample rate and then finishes.
+func Test_deviceStream(t *testing.T) {
+	play := func(streamer beep.Streamer, numSamples int, sampleRate beep.SampleRate) (*take, int, bool) {
+		var done bool
+		take, stream := deviceStream(streamer, numSamples, sampleRate, func() { done = true })
+
+		// Stream it the way a device would, one buffer at a time, until it's drained.
+		var streamed int
+		buf := make([][2]float64, 100)
+		for {
+			n, ok := stream.Stream(buf)
+			streamed += n
+			if !ok {
+				break
+			}
+		}
+
+		return take, streamed, done
+	}
+
+	t.Run("same rate", func(t *testing.T) {
+		take, streamed, done := play(beep.Silence(-1), 1_234, 8_000)
+		require.True(t, done)
+		require.Equal(t, 1_234, take.played)
+		require.Equal(t, 1_234, streamed)
+		require.NoError(t, take.Err())
+	})
+
+	t.Run("streamer ends", func(t *testing.T) {
+		take, streamed, done := play(beep.Silence(500), 1_234, 8_000)
+		require.True(t, done)
+		require.Equal(t, 500, take.played)
+		require.Equal(t, 500, streamed)
+	})
+
+	t.Run("streamer error", func(t *testing.T) {
+		errTest := errors.New("test")
+		take, streamed, done := play(errorStreamer{errTest}, 1_234, 8_000)
+		require.True(t, done)
+		require.Zero(t, take.played)
+		require.Zero(t, streamed)
+		require.ErrorIs(t, take.Err(), errTest)
+	})
+
+	t.Run("resampled", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 8_000,
+		})
+		streamer := NewStreamer(ctx, source.Silence())
+
+		// A second of audio at 8kHz is a second of audio at 16kHz.
+		take, streamed, done := play(streamer, 8_000, 16_000)
+		require.True(t, done)
+		require.Equal(t, 8_000, take.played)
+		require.InDelta(t, 16_000, streamed, 10)
+	})
+}
+
+// errorStreamer is a streamer that is immediately drained with an error.
+type errorStreamer struct {
+	err error
+}
+
+func (streamer errorStreamer) Stream([][2]float64) (int, bool) { return 0, false }
+func (streamer errorStreamer) Err() error                      { return streamer.err }
@@ -0,0 +1,65 @@
+package playback
+
+import (
+	"github.com/faiface/beep"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+)
+
+// A Streamer adapts a source into a beep.Streamer so that it can be played with any of beep's
+// speakers, effects, and compositors. Mono audio from the source is copied to both the left and
+// right channels. A Streamer is not safe for concurrent use by multiple goroutines.
+type Streamer struct {
+	ctx    context.Context
+	source source.Source
+	mono   []float32
+}
+
+// NewStreamer returns a streamer that streams audio from the source. The source's audio is
+// generated at the context's sample rate, and the context's time advances as the audio is streamed.
+func NewStreamer(ctx context.Context, src source.Source) *Streamer {
+	return &Streamer{
+		ctx:    ctx,
+		source: src,
+	}
+}
+
+// Format returns the beep format of the audio that the streamer produces.
+func (streamer *Streamer) Format() beep.Format {
+	var sampleRate int
+	if streamer != nil && streamer.ctx != nil {
+		sampleRate = streamer.ctx.SampleRate()
+	}
+
+	return beep.Format{
+		SampleRate:  beep.SampleRate(sampleRate),
+		NumChannels: 2,
+		Precision:   4,
+	}
+}
+
+// Stream fills samples with the next samples from the source. It implements beep.Streamer. The
+// streamer is drained once the source ends, or immediately if it does not have a context or a
+// source.
+func (streamer *Streamer) Stream(samples [][2]float64) (int, bool) {
+	if streamer == nil || streamer.ctx == nil || streamer.source == nil {
+		return 0, false
+	}
+
+	if cap(streamer.mono) < len(samples) {
+		streamer.mono = make([]float32, len(samples))
+	}
+	mono := streamer.mono[:len(samples)]
+
+	n := streamer.source.Fill(streamer.ctx, mono)
+	for i, sample := range mono[:n] {
+		samples[i] = [2]float64{float64(sample), float64(sample)}
+	}
+
+	return n, n > 0 || len(samples) == 0
+}
+
+// Err always returns nil. It implements beep.Streamer.
+func (streamer *Streamer) Err() error {
+	return nil
+}
@@ -0,0 +1,85 @@
+package playback
+
+import (
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Streamer tests that Streamer streams audio from its source into both stereo channels.
+func Test_Streamer(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var streamer *Streamer
+		n, ok := streamer.Stream(make([][2]float64, 10))
+		require.Zero(t, n)
+		require.False(t, ok)
+		require.NoError(t, streamer.Err())
+		require.Equal(t, beep.Format{SampleRate: 0, NumChannels: 2, Precision: 4}, streamer.Format())
+	})
+
+	t.Run("no source", func(t *testing.T) {
+		streamer := NewStreamer(context.NewContext(), nil)
+		n, ok := streamer.Stream(make([][2]float64, 10))
+		require.Zero(t, n)
+		require.False(t, ok)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 48_000,
+		})
+		streamer := NewStreamer(ctx, source.Tone(tone.Tone{}))
+		require.Equal(t, beep.Format{SampleRate: 48_000, NumChannels: 2, Precision: 4}, streamer.Format())
+	})
+
+	t.Run("samples", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		var next float32
+		src := source.Func(func(ctx context.Context, samples []float32) int {
+			for i := range samples {
+				samples[i] = next
+				next += 0.25
+			}
+			ctx.SetTime(ctx.Time().ShiftBy(len(samples)))
+			return len(samples)
+		})
+
+		streamer := NewStreamer(ctx, src)
+		for _, size := range []int{1, 3, 0, 4} {
+			samples := make([][2]float64, size)
+			n, ok := streamer.Stream(samples)
+			require.Equal(t, size, n)
+			require.True(t, ok)
+			for _, sample := range samples {
+				require.Equal(t, sample[0], sample[1])
+			}
+		}
+		require.Equal(t, float32(2), next)
+		require.Equal(t, context.NewTime().ShiftBy(8), ctx.Time())
+	})
+
+	t.Run("source ends", func(t *testing.T) {
+		ctx := context.NewContext()
+		streamer := NewStreamer(ctx, source.Samples([]float32{0.1, 0.2, 0.3}))
+
+		samples := make([][2]float64, 2)
+		n, ok := streamer.Stream(samples)
+		require.Equal(t, 2, n)
+		require.True(t, ok)
+
+		n, ok = streamer.Stream(samples)
+		require.Equal(t, 1, n)
+		require.True(t, ok)
+		require.Equal(t, [2]float64{float64(float32(0.3)), float64(float32(0.3))}, samples[0])
+
+		n, ok = streamer.Stream(samples)
+		require.Zero(t, n)
+		require.False(t, ok)
+		require.Equal(t, context.NewTime().ShiftBy(3), ctx.Time())
+	})
+}
@@ -0,0 +1,322 @@
+package render
+
+import (
+	"math"
+	"slices"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+)
+
+// A Renderer turns tones into audio samples using additive synthesis. Each sample is the sum of a
+// sine wave at the tone's fundamental frequency and a sine wave at the frequency of every one of
+// its harmonics, weighted by the harmonic gains, shifted by the harmonic phases, and scaled by the
+// tone's gain.
+//
+// Partials that are at or above the context's Nyquist frequency cannot be represented at the
+// context's sample rate and would alias back down to lower frequencies, so they are always left
+// out. The remaining harmonics can optionally be tapered with a roll-off window.
+//
+// A Renderer keeps track of the phase of every partial between calls, so consecutive calls produce
+// one continuous signal without any clicks at the boundaries. Because of this, a single Renderer
+// should be used for a single stream of audio. The zero value is ready to use. A Renderer is not
+// safe for concurrent use by multiple goroutines.
+type Renderer struct {
+	// Window is the roll-off curve used to taper the tone's harmonics as they approach the Nyquist
+	// frequency. The default is tone.NoWindow, which does not taper any harmonics.
+	Window tone.Window
+
+	// Current phase of each partial, in cycles (0 to 1). The fundamental frequency is at index 0,
+	// the first harmonic is at index 1, and so on.
+	phases []float64
+
+	// Frequencies and band-limiting weights of each partial in the last tone rendered, and the
+	// partial ratios they were calculated with. Calculating every partial's frequency for every
+	// sample adds up, so we only do it when something changes.
+	freqs   []float64
+	weights []float32
+	ratios  []float32
+	cache   cacheKey
+
+	// Multipliers that turn the fundamental frequency into each partial's frequency in the last
+	// tone advanced, and the partial ratios they were calculated with. Tones often change from one
+	// sample to the next by only their fundamental frequency, and then advancing only needs to
+	// multiply it out again.
+	mults      []float64
+	multRatios []float32
+	multCache  multKey
+
+	// Renderers for every voice after the first one, which is this renderer.
+	voices []*Renderer
+}
+
+// cacheKey holds all of the values that the cached partial frequencies and weights depend on.
+// The tone's partial ratios are compared separately because a slice can't be part of the key.
+type cacheKey struct {
+	frequency     float32
+	inharmonicity float32
+	numPartials   int
+	sampleRate    int
+	window        tone.Window
+}
+
+// multKey holds all of the values that the cached partial multipliers depend on. The tone's
+// partial ratios are compared separately because a slice can't be part of the key.
+type multKey struct {
+	negative      bool
+	inharmonicity float32
+	numPartials   int
+}
+
+// Render fills samples with consecutive samples of the tone, starting at the context's current
+// time. The context's time is advanced by the number of samples rendered.
+func (r *Renderer) Render(ctx context.Context, t tone.Tone, samples []float32) {
+	if r == nil || ctx == nil {
+		return
+	}
+
+	for i := range samples {
+		samples[i] = r.next(ctx, &t)
+	}
+
+	context.Advance(ctx, len(samples))
+}
+
+// RenderTones fills samples with consecutive samples of a changing tone, starting at the context's
+// current time. Each sample is rendered from the tone at the same index, so the tone can change from
+// one sample to the next without losing its phase. If there are more samples than tones or more
+// tones than samples, only the shorter length is rendered. The context's time is advanced by the
+// number of samples rendered.
+func (r *Renderer) RenderTones(ctx context.Context, tones []tone.Tone, samples []float32) {
+	if r == nil || ctx == nil {
+		return
+	}
+
+	n := min(len(tones), len(samples))
+	for i := range n {
+		samples[i] = r.next(ctx, &tones[i])
+	}
+
+	context.Advance(ctx, n)
+}
+
+// Sample renders a single sample of the tone at the context's current time and advances the
+// context's time by one sample.
+func (r *Renderer) Sample(ctx context.Context, t tone.Tone) float32 {
+	if r == nil || ctx == nil {
+		return 0
+	}
+
+	sample := r.next(ctx, &t)
+	context.Advance(ctx, 1)
+
+	return sample
+}
+
+// Advance moves the phase of every partial forward as if the tones had been rendered, one tone per
+// sample, but without calculating any samples. The context's time is advanced by the number of
+// tones. This is much cheaper than rendering and is useful for skipping ahead in a stream of audio
+// or for working out where the phases will be at some point in the future.
+func (r *Renderer) Advance(ctx context.Context, tones []tone.Tone) {
+	if r == nil || ctx == nil {
+		return
+	}
+
+	// The phases are stepped the same way that next steps them, so that advancing through tones
+	// leaves the phases exactly where rendering them would.
+	sampleRate := float64(ctx.SampleRate())
+	if sampleRate > 0 {
+		for i := range tones {
+			t := &tones[i]
+			mults := r.multipliers(t)
+			r.grow(len(mults))
+
+			fundFreq := math.Abs(float64(t.Frequency))
+			for j, mult := range mults {
+				freq := fundFreq * mult
+				if math.IsNaN(freq) || math.IsInf(freq, 0) {
+					continue
+				}
+				phase := r.phases[j] + freq/sampleRate
+				r.phases[j] = phase - math.Floor(phase)
+			}
+		}
+	}
+
+	context.Advance(ctx, len(tones))
+}
+
+// Phases returns a copy of the current phase of every partial that the renderer has rendered, in
+// cycles (0 to 1). The fundamental frequency is first, followed by each harmonic in order.
+func (r *Renderer) Phases() []float64 {
+	if r == nil || len(r.phases) == 0 {
+		return nil
+	}
+
+	phases := make([]float64, len(r.phases))
+	copy(phases, r.phases)
+
+	return phases
+}
+
+// SetPhases sets the current phase of every partial, in cycles. This makes it possible to pick up
+// a stream of audio where another renderer left off. Partials without a phase in the list start at
+// 0.
+func (r *Renderer) SetPhases(phases []float64) {
+	if r == nil {
+		return
+	}
+
+	r.grow(len(phases))
+	for i := range r.phases {
+		var phase float64
+		if i < len(phases) {
+			phase = phases[i] - math.Floor(phases[i])
+		}
+		r.phases[i] = phase
+	}
+}
+
+// Voice returns the renderer for one voice in a polyphonic stream of audio. Each voice needs its
+// own renderer to keep track of its own phases. Voice 0 is r itself, and the renderers for the
+// other voices are created as needed with the same window as r. If the voice is negative, this
+// returns nil.
+func (r *Renderer) Voice(voice int) *Renderer {
+	if r == nil || voice < 0 {
+		return nil
+	}
+	if voice == 0 {
+		return r
+	}
+
+	for len(r.voices) < voice {
+		r.voices = append(r.voices, &Renderer{})
+	}
+
+	v := r.voices[voice-1]
+	v.Window = r.Window
+
+	return v
+}
+
+// Reset sets the phase of every partial in every voice back to zero, as if the renderer had never
+// been used.
+func (r *Renderer) Reset() {
+	if r == nil {
+		return
+	}
+
+	for i := range r.phases {
+		r.phases[i] = 0
+	}
+	for _, v := range r.voices {
+		v.Reset()
+	}
+}
+
+// next calculates the value of the tone for the current phase of each partial and then advances
+// the phases by one sample.
+func (r *Renderer) next(ctx context.Context, t *tone.Tone) float32 {
+	sampleRate := float64(ctx.SampleRate())
+	if sampleRate <= 0 {
+		return 0
+	}
+
+	freqs, weights := r.partials(ctx, t)
+	r.grow(len(freqs))
+
+	var sum float64
+	for i, freq := range freqs {
+		gain := float64(weights[i])
+		var offset float64
+		if i > 0 {
+			gain *= float64(t.HarmonicGains[i-1])
+			offset = float64(t.HarmonicPhase(i - 1))
+		}
+
+		phase := r.phases[i]
+		if gain != 0 {
+			sum += gain * math.Sin(2*math.Pi*phase+offset)
+		}
+
+		phase += freq / sampleRate
+		r.phases[i] = phase - math.Floor(phase)
+	}
+
+	return float32(float64(t.Gain) * sum)
+}
+
+// grow makes sure that the renderer is tracking the phase of at least n partials.
+func (r *Renderer) grow(n int) {
+	if len(r.phases) < n {
+		r.phases = append(r.phases, make([]float64, n-len(r.phases))...)
+	}
+}
+
+// partials returns the frequency of every partial in the tone, starting with the fundamental, and
+// the weight that each partial should be multiplied by to band-limit the tone.
+func (r *Renderer) partials(ctx context.Context, t *tone.Tone) ([]float64, []float32) {
+	key := cacheKey{
+		frequency:     t.Frequency,
+		inharmonicity: t.Inharmonicity,
+		numPartials:   len(t.HarmonicGains) + 1,
+		sampleRate:    ctx.SampleRate(),
+		window:        r.Window,
+	}
+	if r.freqs != nil && r.cache == key && slices.Equal(r.ratios, t.PartialRatios) {
+		return r.freqs, r.weights
+	}
+
+	if cap(r.freqs) < key.numPartials {
+		r.freqs = make([]float64, key.numPartials)
+		r.weights = make([]float32, key.numPartials)
+	}
+	r.freqs = r.freqs[:key.numPartials]
+	r.weights = r.weights[:key.numPartials]
+
+	nyquist := ctx.NyqistFrequency()
+	for i := range r.freqs {
+		// Partials without a valid frequency are dropped, and their phases are left where they are.
+		freq := t.PartialFreq(i + 1)
+		if math.IsNaN(freq) || math.IsInf(freq, 0) {
+			r.freqs[i] = 0
+			r.weights[i] = 0
+			continue
+		}
+		r.freqs[i] = freq
+
+		// The fundamental frequency is only ever dropped, never tapered, because the harmonic gains
+		// are relative to it.
+		if i == 0 {
+			r.weights[i] = tone.NoWindow.Weight(float32(freq), nyquist)
+		} else {
+			r.weights[i] = r.Window.Weight(float32(freq), nyquist)
+		}
+	}
+	r.cache = key
+	r.ratios = append(r.ratios[:0], t.PartialRatios...)
+
+	return r.freqs, r.weights
+}
+
+// multipliers returns the number that the size of the tone's fundamental frequency is multiplied by
+// to get the frequency of every partial in the tone, starting with the fundamental.
+func (r *Renderer) multipliers(t *tone.Tone) []float64 {
+	key := multKey{
+		negative:      t.Frequency < 0,
+		inharmonicity: t.Inharmonicity,
+		numPartials:   len(t.HarmonicGains) + 1,
+	}
+	if r.mults != nil && r.multCache == key && slices.Equal(r.multRatios, t.PartialRatios) {
+		return r.mults
+	}
+
+	r.mults = slices.Grow(r.mults[:0], key.numPartials)[:key.numPartials]
+	for i := range r.mults {
+		r.mults[i] = t.PartialRatio(i + 1)
+	}
+	r.multCache = key
+	r.multRatios = append(r.multRatios[:0], t.PartialRatios...)
+
+	return r.mults
+}
@@ -0,0 +1,38 @@
+package render_test
+
+import (
+	"fmt"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+)
+
+func ExampleRenderer_Render() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8,
+	})
+
+	tone := tone.NewToneAt(ctx, 1)
+	tone.Gain = 1
+
+	var renderer render.Renderer
+	samples := make([]float32, 8)
+	renderer.Render(ctx, tone, samples)
+
+	for _, sample := range samples {
+		fmt.Printf("%.3f\n", sample)
+	}
+	fmt.Println(ctx.Time())
+
+	// Output:
+	// 0.000
+	// 0.707
+	// 1.000
+	// 0.707
+	// 0.000
+	// -0.707
+	// -1.000
+	// -0.707
+	// 1 second, sample 1/8
+}
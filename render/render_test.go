@@ -0,0 +1,579 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Renderer_Render tests that Renderer's Render method produces the sum of the tone's partials.
+func Test_Renderer_Render(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		samples := make([]float32, 10)
+		require.NotPanics(t, func() { r.Render(context.NewContext(), tone.Tone{}, samples) })
+		require.Equal(t, make([]float32, 10), samples)
+	})
+
+	t.Run("no sample rate", func(t *testing.T) {
+		var r Renderer
+		samples := []float32{1, 2, 3}
+		r.Render(context.NewTestContext(), tone.Tone{Frequency: 440, Gain: 1}, samples)
+		require.Equal(t, []float32{0, 0, 0}, samples)
+	})
+
+	t.Run("silent tone", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		samples := make([]float32, 100)
+		r.Render(ctx, tone.NewToneAt(ctx, 440), samples)
+		require.Equal(t, make([]float32, 100), samples)
+	})
+
+	t.Run("sine wave", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		sampleRate := float64(ctx.SampleRate())
+
+		tn := tone.NewToneAt(ctx, 440)
+		tn.Gain = 0.5
+
+		samples := make([]float32, 1_000)
+		r.Render(ctx, tn, samples)
+		for i, sample := range samples {
+			want := 0.5 * math.Sin(2*math.Pi*440*float64(i)/sampleRate)
+			require.InDelta(t, want, sample, 1e-4)
+		}
+	})
+
+	t.Run("harmonics", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		sampleRate := float64(ctx.SampleRate())
+
+		tn := tone.NewToneAt(ctx, 100)
+		tn.Gain = 1
+		tn.HarmonicGains[0] = 0.5
+		tn.HarmonicGains[3] = 0.25
+
+		samples := make([]float32, 1_000)
+		r.Render(ctx, tn, samples)
+		for i, sample := range samples {
+			x := 2 * math.Pi * 100 * float64(i) / sampleRate
+			want := math.Sin(x) + 0.5*math.Sin(2*x) + 0.25*math.Sin(5*x)
+			require.InDelta(t, want, sample, 1e-4)
+		}
+	})
+
+	t.Run("harmonic phases", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		sampleRate := float64(ctx.SampleRate())
+
+		tn := tone.NewToneAt(ctx, 100)
+		tn.Gain = 1
+		tn.HarmonicGains[0] = 0.5
+		tn.HarmonicGains[1] = 0.25
+		tn.SetHarmonicPhase(0, math.Pi/2)
+		tn.SetHarmonicPhase(1, math.Pi)
+
+		samples := make([]float32, 1_000)
+		r.Render(ctx, tn, samples)
+		for i, sample := range samples {
+			x := 2 * math.Pi * 100 * float64(i) / sampleRate
+			want := math.Sin(x) + 0.5*math.Cos(2*x) - 0.25*math.Sin(3*x)
+			require.InDelta(t, want, sample, 1e-4)
+		}
+	})
+
+	t.Run("waveform signs", func(t *testing.T) {
+		ctx := context.NewContext()
+		sampleRate := float64(ctx.SampleRate())
+
+		// Compare each waveform to its Fourier series, where the sign of each term comes from the
+		// harmonic's phase.
+		for _, subtest := range []struct {
+			tone tone.Tone
+			term func(n int) float64
+			name string
+		}{
+			{tone.NewSquareTone(ctx, 100), func(n int) float64 {
+				if n%2 == 0 {
+					return 0
+				}
+				return 1 / float64(n)
+			}, "square"},
+			{tone.NewTriangleTone(ctx, 100), func(n int) float64 {
+				switch n % 4 {
+				case 1:
+					return 1 / float64(n*n)
+				case 3:
+					return -1 / float64(n*n)
+				}
+				return 0
+			}, "triangle"},
+			{tone.NewSawtoothTone(ctx, 100), func(n int) float64 {
+				if n%2 == 0 {
+					return -1 / float64(n)
+				}
+				return 1 / float64(n)
+			}, "sawtooth"},
+		} {
+			t.Run(subtest.name, func(t *testing.T) {
+				var r Renderer
+				tn := subtest.tone
+				tn.Gain = 1
+
+				samples := make([]float32, 1_000)
+				r.Render(ctx, tn, samples)
+				for i, sample := range samples {
+					x := 2 * math.Pi * 100 * float64(i) / sampleRate
+					var want float64
+					for n := 1; n <= len(tn.HarmonicGains)+1; n++ {
+						want += subtest.term(n) * math.Sin(float64(n)*x)
+					}
+					require.InDelta(t, want, sample, 1e-4)
+				}
+			})
+		}
+	})
+
+	t.Run("inharmonic partials", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		sampleRate := ctx.SampleRate()
+
+		tn := tone.NewToneAt(ctx, 100)
+		tn.Gain = 1
+		tn.HarmonicGains[0] = 0.5
+		tn.HarmonicGains[1] = 0.25
+		tn.PartialRatios = []float32{2.76}
+		tn.Inharmonicity = 0.01
+
+		samples := make([]float32, sampleRate)
+		r.Render(ctx, tn, samples)
+
+		require.InDelta(t, 1, amplitude(samples, 100, sampleRate), 1e-3)
+		require.InDelta(t, 0.5, amplitude(samples, 276, sampleRate), 1e-3)
+		freq, err := tn.HarmonicFreq(3)
+		require.NoError(t, err)
+		require.InDelta(t, 0.25, amplitude(samples, float64(freq), sampleRate), 5e-3)
+		require.InDelta(t, 0, amplitude(samples, 200, sampleRate), 5e-3)
+
+		// Changing the ratios in place moves the partial.
+		tn.PartialRatios[0] = 3.5
+		r.Render(ctx, tn, samples)
+		require.InDelta(t, 0.5, amplitude(samples, 350, sampleRate), 5e-3)
+	})
+
+	t.Run("invalid partial ratios", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		sampleRate := ctx.SampleRate()
+
+		tn := tone.NewToneAt(ctx, 100)
+		tn.Gain = 1
+		tn.HarmonicGains[0] = 0.5
+		tn.HarmonicGains[1] = 0.25
+		tn.PartialRatios = []float32{float32(math.NaN()), float32(math.Inf(1))}
+
+		// The partials without a valid frequency are dropped, and the rest are still rendered.
+		samples := make([]float32, sampleRate)
+		r.Render(ctx, tn, samples)
+		for _, sample := range samples {
+			require.False(t, math.IsNaN(float64(sample)))
+		}
+		require.InDelta(t, 1, amplitude(samples, 100, sampleRate), 1e-3)
+		for _, phase := range r.Phases() {
+			require.False(t, math.IsNaN(phase))
+		}
+	})
+
+	t.Run("square wave", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		sampleRate := ctx.SampleRate()
+
+		tn := tone.NewSquareTone(ctx, 441)
+		tn.Gain = 1
+
+		// Render exactly one second so that every partial completes a whole number of cycles, and
+		// then measure the amplitude of each partial.
+		samples := make([]float32, sampleRate)
+		r.Render(ctx, tn, samples)
+
+		require.InDelta(t, 1, amplitude(samples, 441, sampleRate), 1e-3)
+		for i, gain := range tn.HarmonicGains {
+			order := i + 2
+			require.InDelta(t, gain, amplitude(samples, 441*float64(order), sampleRate), 1e-3)
+		}
+	})
+
+	t.Run("phase continuity", func(t *testing.T) {
+		ctx := context.NewContext()
+		tn := tone.NewSawtoothTone(ctx, 261.6256)
+		tn.Gain = 1
+
+		var r1 Renderer
+		whole := make([]float32, 1_000)
+		r1.Render(ctx, tn, whole)
+
+		var r2 Renderer
+		parts := make([]float32, 1_000)
+		for _, bounds := range [][2]int{{0, 1}, {1, 100}, {100, 357}, {357, 358}, {358, 1_000}} {
+			r2.Render(ctx, tn, parts[bounds[0]:bounds[1]])
+		}
+
+		require.Equal(t, whole, parts)
+	})
+
+	t.Run("time", func(t *testing.T) {
+		var r Renderer
+		ctx := context.NewContext()
+		start := ctx.Time()
+
+		r.Render(ctx, tone.NewToneAt(ctx, 440), make([]float32, 100))
+		require.Equal(t, start.ShiftBy(100), ctx.Time())
+
+		r.Render(ctx, tone.NewToneAt(ctx, 440), make([]float32, ctx.SampleRate()))
+		require.Equal(t, start.ShiftBy(100+ctx.SampleRate()), ctx.Time())
+	})
+}
+
+// Test_Renderer_Render_bandLimit tests that Renderer's Render method leaves out partials that are
+// at or above the Nyquist frequency and tapers the rest with the renderer's window.
+func Test_Renderer_Render_bandLimit(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 10_000,
+	})
+	sampleRate := ctx.SampleRate()
+
+	t.Run("harmonics above nyquist", func(t *testing.T) {
+		var r Renderer
+
+		tn := tone.NewSawtoothTone(ctx, 2_000)
+		tn.Gain = 1
+
+		samples := make([]float32, sampleRate)
+		r.Render(ctx, tn, samples)
+
+		// Only the fundamental (2kHz) and the first harmonic (4kHz) are below 5kHz.
+		for i, sample := range samples {
+			x := 2 * math.Pi * 2_000 * float64(i) / float64(sampleRate)
+			want := math.Sin(x) - float64(tn.HarmonicGains[0])*math.Sin(2*x)
+			require.InDelta(t, want, sample, 1e-4)
+		}
+	})
+
+	t.Run("fundamental above nyquist", func(t *testing.T) {
+		var r Renderer
+
+		tn := tone.NewSquareTone(ctx, 6_000)
+		tn.Gain = 1
+
+		samples := make([]float32, 100)
+		r.Render(ctx, tn, samples)
+		require.Equal(t, make([]float32, 100), samples)
+	})
+
+	for _, window := range []tone.Window{tone.LanczosWindow, tone.RaisedCosineWindow} {
+		r := Renderer{Window: window}
+
+		tn := tone.NewSawtoothTone(ctx, 500)
+		tn.Gain = 1
+
+		samples := make([]float32, sampleRate)
+		r.Render(ctx, tn, samples)
+
+		// The fundamental is never tapered.
+		require.InDelta(t, 1, amplitude(samples, 500, sampleRate), 1e-3)
+		for i, gain := range tn.HarmonicGains {
+			freq, err := tn.HarmonicFreq(i + 2)
+			require.NoError(t, err)
+			if freq >= ctx.NyqistFrequency() {
+				// Frequencies above the nyquist frequency can't be measured at this sample rate.
+				break
+			}
+			want := gain * window.Weight(freq, ctx.NyqistFrequency())
+			require.InDelta(t, want, amplitude(samples, float64(freq), sampleRate), 1e-3)
+		}
+	}
+}
+
+// Test_Renderer_Sample tests that Renderer's Sample method renders the same values as Render, one
+// at a time.
+func Test_Renderer_Sample(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		require.Zero(t, r.Sample(context.NewContext(), tone.Tone{Frequency: 440, Gain: 1}))
+	})
+
+	ctx := context.NewContext()
+	tn := tone.NewTriangleTone(ctx, 440)
+	tn.Gain = 0.8
+
+	var r1 Renderer
+	want := make([]float32, 500)
+	r1.Render(ctx, tn, want)
+
+	var r2 Renderer
+	start := ctx.Time()
+	for i := range want {
+		require.Equal(t, want[i], r2.Sample(ctx, tn))
+	}
+	require.Equal(t, start.ShiftBy(len(want)), ctx.Time())
+}
+
+// Test_Renderer_RenderTones tests that Renderer's RenderTones method renders one tone per sample
+// without losing any phase when the tone changes.
+func Test_Renderer_RenderTones(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		require.NotPanics(t, func() { r.RenderTones(context.NewContext(), make([]tone.Tone, 10), make([]float32, 10)) })
+	})
+
+	ctx := context.NewContext()
+
+	t.Run("same tone", func(t *testing.T) {
+		tn := tone.NewSquareTone(ctx, 440)
+		tn.Gain = 0.5
+
+		var r1 Renderer
+		want := make([]float32, 300)
+		r1.Render(ctx, tn, want)
+
+		tones := make([]tone.Tone, len(want))
+		for i := range tones {
+			tones[i] = tn
+		}
+
+		var r2 Renderer
+		have := make([]float32, len(want))
+		start := ctx.Time()
+		r2.RenderTones(ctx, tones, have)
+		require.Equal(t, want, have)
+		require.Equal(t, start.ShiftBy(len(want)), ctx.Time())
+	})
+
+	t.Run("changing tones", func(t *testing.T) {
+		tones := make([]tone.Tone, 200)
+		for i := range tones {
+			tones[i] = tone.NewSawtoothTone(ctx, 100+float32(i))
+			tones[i].Gain = float32(i) / 200
+		}
+
+		var r1 Renderer
+		want := make([]float32, len(tones))
+		for i := range tones {
+			want[i] = r1.Sample(ctx, tones[i])
+		}
+
+		var r2 Renderer
+		have := make([]float32, len(tones))
+		r2.RenderTones(ctx, tones, have)
+		require.Equal(t, want, have)
+	})
+
+	t.Run("mismatched lengths", func(t *testing.T) {
+		tones := make([]tone.Tone, 10)
+		for i := range tones {
+			tones[i] = tone.NewToneAt(ctx, 440)
+			tones[i].Gain = 1
+		}
+
+		var r Renderer
+		start := ctx.Time()
+		samples := make([]float32, 20)
+		for i := range samples {
+			samples[i] = 5
+		}
+		r.RenderTones(ctx, tones, samples)
+		require.Equal(t, start.ShiftBy(10), ctx.Time())
+		for _, sample := range samples[10:] {
+			require.Equal(t, float32(5), sample)
+		}
+
+		r.RenderTones(ctx, tones, samples[:4])
+		require.Equal(t, start.ShiftBy(14), ctx.Time())
+	})
+}
+
+// Test_Renderer_Advance tests that Renderer's Advance method moves the phases forward the same way
+// that rendering does, without writing any samples.
+func Test_Renderer_Advance(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		require.NotPanics(t, func() { r.Advance(context.NewContext(), make([]tone.Tone, 10)) })
+	})
+
+	ctx := context.NewContext()
+	tones := make([]tone.Tone, 250)
+	for i := range tones {
+		tones[i] = tone.NewSawtoothTone(ctx, 200+float32(i))
+		tones[i].Gain = 0.5
+	}
+
+	var r1 Renderer
+	r1.RenderTones(ctx, tones, make([]float32, len(tones)))
+
+	var r2 Renderer
+	start := ctx.Time()
+	r2.Advance(ctx, tones)
+	require.Equal(t, start.ShiftBy(len(tones)), ctx.Time())
+	require.Equal(t, r1.Phases(), r2.Phases())
+
+	// Both renderers carry on from the same place.
+	want := make([]float32, 100)
+	r1.RenderTones(ctx, tones, want)
+	have := make([]float32, 100)
+	r2.RenderTones(ctx, tones, have)
+	require.Equal(t, want, have)
+
+	t.Run("inharmonic", func(t *testing.T) {
+		tones := make([]tone.Tone, 250)
+		for i := range tones {
+			tones[i] = tone.NewSawtoothTone(ctx, 200-float32(i))
+			switch i % 3 {
+			case 1:
+				tones[i].Inharmonicity = 0.001
+			case 2:
+				tones[i].PartialRatios = []float32{2.76, float32(math.NaN()), 8.93}
+			}
+		}
+
+		var r1 Renderer
+		r1.RenderTones(ctx, tones, make([]float32, len(tones)))
+
+		var r2 Renderer
+		r2.Advance(ctx, tones)
+		require.Equal(t, r1.Phases(), r2.Phases())
+	})
+}
+
+// Test_Renderer_SetPhases tests that Renderer's SetPhases method lets one renderer pick up where
+// another one left off.
+func Test_Renderer_SetPhases(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		require.Nil(t, r.Phases())
+		require.NotPanics(t, func() { r.SetPhases([]float64{0.5}) })
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var r Renderer
+		require.Nil(t, r.Phases())
+	})
+
+	t.Run("wrapping", func(t *testing.T) {
+		var r Renderer
+		r.SetPhases([]float64{0.25, 1.5, -0.25})
+		require.Equal(t, []float64{0.25, 0.5, 0.75}, r.Phases())
+
+		// Missing phases go back to 0.
+		r.SetPhases([]float64{0.125})
+		require.Equal(t, []float64{0.125, 0, 0}, r.Phases())
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		var r Renderer
+		r.SetPhases([]float64{0.25})
+		phases := r.Phases()
+		phases[0] = 0.75
+		require.Equal(t, []float64{0.25}, r.Phases())
+	})
+
+	t.Run("handoff", func(t *testing.T) {
+		ctx := context.NewContext()
+		tn := tone.NewTriangleTone(ctx, 330)
+		tn.Gain = 1
+
+		var r1 Renderer
+		want := make([]float32, 500)
+		r1.Render(ctx, tn, want)
+
+		var r2 Renderer
+		have := make([]float32, 500)
+		r2.Render(ctx, tn, have[:200])
+
+		var r3 Renderer
+		r3.SetPhases(r2.Phases())
+		r3.Render(ctx, tn, have[200:])
+		require.Equal(t, want, have)
+	})
+}
+
+// Test_Renderer_Voice tests that Renderer's Voice method gives every voice its own renderer.
+func Test_Renderer_Voice(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		require.Nil(t, r.Voice(0))
+		require.Nil(t, r.Voice(1))
+	})
+
+	var r Renderer
+	r.Window = tone.LanczosWindow
+	require.Nil(t, r.Voice(-1))
+	require.Same(t, &r, r.Voice(0))
+
+	v3 := r.Voice(3)
+	require.NotNil(t, v3)
+	require.Same(t, v3, r.Voice(3))
+	require.Equal(t, tone.LanczosWindow, v3.Window)
+	require.Len(t, r.voices, 3)
+
+	v1 := r.Voice(1)
+	require.NotSame(t, v1, v3)
+	require.NotSame(t, &r, v1)
+
+	// Each voice keeps its own phases.
+	ctx := context.NewContext()
+	v1.Render(ctx, tone.NewToneAt(ctx, 440), make([]float32, 10))
+	require.NotEmpty(t, v1.Phases())
+	require.Empty(t, r.Phases())
+	require.Empty(t, v3.Phases())
+
+	// Resetting the renderer resets every voice.
+	r.Reset()
+	for _, phase := range v1.Phases() {
+		require.Zero(t, phase)
+	}
+}
+
+// Test_Renderer_Reset tests that Renderer's Reset method returns every partial to its initial phase.
+func Test_Renderer_Reset(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Renderer
+		require.NotPanics(t, func() { r.Reset() })
+	})
+
+	ctx := context.NewContext()
+	tn := tone.NewSquareTone(ctx, 123.4)
+	tn.Gain = 1
+
+	var r Renderer
+	first := make([]float32, 321)
+	r.Render(ctx, tn, first)
+
+	second := make([]float32, 321)
+	r.Render(ctx, tn, second)
+	require.NotEqual(t, first, second)
+
+	r.Reset()
+	r.Render(ctx, tn, second)
+	require.Equal(t, first, second)
+}
+
+// amplitude measures the amplitude of the sine component at the given frequency.
+func amplitude(samples []float32, freq float64, sampleRate int) float64 {
+	var re, im float64
+	for i, sample := range samples {
+		x := 2 * math.Pi * freq * float64(i) / float64(sampleRate)
+		re += float64(sample) * math.Cos(x)
+		im += float64(sample) * math.Sin(x)
+	}
+
+	return 2 * math.Hypot(re, im) / float64(len(samples))
+}
@@ -0,0 +1,128 @@
+package source
+
+import (
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// Sequence returns a source that plays each of the sources in order, one after another. Each source
+// plays until it ends, so every source except the last one should be finite.
+func Sequence(sources ...Source) Source {
+	return &sequence{sources: sources}
+}
+
+type sequence struct {
+	sources []Source
+	current int
+}
+
+// Fill fills samples from the current source and moves on to the next source whenever one ends.
+func (seq *sequence) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil {
+		return 0
+	}
+
+	var filled int
+	for filled < len(samples) && seq.current < len(seq.sources) {
+		if source := seq.sources[seq.current]; source != nil {
+			filled += source.Fill(ctx, samples[filled:])
+		}
+		if filled < len(samples) {
+			seq.current++
+		}
+	}
+
+	return filled
+}
+
+// Reset resets every source and starts over from the first one.
+func (seq *sequence) Reset() {
+	for _, source := range seq.sources {
+		if source != nil {
+			reset(source)
+		}
+	}
+	seq.current = 0
+}
+
+// Loop returns a source that plays the source over and over again forever. The source is reset
+// every time it ends, so it must be a Resetter to play more than once. Loop ends if the source isn't
+// a Resetter or doesn't have any samples to play after being reset.
+func Loop(source Source) Source {
+	return &loop{source: source}
+}
+
+type loop struct {
+	source Source
+
+	// Whether the source was reset and hasn't filled any samples since
+	restarted bool
+}
+
+// Fill fills samples from the source, resetting it whenever it ends.
+func (l *loop) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil || l.source == nil {
+		return 0
+	}
+
+	var filled int
+	for filled < len(samples) {
+		n := l.source.Fill(ctx, samples[filled:])
+		filled += n
+		if n > 0 {
+			l.restarted = false
+		}
+		if filled == len(samples) {
+			break
+		}
+
+		// If the source is empty even after starting over, it's never going to play anything.
+		if l.restarted || !reset(l.source) {
+			break
+		}
+		l.restarted = true
+	}
+
+	return filled
+}
+
+// Reset resets the source.
+func (l *loop) Reset() {
+	reset(l.source)
+	l.restarted = false
+}
+
+// Take returns a source that plays the source for the duration, at the context's sample rate, and
+// then ends. It ends sooner if the source does.
+func Take(source Source, d time.Duration) Source {
+	return &take{
+		source:   source,
+		duration: d,
+	}
+}
+
+type take struct {
+	source   Source
+	duration time.Duration
+	taken    int
+}
+
+// Fill fills samples from the source until the duration is up.
+func (t *take) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil || t.source == nil {
+		return 0
+	}
+
+	remaining := max(numSamples(ctx, t.duration)-t.taken, 0)
+	n := t.source.Fill(ctx, samples[:min(len(samples), remaining)])
+	t.taken += n
+
+	return n
+}
+
+// Reset resets the source and starts the duration over.
+func (t *take) Reset() {
+	reset(t.source)
+	t.taken = 0
+}
@@ -0,0 +1,122 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Sequence tests that Sequence plays its sources one after another.
+func Test_Sequence(t *testing.T) {
+	ctx := context.NewContext()
+	source := Sequence(
+		Samples([]float32{1, 2}),
+		nil,
+		Samples(nil),
+		Samples([]float32{3, 4, 5}),
+		Samples([]float32{6}),
+	)
+
+	require.Zero(t, source.Fill(nil, make([]float32, 10)))
+
+	have := make([]float32, 4)
+	require.Equal(t, 4, source.Fill(ctx, have))
+	require.Equal(t, []float32{1, 2, 3, 4}, have)
+	require.Zero(t, source.Fill(ctx, have[:0]))
+	require.Equal(t, 2, source.Fill(ctx, have))
+	require.Equal(t, []float32{5, 6}, have[:2])
+	require.Zero(t, source.Fill(ctx, have))
+	require.Equal(t, context.NewTime().ShiftBy(6), ctx.Time())
+
+	source.(Resetter).Reset()
+	require.Equal(t, 4, source.Fill(ctx, have))
+	require.Equal(t, []float32{1, 2, 3, 4}, have)
+
+	require.Zero(t, Sequence().Fill(ctx, have))
+}
+
+// Test_Loop tests that Loop plays its source over and over again.
+func Test_Loop(t *testing.T) {
+	t.Run("samples", func(t *testing.T) {
+		ctx := context.NewContext()
+		source := Loop(Samples([]float32{1, 2, 3}))
+
+		require.Zero(t, source.Fill(nil, make([]float32, 10)))
+
+		have := make([]float32, 8)
+		require.Equal(t, 8, source.Fill(ctx, have))
+		require.Equal(t, []float32{1, 2, 3, 1, 2, 3, 1, 2}, have)
+		require.Equal(t, 2, source.Fill(ctx, have[:2]))
+		require.Equal(t, []float32{3, 1}, have[:2])
+		require.Equal(t, context.NewTime().ShiftBy(10), ctx.Time())
+
+		source.(Resetter).Reset()
+		require.Equal(t, 4, source.Fill(ctx, have[:4]))
+		require.Equal(t, []float32{1, 2, 3, 1}, have[:4])
+	})
+
+	t.Run("take", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 1_000,
+		})
+		source := Loop(Sequence(Take(Silence(), 2*time.Millisecond), Samples([]float32{1})))
+
+		have := make([]float32, 7)
+		require.Equal(t, 7, source.Fill(ctx, have))
+		require.Equal(t, []float32{0, 0, 1, 0, 0, 1, 0}, have)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ctx := context.NewContext()
+		require.Zero(t, Loop(Samples(nil)).Fill(ctx, make([]float32, 10)))
+		require.Zero(t, Loop(nil).Fill(ctx, make([]float32, 10)))
+	})
+
+	t.Run("not a resetter", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		var calls int
+		source := Loop(Func(func(ctx context.Context, samples []float32) int {
+			calls++
+			return copy(samples, []float32{1, 2})
+		}))
+
+		have := make([]float32, 5)
+		require.Equal(t, 2, source.Fill(ctx, have))
+		require.Equal(t, 1, calls)
+	})
+}
+
+// Test_Take tests that Take plays its source for a limited duration.
+func Test_Take(t *testing.T) {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+	source := Take(Loop(Samples([]float32{1, 2, 3})), 5*time.Millisecond)
+
+	require.Zero(t, source.Fill(nil, make([]float32, 10)))
+
+	have := make([]float32, 4)
+	require.Equal(t, 4, source.Fill(ctx, have))
+	require.Equal(t, []float32{1, 2, 3, 1}, have)
+	require.Equal(t, 1, source.Fill(ctx, have))
+	require.Equal(t, float32(2), have[0])
+	require.Zero(t, source.Fill(ctx, have))
+	require.Equal(t, context.NewTimeAt(0, 6, 1_000), ctx.Time())
+
+	source.(Resetter).Reset()
+	require.Equal(t, 4, source.Fill(ctx, have))
+	require.Equal(t, []float32{1, 2, 3, 1}, have)
+
+	t.Run("source ends first", func(t *testing.T) {
+		source := Take(Samples([]float32{1, 2}), time.Second)
+		require.Equal(t, 2, source.Fill(ctx, have))
+		require.Zero(t, source.Fill(ctx, have))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.Zero(t, Take(nil, time.Second).Fill(ctx, have))
+	})
+}
@@ -0,0 +1,51 @@
+package source
+
+import (
+	"math"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Source produces a stream of mono audio over time. Tones, envelopes, effects, and files can all
+// be sources, and sources can be combined into new sources with Sequence, Loop, and Take.
+type Source interface {
+	// Fill fills samples with the next samples of audio, starting at the context's current time,
+	// and advances the context's time by the number of samples filled, which it returns. A source
+	// that fills fewer samples than it was given has ended and fills no samples from then on.
+	Fill(ctx context.Context, samples []float32) int
+}
+
+// A Resetter is a source that can start over from the beginning.
+type Resetter interface {
+	// Reset moves the source back to its beginning.
+	Reset()
+}
+
+// Func is an adapter that allows an ordinary function to be used as a Source.
+type Func func(ctx context.Context, samples []float32) int
+
+// Fill calls f(ctx, samples).
+func (f Func) Fill(ctx context.Context, samples []float32) int {
+	return f(ctx, samples)
+}
+
+// reset resets the source if it's a Resetter and reports if it was.
+func reset(source Source) bool {
+	resetter, ok := source.(Resetter)
+	if ok {
+		resetter.Reset()
+	}
+
+	return ok
+}
+
+// numSamples returns the number of samples in the duration at the context's sample rate.
+func numSamples(ctx context.Context, d time.Duration) int {
+	sampleRate := ctx.SampleRate()
+	if sampleRate <= 0 || d <= 0 {
+		return 0
+	}
+
+	return int(math.Round(d.Seconds() * float64(sampleRate)))
+}
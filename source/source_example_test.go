@@ -0,0 +1,34 @@
+package source_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/source"
+)
+
+func ExampleSequence() {
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 1_000,
+	})
+
+	// Play a short click every 3 milliseconds for 10 milliseconds.
+	click := source.Sequence(
+		source.Samples([]float32{1}),
+		source.Take(source.Silence(), 2*time.Millisecond),
+	)
+	src := source.Take(source.Loop(click), 10*time.Millisecond)
+
+	samples := make([]float32, 16)
+	n := src.Fill(ctx, samples)
+
+	fmt.Println(n)
+	fmt.Println(samples[:n])
+	fmt.Println(ctx.Time())
+
+	// Output:
+	// 10
+	// [1 0 0 1 0 0 1 0 0 1]
+	// 0 seconds, sample 11/1000
+}
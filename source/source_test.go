@@ -0,0 +1,54 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Func tests that Func's Fill method calls the underlying function.
+func Test_Func(t *testing.T) {
+	ctx := context.NewContext()
+
+	var called bool
+	source := Func(func(have context.Context, samples []float32) int {
+		require.Equal(t, ctx, have)
+		require.Len(t, samples, 3)
+		called = true
+		return 2
+	})
+
+	require.Equal(t, 2, source.Fill(ctx, make([]float32, 3)))
+	require.True(t, called)
+}
+
+// Test_numSamples tests that numSamples converts a duration into a number of samples at the
+// context's sample rate.
+func Test_numSamples(t *testing.T) {
+	type subtest struct {
+		sampleRate int
+		d          time.Duration
+		want       int
+	}
+
+	subtests := []subtest{
+		{1_000, 0, 0},
+		{1_000, -time.Second, 0},
+		{1_000, time.Second, 1_000},
+		{44_100, 500 * time.Millisecond, 22_050},
+		{1_000, 1_400 * time.Microsecond, 1},
+		{1_000, 1_500 * time.Microsecond, 2},
+	}
+
+	for _, subtest := range subtests {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: subtest.sampleRate,
+		})
+		require.Equal(t, subtest.want, numSamples(ctx, subtest.d), "%d Hz, %v", subtest.sampleRate, subtest.d)
+	}
+
+	// Contexts without a sample rate don't have any samples.
+	require.Zero(t, numSamples(context.NewTestContext(), time.Second))
+}
@@ -0,0 +1,132 @@
+package source
+
+import (
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/envelope"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+)
+
+// Silence returns a source that plays silence forever.
+func Silence() Source {
+	return silence{}
+}
+
+type silence struct{}
+
+// Fill fills samples with zeros.
+func (silence) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil {
+		return 0
+	}
+
+	clear(samples)
+	context.Advance(ctx, len(samples))
+
+	return len(samples)
+}
+
+// Reset does nothing, since silence is the same from beginning to end.
+func (silence) Reset() {}
+
+// Tone returns a source that plays the tone forever.
+func Tone(t tone.Tone) Source {
+	return &toneSource{tone: t}
+}
+
+type toneSource struct {
+	tone     tone.Tone
+	renderer render.Renderer
+}
+
+// Fill renders the next samples of the tone.
+func (source *toneSource) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil {
+		return 0
+	}
+
+	source.renderer.Render(ctx, source.tone, samples)
+
+	return len(samples)
+}
+
+// Reset starts the tone over from a phase of zero.
+func (source *toneSource) Reset() {
+	source.renderer.Reset()
+}
+
+// Samples returns a source that plays the samples once. This is useful for audio that was
+// generated ahead of time or decoded from a file. The samples are not copied.
+func Samples(samples []float32) Source {
+	return &samplesSource{samples: samples}
+}
+
+type samplesSource struct {
+	samples []float32
+	pos     int
+}
+
+// Fill copies the next samples.
+func (source *samplesSource) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil {
+		return 0
+	}
+
+	n := copy(samples, source.samples[source.pos:])
+	source.pos += n
+	context.Advance(ctx, n)
+
+	return n
+}
+
+// Reset moves back to the first sample.
+func (source *samplesSource) Reset() {
+	source.pos = 0
+}
+
+// Envelope returns a source that scales the audio from the source by the envelope's level at each
+// sample. The envelope is not triggered or released automatically. The source ends when the
+// original source ends or when the envelope is done. If the envelope is nil, the audio from the
+// source passes through unchanged.
+func Envelope(source Source, env *envelope.Envelope) Source {
+	return &envelopeSource{
+		source: source,
+		env:    env,
+	}
+}
+
+type envelopeSource struct {
+	source Source
+	env    *envelope.Envelope
+	levels []float32
+}
+
+// Fill fills samples from the original source and scales them by the envelope.
+func (source *envelopeSource) Fill(ctx context.Context, samples []float32) int {
+	if ctx == nil || source.source == nil || source.env.Done(ctx) {
+		return 0
+	}
+
+	if source.env == nil {
+		return source.source.Fill(ctx, samples)
+	}
+
+	// The envelope's levels have to be read before the source advances the context's time.
+	if cap(source.levels) < len(samples) {
+		source.levels = make([]float32, len(samples))
+	}
+	levels := source.levels[:len(samples)]
+	source.env.Levels(ctx, levels)
+
+	n := source.source.Fill(ctx, samples)
+	for i := range n {
+		samples[i] *= levels[i]
+	}
+
+	return n
+}
+
+// Reset resets the original source. The envelope has to be triggered again separately.
+func (source *envelopeSource) Reset() {
+	reset(source.source)
+}
@@ -0,0 +1,128 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/envelope"
+	"github.com/green-aloe/enobox/render"
+	"github.com/green-aloe/enobox/tone"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Silence tests that Silence plays silence forever.
+func Test_Silence(t *testing.T) {
+	ctx := context.NewContext()
+	source := Silence()
+
+	require.Zero(t, source.Fill(nil, make([]float32, 10)))
+
+	samples := []float32{1, 2, 3, 4}
+	require.Equal(t, 4, source.Fill(ctx, samples))
+	require.Equal(t, []float32{0, 0, 0, 0}, samples)
+	require.Equal(t, 10_000, source.Fill(ctx, make([]float32, 10_000)))
+	require.Equal(t, context.NewTime().ShiftBy(10_004), ctx.Time())
+}
+
+// Test_Tone tests that Tone plays a tone continuously and starts it over when it's reset.
+func Test_Tone(t *testing.T) {
+	ctx := context.NewContext()
+	tn := tone.NewSquareTone(ctx, 220)
+	tn.Gain = 0.5
+
+	var renderer render.Renderer
+	want := make([]float32, 1_000)
+	renderer.Render(context.NewContext(), tn, want)
+
+	source := Tone(tn)
+	require.Zero(t, source.Fill(nil, make([]float32, 10)))
+
+	have := make([]float32, 1_000)
+	require.Equal(t, 400, source.Fill(ctx, have[:400]))
+	require.Equal(t, 600, source.Fill(ctx, have[400:]))
+	require.Equal(t, want, have)
+	require.Equal(t, context.NewTime().ShiftBy(1_000), ctx.Time())
+
+	source.(Resetter).Reset()
+	require.Equal(t, 100, source.Fill(context.NewContext(), have[:100]))
+	require.Equal(t, want[:100], have[:100])
+}
+
+// Test_Samples tests that Samples plays the samples once and can be reset.
+func Test_Samples(t *testing.T) {
+	ctx := context.NewContext()
+	source := Samples([]float32{1, 2, 3, 4, 5})
+
+	require.Zero(t, source.Fill(nil, make([]float32, 10)))
+
+	have := make([]float32, 3)
+	require.Equal(t, 3, source.Fill(ctx, have))
+	require.Equal(t, []float32{1, 2, 3}, have)
+	require.Equal(t, 2, source.Fill(ctx, have))
+	require.Equal(t, []float32{4, 5, 3}, have)
+	require.Zero(t, source.Fill(ctx, have))
+	require.Equal(t, context.NewTime().ShiftBy(5), ctx.Time())
+
+	source.(Resetter).Reset()
+	require.Equal(t, 3, source.Fill(ctx, have))
+	require.Equal(t, []float32{1, 2, 3}, have)
+
+	require.Zero(t, Samples(nil).Fill(ctx, have))
+}
+
+// Test_Envelope tests that Envelope scales a source by an envelope's levels.
+func Test_Envelope(t *testing.T) {
+	ones := func(n int) []float32 {
+		samples := make([]float32, n)
+		for i := range samples {
+			samples[i] = 1
+		}
+		return samples
+	}
+
+	t.Run("levels", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 1_000,
+		})
+		env := envelope.NewBreakpoints(0, envelope.Segment{Duration: 4 * time.Millisecond, Level: 1, Shape: envelope.Linear})
+		env.Trigger(ctx)
+
+		source := Envelope(Samples(ones(7)), env)
+		have := make([]float32, 5)
+		require.Equal(t, 3, source.Fill(ctx, have[:3]))
+		require.Equal(t, 2, source.Fill(ctx, have[3:]))
+		require.Equal(t, []float32{0, 0.25, 0.5, 0.75, 1}, have)
+
+		require.Equal(t, 2, source.Fill(ctx, have))
+		require.Equal(t, []float32{1, 1}, have[:2])
+		require.Zero(t, source.Fill(ctx, have))
+		require.Equal(t, context.NewTimeAt(0, 8, 1_000), ctx.Time())
+	})
+
+	t.Run("done", func(t *testing.T) {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: 1_000,
+		})
+		env := envelope.NewADSR(0, 0, 1, 0)
+		env.Trigger(ctx)
+
+		source := Envelope(Silence(), env)
+		require.Equal(t, 10, source.Fill(ctx, make([]float32, 10)))
+
+		env.Release(ctx)
+		require.Zero(t, source.Fill(ctx, make([]float32, 10)))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		ctx := context.NewContext()
+		require.Zero(t, Envelope(nil, envelope.NewADSR(0, 0, 1, 0)).Fill(ctx, make([]float32, 10)))
+		require.Zero(t, Envelope(Silence(), nil).Fill(nil, make([]float32, 10)))
+
+		// Without an envelope, the audio passes through unchanged.
+		samples := make([]float32, 3)
+		require.Equal(t, 3, Envelope(Samples([]float32{1, -0.5, 0.25}), nil).Fill(ctx, samples))
+		require.Equal(t, []float32{1, -0.5, 0.25}, samples)
+		require.Equal(t, context.NewTimeAt(0, 4, context.DefaultSampleRate), ctx.Time())
+	})
+}
@@ -0,0 +1,73 @@
+package tone
+
+import (
+	"math"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Window is a roll-off curve that tapers the harmonics of a band-limited tone as their
+// frequencies approach the Nyquist frequency. Abruptly cutting off a waveform's harmonics makes it
+// ring around its discontinuities (the Gibbs phenomenon), and gently fading out the upper harmonics
+// instead suppresses most of that ringing.
+type Window int
+
+const (
+	// NoWindow keeps every harmonic below the Nyquist frequency at its full gain.
+	NoWindow Window = iota
+
+	// LanczosWindow tapers harmonics with Lanczos sigma factors, which is a sinc curve that falls
+	// from 1 at 0Hz to 0 at the Nyquist frequency.
+	LanczosWindow
+
+	// RaisedCosineWindow tapers harmonics with half of a cosine cycle that falls from 1 at 0Hz to 0
+	// at the Nyquist frequency. It rolls off more gradually than LanczosWindow does.
+	RaisedCosineWindow
+)
+
+// Weight returns the multiplier that the window applies to a partial at the specified frequency.
+// Partials at or above the Nyquist frequency always have a weight of 0.
+func (window Window) Weight(frequency float32, nyquist float32) float32 {
+	if frequency < 0 {
+		frequency = -frequency
+	}
+	if nyquist <= 0 || frequency >= nyquist {
+		return 0
+	}
+
+	x := float64(frequency) / float64(nyquist)
+
+	switch window {
+	case LanczosWindow:
+		if x == 0 {
+			return 1
+		}
+		return float32(math.Sin(math.Pi*x) / (math.Pi * x))
+
+	case RaisedCosineWindow:
+		return float32(0.5 * (1 + math.Cos(math.Pi*x)))
+
+	default:
+		return 1
+	}
+}
+
+// BandLimit removes every harmonic that the context's sample rate cannot represent by setting its
+// gain to 0, and tapers the gains of the remaining harmonics with the window. A harmonic can be
+// represented if its frequency is below the context's Nyquist frequency. Harmonics without a valid
+// frequency are removed too. The fundamental frequency is not changed.
+func (tone *Tone) BandLimit(ctx context.Context, window Window) {
+	if tone == nil || ctx == nil {
+		return
+	}
+
+	nyquist := ctx.NyqistFrequency()
+	for i := range tone.HarmonicGains {
+		freq, err := tone.HarmonicFreq(i + 2)
+		if err != nil {
+			tone.HarmonicGains[i] = 0
+			continue
+		}
+		tone.HarmonicGains[i] *= window.Weight(freq, nyquist)
+	}
+}
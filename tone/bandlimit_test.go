@@ -0,0 +1,109 @@
+package tone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Window_Weight tests that Window's Weight method returns the correct roll-off multipliers.
+func Test_Window_Weight(t *testing.T) {
+	type subtest struct {
+		window    Window
+		frequency float32
+		nyquist   float32
+		want      float32
+		name      string
+	}
+
+	subtests := []subtest{
+		{NoWindow, 0, 22050, 1, "no window, zero frequency"},
+		{NoWindow, 440, 22050, 1, "no window, low frequency"},
+		{NoWindow, 22049, 22050, 1, "no window, below nyquist"},
+		{NoWindow, 22050, 22050, 0, "no window, at nyquist"},
+		{NoWindow, 30000, 22050, 0, "no window, above nyquist"},
+		{NoWindow, -440, 22050, 1, "no window, negative frequency"},
+		{NoWindow, -30000, 22050, 0, "no window, negative frequency above nyquist"},
+		{NoWindow, 440, 0, 0, "no window, no nyquist"},
+		{LanczosWindow, 0, 22050, 1, "lanczos, zero frequency"},
+		{LanczosWindow, 11025, 22050, float32(2 / math.Pi), "lanczos, half nyquist"},
+		{LanczosWindow, 22050, 22050, 0, "lanczos, at nyquist"},
+		{LanczosWindow, 30000, 22050, 0, "lanczos, above nyquist"},
+		{RaisedCosineWindow, 0, 22050, 1, "raised cosine, zero frequency"},
+		{RaisedCosineWindow, 11025, 22050, 0.5, "raised cosine, half nyquist"},
+		{RaisedCosineWindow, 22050, 22050, 0, "raised cosine, at nyquist"},
+		{RaisedCosineWindow, 30000, 22050, 0, "raised cosine, above nyquist"},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			have := subtest.window.Weight(subtest.frequency, subtest.nyquist)
+			require.InDelta(t, subtest.want, have, 1e-6)
+		})
+	}
+
+	t.Run("monotonic", func(t *testing.T) {
+		for _, window := range []Window{LanczosWindow, RaisedCosineWindow} {
+			prev := float32(1)
+			for freq := float32(0); freq <= 22050; freq += 50 {
+				weight := window.Weight(freq, 22050)
+				require.LessOrEqual(t, weight, prev)
+				require.GreaterOrEqual(t, weight, float32(0))
+				prev = weight
+			}
+		}
+	})
+}
+
+// Test_Tone_BandLimit tests that Tone's BandLimit method removes and tapers the correct harmonics.
+func Test_Tone_BandLimit(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var tone *Tone
+		require.NotPanics(t, func() { tone.BandLimit(context.NewContext(), NoWindow) })
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		tone := NewSawtoothTone(context.NewContext(), 4_000)
+		require.NotPanics(t, func() { tone.BandLimit(nil, NoWindow) })
+		require.Equal(t, NewSawtoothTone(context.NewContext(), 4_000), tone)
+	})
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 44_100,
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		tone := NewSawtoothTone(ctx, 4_000)
+		tone.BandLimit(ctx, NoWindow)
+
+		// Harmonics up to order 5 (20kHz) fit under the nyquist frequency of 22.05kHz.
+		want := NewSawtoothTone(ctx, 4_000)
+		for i := range want.HarmonicGains {
+			if order := i + 2; order > 5 {
+				want.HarmonicGains[i] = 0
+			}
+		}
+		require.Equal(t, want, tone)
+	})
+
+	t.Run("all harmonics fit", func(t *testing.T) {
+		tone := NewSquareTone(ctx, 100)
+		tone.BandLimit(ctx, NoWindow)
+		require.Equal(t, NewSquareTone(ctx, 100), tone)
+	})
+
+	for _, window := range []Window{LanczosWindow, RaisedCosineWindow} {
+		tone := NewSawtoothTone(ctx, 1_000)
+		tone.BandLimit(ctx, window)
+
+		orig := NewSawtoothTone(ctx, 1_000)
+		for i, gain := range tone.HarmonicGains {
+			freq, err := orig.HarmonicFreq(i + 2)
+			require.NoError(t, err)
+			require.Equal(t, orig.HarmonicGains[i]*window.Weight(freq, 22_050), gain)
+			require.Less(t, gain, orig.HarmonicGains[i])
+		}
+	}
+}
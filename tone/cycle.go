@@ -0,0 +1,111 @@
+package tone
+
+import (
+	"math"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// NewToneFromCycle creates a new tone with the specified fundamental frequency that reproduces one
+// cycle of a waveform, like the single-cycle samples used by wavetable synthesizers. The cycle can
+// have any number of samples, and the samples should cover exactly one period of the waveform.
+//
+// This runs a discrete Fourier transform on the cycle to measure the amplitude and phase of every
+// harmonic, up to the number of harmonic gains in the context. The tone's gain is set to the
+// amplitude of the fundamental frequency, and the harmonic gains and phases are set relative to it.
+// Harmonics at or above half the number of samples in the cycle can't be measured and have no gain.
+// Any DC offset in the cycle is left out.
+//
+// A tone's fundamental frequency always starts at a phase of 0, so the tone reproduces the shape
+// of the cycle but might be shifted in time from it. A cycle without any energy at its fundamental
+// frequency can't be represented as a tone, so it produces a silent tone.
+func NewToneFromCycle(ctx context.Context, frequency float32, cycle []float32) Tone {
+	tone := NewToneAt(ctx, frequency)
+	if !measurable(cycle, 1) {
+		return tone
+	}
+
+	fundAmp, fundPhase := dft(cycle, 1)
+	if fundAmp < 1e-9 {
+		return tone
+	}
+	tone.Gain = float32(fundAmp)
+
+	for i := range tone.HarmonicGains {
+		order := i + 2
+		if !measurable(cycle, order) {
+			break
+		}
+
+		amp, phase := dft(cycle, order)
+		if amp < 1e-9 {
+			continue
+		}
+
+		// Shift the harmonic's phase to line up with a fundamental frequency that starts at 0.
+		phase -= float64(order) * fundPhase
+		phase = math.Mod(phase, 2*math.Pi)
+		if phase < 0 {
+			phase += 2 * math.Pi
+		}
+
+		tone.HarmonicGains[i] = float32(amp / fundAmp)
+		tone.SetHarmonicPhase(i, float32(phase))
+	}
+
+	return tone
+}
+
+// Cycle fills cycle with exactly one period of the tone's waveform, including its gain. This is
+// the inverse of NewToneFromCycle and can be used to export a tone as a single-cycle wavetable.
+// Harmonics at or above half the number of samples in the cycle are left out so that the table
+// doesn't alias. The tone's frequency, inharmonicity, and partial ratios are ignored, because a
+// single cycle can only hold harmonics at integer multiples of the fundamental frequency.
+func (tone *Tone) Cycle(cycle []float32) {
+	if tone == nil {
+		return
+	}
+
+	n := float64(len(cycle))
+	for i := range cycle {
+		x := 2 * math.Pi * float64(i) / n
+
+		sum := math.Sin(x)
+		for j, gain := range tone.HarmonicGains {
+			order := j + 2
+			if !measurable(cycle, order) {
+				break
+			}
+			if gain == 0 {
+				continue
+			}
+
+			phase := float64(tone.HarmonicPhase(j))
+			sum += float64(gain) * math.Sin(float64(order)*x+phase)
+		}
+
+		cycle[i] = float32(float64(tone.Gain) * sum)
+	}
+}
+
+// measurable reports if a harmonic of the specified order is below the Nyquist frequency of the
+// cycle, which is half the number of samples in it.
+func measurable(cycle []float32, order int) bool {
+	return 2*order < len(cycle)
+}
+
+// dft measures the amplitude and phase of one harmonic in a single cycle of samples. The phase is
+// relative to a sine wave that starts at the beginning of the cycle.
+func dft(cycle []float32, order int) (float64, float64) {
+	var sinSum, cosSum float64
+	n := float64(len(cycle))
+	for i, sample := range cycle {
+		x := 2 * math.Pi * float64(order) * float64(i) / n
+		sinSum += float64(sample) * math.Sin(x)
+		cosSum += float64(sample) * math.Cos(x)
+	}
+	sinSum *= 2 / n
+	cosSum *= 2 / n
+
+	return math.Hypot(sinSum, cosSum), math.Atan2(cosSum, sinSum)
+}
@@ -0,0 +1,175 @@
+package tone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewToneFromCycle tests that NewToneFromCycle measures the harmonics in a single cycle of a
+// waveform.
+func Test_NewToneFromCycle(t *testing.T) {
+	ctx := context.NewContext()
+
+	t.Run("empty cycle", func(t *testing.T) {
+		for _, cycle := range [][]float32{nil, {}, {1}, {1, -1}} {
+			tone := NewToneFromCycle(ctx, 440, cycle)
+			require.Equal(t, NewToneAt(ctx, 440), tone)
+		}
+	})
+
+	t.Run("no fundamental", func(t *testing.T) {
+		cycle := make([]float32, 64)
+		for i := range cycle {
+			cycle[i] = 0.5 + float32(math.Sin(4*math.Pi*float64(i)/64))
+		}
+
+		tone := NewToneFromCycle(ctx, 440, cycle)
+		require.Equal(t, NewToneAt(ctx, 440), tone)
+	})
+
+	t.Run("sine", func(t *testing.T) {
+		cycle := make([]float32, 100)
+		for i := range cycle {
+			cycle[i] = 0.8 * float32(math.Sin(2*math.Pi*float64(i)/100))
+		}
+
+		tone := NewToneFromCycle(ctx, 220, cycle)
+		require.Equal(t, float32(220), tone.Frequency)
+		require.InDelta(t, 0.8, tone.Gain, 1e-6)
+		for i := range tone.HarmonicGains {
+			require.InDelta(t, 0, tone.HarmonicGains[i], 1e-6)
+		}
+	})
+
+	t.Run("square", func(t *testing.T) {
+		cycle := make([]float32, 2_048)
+		for i := range cycle {
+			cycle[i] = 1
+			if i >= len(cycle)/2 {
+				cycle[i] = -1
+			}
+		}
+
+		tone := NewToneFromCycle(ctx, 220, cycle)
+		require.InDelta(t, 4/math.Pi, tone.Gain, 1e-3)
+		square := NewSquareTone(ctx, 220)
+		for i := range square.HarmonicGains {
+			require.InDelta(t, square.HarmonicGains[i], tone.HarmonicGains[i], 1e-3)
+			if square.HarmonicGains[i] != 0 {
+				require.InDelta(t, 0, math.Sin(float64(tone.HarmonicPhases[i])), 1e-2)
+				require.InDelta(t, 1, math.Cos(float64(tone.HarmonicPhases[i])), 1e-2)
+			}
+		}
+	})
+
+	t.Run("sawtooth", func(t *testing.T) {
+		// A rising ramp that starts halfway through its cycle.
+		cycle := make([]float32, 2_048)
+		for i := range cycle {
+			cycle[i] = 2*float32(i)/float32(len(cycle)) - 1
+		}
+
+		tone := NewToneFromCycle(ctx, 220, cycle)
+		require.InDelta(t, 2/math.Pi, tone.Gain, 1e-3)
+		saw := NewSawtoothTone(ctx, 220)
+		for i := range saw.HarmonicGains {
+			require.InDelta(t, saw.HarmonicGains[i], tone.HarmonicGains[i], 1e-3)
+			require.InDelta(t, math.Cos(float64(saw.HarmonicPhases[i])), math.Cos(float64(tone.HarmonicPhases[i])), 1e-2)
+		}
+	})
+
+	t.Run("shifted cycle", func(t *testing.T) {
+		saw := NewSawtoothTone(ctx, 220)
+		saw.Gain = 1
+		cycle := make([]float32, 512)
+		saw.Cycle(cycle)
+
+		// Rotating the cycle changes where it starts but not its shape.
+		shifted := append(cycle[100:], cycle[:100]...)
+
+		want := NewToneFromCycle(ctx, 220, cycle)
+		have := NewToneFromCycle(ctx, 220, shifted)
+		require.InDelta(t, want.Gain, have.Gain, 1e-6)
+		require.InDeltaSlice(t, want.HarmonicGains, have.HarmonicGains, 1e-5)
+		for i := range want.HarmonicPhases {
+			require.InDelta(t, math.Sin(float64(want.HarmonicPhases[i])), math.Sin(float64(have.HarmonicPhases[i])), 1e-4)
+			require.InDelta(t, math.Cos(float64(want.HarmonicPhases[i])), math.Cos(float64(have.HarmonicPhases[i])), 1e-4)
+		}
+	})
+
+	t.Run("short cycle", func(t *testing.T) {
+		saw := NewSawtoothTone(ctx, 220)
+		saw.Gain = 1
+		cycle := make([]float32, 8)
+		saw.Cycle(cycle)
+
+		// Only the harmonics below half the length of the cycle can be measured.
+		tone := NewToneFromCycle(ctx, 220, cycle)
+		require.InDelta(t, 1, tone.Gain, 1e-6)
+		require.InDelta(t, 0.5, tone.HarmonicGains[0], 1e-6)
+		require.InDelta(t, 1.0/3, tone.HarmonicGains[1], 1e-6)
+		for _, gain := range tone.HarmonicGains[2:] {
+			require.Zero(t, gain)
+		}
+	})
+}
+
+// Test_Tone_Cycle tests that Tone's Cycle method renders exactly one period of the tone.
+func Test_Tone_Cycle(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var tone *Tone
+		require.NotPanics(t, func() { tone.Cycle(make([]float32, 10)) })
+	})
+
+	ctx := context.NewContext()
+
+	t.Run("sine", func(t *testing.T) {
+		tone := NewToneAt(ctx, 440)
+		tone.Gain = 0.5
+
+		cycle := make([]float32, 64)
+		tone.Cycle(cycle)
+		for i, sample := range cycle {
+			require.InDelta(t, 0.5*math.Sin(2*math.Pi*float64(i)/64), sample, 1e-6)
+		}
+	})
+
+	t.Run("phases", func(t *testing.T) {
+		tone := NewToneAt(ctx, 440)
+		tone.Gain = 1
+		tone.HarmonicGains[0] = 0.5
+		tone.SetHarmonicPhase(0, math.Pi/2)
+
+		cycle := make([]float32, 64)
+		tone.Cycle(cycle)
+		for i, sample := range cycle {
+			x := 2 * math.Pi * float64(i) / 64
+			require.InDelta(t, math.Sin(x)+0.5*math.Cos(2*x), sample, 1e-6)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, want := range []Tone{
+			NewSquareTone(ctx, 110),
+			NewTriangleTone(ctx, 110),
+			NewSawtoothTone(ctx, 110),
+			NewPulseTone(ctx, 110, 0.2),
+			NewVowelTone(ctx, 110, VowelO),
+		} {
+			want.Gain = 0.7
+
+			cycle := make([]float32, 256)
+			want.Cycle(cycle)
+			have := NewToneFromCycle(ctx, want.Frequency, cycle)
+
+			again := make([]float32, 256)
+			have.Cycle(again)
+			require.InDeltaSlice(t, cycle, again, 1e-5)
+			require.InDelta(t, want.Gain, have.Gain, 1e-5)
+			require.InDeltaSlice(t, want.HarmonicGains, have.HarmonicGains, 1e-5)
+		}
+	})
+}
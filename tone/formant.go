@@ -0,0 +1,136 @@
+package tone
+
+import (
+	"math"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Formant is a resonant peak in the spectrum of a sound. The formants of a voice or an instrument
+// stay at roughly the same frequencies no matter what note is played, which is a large part of what
+// makes a vowel sound like that vowel.
+type Formant struct {
+	// Frequency is the center frequency of the peak.
+	Frequency float32
+
+	// Bandwidth is the width of the peak, in Hz, between the two points where it is 3dB below its
+	// center.
+	Bandwidth float32
+
+	// Gain is the amplitude of the peak at its center.
+	Gain float32
+}
+
+// Amplitude returns the amplitude of the formant at the specified frequency.
+func (formant Formant) Amplitude(frequency float32) float32 {
+	return float32(formant.weight(float64(frequency)))
+}
+
+// weight returns the amplitude of the formant at the specified frequency.
+func (formant Formant) weight(frequency float64) float64 {
+	if formant.Bandwidth <= 0 {
+		return 0
+	}
+
+	x := (frequency - float64(formant.Frequency)) / (float64(formant.Bandwidth) / 2)
+
+	return float64(formant.Gain) / math.Sqrt(1+x*x)
+}
+
+// A Vowel is one of the five basic vowel sounds of a sung voice.
+type Vowel int
+
+const (
+	VowelA Vowel = iota + 1
+	VowelE
+	VowelI
+	VowelO
+	VowelU
+)
+
+// Formants of a bass voice singing each vowel, with the gains in dB.
+var vowelFormants = map[Vowel][5]struct{ freq, bandwidth, dB float32 }{
+	VowelA: {{600, 60, 0}, {1040, 70, -7}, {2250, 110, -9}, {2450, 120, -9}, {2750, 130, -20}},
+	VowelE: {{400, 40, 0}, {1620, 80, -12}, {2400, 100, -9}, {2800, 120, -12}, {3100, 120, -18}},
+	VowelI: {{250, 60, 0}, {1750, 90, -30}, {2600, 100, -16}, {3050, 120, -22}, {3340, 120, -28}},
+	VowelO: {{400, 40, 0}, {750, 80, -11}, {2400, 100, -21}, {2600, 120, -20}, {2900, 120, -40}},
+	VowelU: {{350, 40, 0}, {600, 80, -20}, {2400, 100, -32}, {2675, 120, -28}, {2950, 120, -36}},
+}
+
+// Formants returns the first five formants of a bass voice singing the vowel, or nil if the vowel
+// is not valid.
+func (vowel Vowel) Formants() []Formant {
+	table, ok := vowelFormants[vowel]
+	if !ok {
+		return nil
+	}
+
+	formants := make([]Formant, len(table))
+	for i, f := range table {
+		formants[i] = Formant{
+			Frequency: f.freq,
+			Bandwidth: f.bandwidth,
+			Gain:      float32(math.Pow(10, float64(f.dB)/20)),
+		}
+	}
+
+	return formants
+}
+
+// String returns the name of the vowel.
+func (vowel Vowel) String() string {
+	switch vowel {
+	case VowelA:
+		return "a"
+	case VowelE:
+		return "e"
+	case VowelI:
+		return "i"
+	case VowelO:
+		return "o"
+	case VowelU:
+		return "u"
+	}
+
+	return ""
+}
+
+// NewFormantTone creates a new tone whose spectrum is shaped by the formants. The gains come from
+// FormantFormula.
+func NewFormantTone(ctx context.Context, frequency float32, formants ...Formant) Tone {
+	return NewToneFromFormula(ctx, frequency, FormantFormula(frequency, formants...))
+}
+
+// NewVowelTone creates a new tone that sounds like a voice singing the vowel.
+func NewVowelTone(ctx context.Context, frequency float32, vowel Vowel) Tone {
+	return NewFormantTone(ctx, frequency, vowel.Formants()...)
+}
+
+// FormantFormula returns a formula that calculates the gain of a harmonic in a tone with the
+// specified fundamental frequency whose spectrum is shaped by the formants. The spectral envelope
+// is the sum of all the formants' peaks, and each harmonic's gain is the envelope at the harmonic's
+// frequency divided by the envelope at the fundamental frequency.
+//
+// Because the gains are relative to the fundamental frequency, harmonics that land near a formant
+// can have gains well above 1 when the fundamental frequency is far from any formants. The tone's
+// gain might need to be lowered to keep the output in range.
+func FormantFormula(fundamental float32, formants ...Formant) Formula {
+	envelope := func(frequency float64) float64 {
+		var sum float64
+		for _, formant := range formants {
+			sum += formant.weight(frequency)
+		}
+		return sum
+	}
+
+	fundFreq := math.Abs(float64(fundamental))
+	ref := envelope(fundFreq)
+
+	return func(order int) float32 {
+		if order <= 0 || ref == 0 {
+			return 0
+		}
+
+		return float32(envelope(fundFreq*float64(order)) / ref)
+	}
+}
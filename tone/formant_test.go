@@ -0,0 +1,107 @@
+package tone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Vowel_Formants tests that Vowel's Formants method returns the formants for each vowel.
+func Test_Vowel_Formants(t *testing.T) {
+	for _, vowel := range []Vowel{VowelA, VowelE, VowelI, VowelO, VowelU} {
+		t.Run(vowel.String(), func(t *testing.T) {
+			formants := vowel.Formants()
+			require.Len(t, formants, 5)
+			require.Equal(t, float32(1), formants[0].Gain)
+			for i := 1; i < len(formants); i++ {
+				require.Greater(t, formants[i].Frequency, formants[i-1].Frequency)
+				require.Less(t, formants[i].Gain, float32(1))
+				require.Positive(t, formants[i].Bandwidth)
+			}
+
+			// Changing the formants doesn't change the table.
+			formants[0].Frequency = 0
+			require.NotZero(t, vowel.Formants()[0].Frequency)
+		})
+	}
+
+	for _, vowel := range []Vowel{0, -1, 6} {
+		require.Nil(t, vowel.Formants())
+		require.Empty(t, vowel.String())
+	}
+}
+
+// Test_Formant_Amplitude tests that Formant's Amplitude method returns the height of the formant's
+// peak at each frequency.
+func Test_Formant_Amplitude(t *testing.T) {
+	formant := Formant{Frequency: 500, Bandwidth: 100, Gain: 0.5}
+	require.Equal(t, float32(0.5), formant.Amplitude(500))
+	require.InDelta(t, 0.5/1.41421356, formant.Amplitude(450), 1e-6)
+	require.InDelta(t, 0.5/1.41421356, formant.Amplitude(550), 1e-6)
+	require.InDelta(t, 0.5/math.Sqrt(1+81), formant.Amplitude(950), 1e-6)
+
+	require.Zero(t, Formant{Frequency: 500, Gain: 1}.Amplitude(500))
+}
+
+// Test_FormantFormula tests that FormantFormula shapes the harmonic gains around the formants.
+func Test_FormantFormula(t *testing.T) {
+	t.Run("no formants", func(t *testing.T) {
+		formula := FormantFormula(100)
+		for order := range 10 {
+			require.Zero(t, formula(order))
+		}
+	})
+
+	t.Run("single formant", func(t *testing.T) {
+		formula := FormantFormula(100, Formant{Frequency: 500, Bandwidth: 100, Gain: 1})
+
+		// The envelope peaks at the formant's frequency and falls off evenly on either side.
+		peak := formula(5)
+		require.Greater(t, peak, formula(4))
+		require.Greater(t, peak, formula(6))
+		require.InDelta(t, formula(4), formula(6), 1e-6)
+		require.InDelta(t, formula(3), formula(7), 1e-6)
+
+		// The gain at the fundamental frequency is the reference.
+		require.InDelta(t, 1, formula(1), 1e-6)
+
+		// The peak is -3dB at half the bandwidth away from the center.
+		formula = FormantFormula(50, Formant{Frequency: 500, Bandwidth: 100, Gain: 1})
+		require.InDelta(t, 1/1.41421356, formula(11)/formula(10), 1e-6)
+	})
+
+	t.Run("negative fundamental", func(t *testing.T) {
+		formants := VowelA.Formants()
+		pos := FormantFormula(110, formants...)
+		neg := FormantFormula(-110, formants...)
+		for order := range 20 {
+			require.Equal(t, pos(order), neg(order))
+		}
+	})
+
+	t.Run("invalid bandwidth", func(t *testing.T) {
+		formula := FormantFormula(100, Formant{Frequency: 500, Gain: 1})
+		require.Zero(t, formula(5))
+	})
+}
+
+// Test_NewVowelTone tests that NewVowelTone puts the loudest harmonics near the vowel's formants.
+func Test_NewVowelTone(t *testing.T) {
+	ctx := context.NewContext()
+
+	// With a fundamental frequency of 100Hz, the first formant of "a" (600Hz) lands on the fifth
+	// harmonic (order 6) and the second formant (1040Hz) lands near the ninth harmonic (order 10).
+	tone := NewVowelTone(ctx, 100, VowelA)
+	require.Equal(t, float32(100), tone.Frequency)
+	require.Greater(t, tone.HarmonicGains[4], tone.HarmonicGains[3])
+	require.Greater(t, tone.HarmonicGains[4], tone.HarmonicGains[5])
+	require.Greater(t, tone.HarmonicGains[8], tone.HarmonicGains[7])
+	require.Greater(t, tone.HarmonicGains[8], tone.HarmonicGains[10])
+
+	require.Equal(t, NewFormantTone(ctx, 100, VowelA.Formants()...), tone)
+
+	// An invalid vowel has no spectrum.
+	require.Equal(t, NewToneAt(ctx, 100), NewVowelTone(ctx, 100, 0))
+}
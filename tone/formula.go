@@ -0,0 +1,42 @@
+package tone
+
+import (
+	"math"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// A Formula calculates the gain of one of a tone's harmonics from its order. The first harmonic has
+// an order of 2, the second harmonic has an order of 3, and so on. The gain is relative to the
+// fundamental frequency, the same as the gains in Tone's HarmonicGains.
+//
+// A formula can return a negative gain to flip the sign of a harmonic. The tone will use the
+// absolute value of the gain and give the harmonic a phase of pi.
+type Formula func(order int) float32
+
+// NewToneFromFormula creates a new tone with the specified fundamental frequency and a harmonic gain
+// from the formula for every harmonic in the context. Each gain is truncated to have no more than
+// MaxSigFigs digits.
+//
+// As an example, this formula creates a tone whose harmonics fall off by half every octave:
+//
+//	tone := NewToneFromFormula(ctx, 440, func(order int) float32 {
+//		return 1 / float32(order)
+//	})
+func NewToneFromFormula(ctx context.Context, frequency float32, formula Formula) Tone {
+	tone := NewToneAt(ctx, frequency)
+	if formula == nil {
+		return tone
+	}
+
+	for i := range tone.HarmonicGains {
+		gain := formula(i + 2)
+		if gain < 0 {
+			gain = -gain
+			tone.SetHarmonicPhase(i, math.Pi)
+		}
+		tone.HarmonicGains[i] = Trunc(gain, MaxSigFigs)
+	}
+
+	return tone
+}
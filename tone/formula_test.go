@@ -0,0 +1,76 @@
+package tone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewToneFromFormula tests that NewToneFromFormula fills in every harmonic gain from the
+// formula.
+func Test_NewToneFromFormula(t *testing.T) {
+	defer SetNumHarmGains(DefaultNumHarmGains)
+
+	t.Run("nil formula", func(t *testing.T) {
+		ctx := context.NewContext()
+		tone := NewToneFromFormula(ctx, 440, nil)
+		require.Equal(t, NewToneAt(ctx, 440), tone)
+	})
+
+	t.Run("orders", func(t *testing.T) {
+		ctx := context.NewContext()
+
+		var orders []int
+		tone := NewToneFromFormula(ctx, 440, func(order int) float32 {
+			orders = append(orders, order)
+			return float32(order)
+		})
+
+		require.Equal(t, float32(440), tone.Frequency)
+		require.Zero(t, tone.Gain)
+		require.Len(t, orders, NumHarmGains(ctx))
+		for i, order := range orders {
+			require.Equal(t, i+2, order)
+			require.Equal(t, float32(order), tone.HarmonicGains[i])
+		}
+	})
+
+	t.Run("truncation", func(t *testing.T) {
+		ctx := context.NewContext()
+		tone := NewToneFromFormula(ctx, 440, func(order int) float32 {
+			return 1 / float32(order)
+		})
+		require.Equal(t, float32(0.333333), tone.HarmonicGains[1])
+	})
+
+	t.Run("negative gains", func(t *testing.T) {
+		ctx := context.NewContext()
+		tone := NewToneFromFormula(ctx, 440, func(order int) float32 {
+			if order%2 == 0 {
+				return -0.5
+			}
+			return 0.25
+		})
+
+		for i := range tone.HarmonicGains {
+			if order := i + 2; order%2 == 0 {
+				require.Equal(t, float32(0.5), tone.HarmonicGains[i])
+				require.Equal(t, float32(math.Pi), tone.HarmonicPhases[i])
+			} else {
+				require.Equal(t, float32(0.25), tone.HarmonicGains[i])
+				require.Zero(t, tone.HarmonicPhases[i])
+			}
+		}
+	})
+
+	t.Run("more than 100 harmonics", func(t *testing.T) {
+		SetNumHarmGains(500)
+		ctx := context.NewContext()
+
+		tone := NewToneFromFormula(ctx, 10, SawtoothFormula)
+		require.Len(t, tone.HarmonicGains, 500)
+		require.Equal(t, Trunc(1.0/501, MaxSigFigs), tone.HarmonicGains[499])
+	})
+}
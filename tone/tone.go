@@ -1,6 +1,9 @@
 package tone
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"slices"
 
 	"github.com/govalues/decimal"
@@ -13,8 +16,16 @@ const (
 	MaxSigFigs = 6
 )
 
-// A Tone represents a single tone, which is a fundamental frequency and its harmonics at regular
-// intervals above it. A new tone must be created with NewTone before it can be used.
+var (
+	// ErrInvalidFrequency is returned when a harmonic's frequency cannot be calculated, such as when
+	// the tone's fundamental frequency or one of its partial ratios is NaN or infinite.
+	ErrInvalidFrequency = errors.New("tone: invalid frequency")
+)
+
+// A Tone represents a single tone, which is a fundamental frequency and its harmonics above it. By
+// default, the harmonics are at regular intervals (integer multiples of the fundamental frequency),
+// but they can be stretched or moved anywhere to make inharmonic tones like those of pianos, bells,
+// and metal bars. A new tone must be created with NewTone before it can be used.
 type Tone struct {
 	// Frequency is the tone's fundamental frequency.
 	Frequency float32
@@ -35,6 +46,31 @@ type Tone struct {
 	// As an example, a value of 2 will double the amplitude of that harmonic relative to the
 	// fundamental frequency, while a value of 0.5 will halve it.
 	HarmonicGains []float32
+
+	// HarmonicPhases is a list of phase offsets for each harmonic above the fundamental frequency,
+	// in radians. They use the same order as the harmonic gains and are relative to the phase of
+	// the fundamental frequency. A phase of 0 starts the harmonic as a sine wave, while a phase of
+	// pi flips its sign.
+	//
+	// Phases don't change what a tone sounds like very much, but they do change the shape of its
+	// waveform and how high its peaks are.
+	HarmonicPhases []float32
+
+	// Inharmonicity is the tone's inharmonicity coefficient, also known as the B factor of a piano
+	// string. It stretches each harmonic further above its integer multiple the higher the harmonic
+	// is. A value of 0 (the default) keeps the harmonics at integer multiples of the fundamental
+	// frequency. Real piano strings have values roughly between 0.00005 and 0.002.
+	Inharmonicity float32
+
+	// PartialRatios is an optional list of frequency ratios for each harmonic above the fundamental
+	// frequency. They are ratios of the frequency of the harmonic to the fundamental frequency, and
+	// they use the same order as the harmonic gains. A ratio overrides both the harmonic's integer
+	// multiple and the tone's inharmonicity. A ratio of 0, or a missing ratio, leaves the harmonic
+	// at its default frequency.
+	//
+	// As an example, the ratios {2.76, 5.40, 8.93} produce the first few partials of a free metal
+	// bar.
+	PartialRatios []float32
 }
 
 // NewTone initializes a tone with default/zero values.
@@ -55,7 +91,8 @@ func NewToneFrom(ctx context.Context, note note.Note, octave int) Tone {
 // NewToneWith initializes a tone with the specified fundamental frequency, gain, and harmonic
 // gains. The harmonic gains are set directly in the tone, as opposed to allocating a new slice and
 // copying over the values. If the number of harmonic gains provided does not match the number for
-// the context, this grows or shrinks the slice to match the expected length.
+// the context, this grows or shrinks the slice to match the expected length. Every harmonic starts
+// with a phase of 0, and the harmonic phases aren't allocated until a phase is set.
 func NewToneWith(ctx context.Context, frequency float32, gain float32, harmonicGains []float32) Tone {
 	if want, have := NumHarmGains(ctx), len(harmonicGains); want != have {
 		if want > cap(harmonicGains) {
@@ -71,14 +108,52 @@ func NewToneWith(ctx context.Context, frequency float32, gain float32, harmonicG
 	}
 }
 
+// HarmonicPhase returns the phase offset of the harmonic at the index in the tone's harmonic gains,
+// in radians. Harmonics without a phase have a phase of 0.
+func (tone *Tone) HarmonicPhase(index int) float32 {
+	if tone == nil || index < 0 || index >= len(tone.HarmonicPhases) {
+		return 0
+	}
+
+	return tone.HarmonicPhases[index]
+}
+
+// SetHarmonicPhase sets the phase offset of the harmonic at the index in the tone's harmonic gains,
+// in radians. The harmonic phases are allocated the first time that a phase is set. Invalid indexes
+// are ignored.
+func (tone *Tone) SetHarmonicPhase(index int, phase float32) {
+	if tone == nil || index < 0 || index >= len(tone.HarmonicGains) {
+		return
+	}
+
+	if index >= len(tone.HarmonicPhases) {
+		if phase == 0 {
+			return
+		}
+		tone.HarmonicPhases = slices.Grow(tone.HarmonicPhases, len(tone.HarmonicGains)-len(tone.HarmonicPhases))
+		tone.HarmonicPhases = tone.HarmonicPhases[:len(tone.HarmonicGains)]
+	}
+
+	tone.HarmonicPhases[index] = phase
+}
+
 // HarmonicFreq calculates the frequency of one of the tone's harmonic. The fundamental frequency
 // has an order of 1. The frequency is truncated to have no more than MaxSigFigs digits.
 //
 // As an example, if the tone has a fundamental frequency of 440Hz, then the first harmonic (order=2) is
 // 880Hz and the second harmonic (order=3) is 1320Hz,
-func (tone *Tone) HarmonicFreq(order int) float32 {
+//
+// If the harmonic has a partial ratio, the frequency is the fundamental frequency multiplied by
+// that ratio. Otherwise, if the tone has an inharmonicity coefficient B, the frequency of harmonic n
+// is stretched to n * f * sqrt((1 + B*n^2) / (1 + B)). This is the standard formula for a stiff
+// string, normalized so that the fundamental frequency stays where it is.
+//
+// If the frequency cannot be calculated, such as when the tone's fundamental frequency, partial
+// ratio, or inharmonicity is NaN or infinite, this returns an error that wraps
+// ErrInvalidFrequency.
+func (tone *Tone) HarmonicFreq(order int) (float32, error) {
 	if tone == nil || order <= 0 || tone.Frequency == 0 {
-		return 0
+		return 0, nil
 	}
 
 	freq := tone.Frequency
@@ -87,12 +162,66 @@ func (tone *Tone) HarmonicFreq(order int) float32 {
 		order -= 2
 	}
 
-	fundFreq, _ := decimal.NewFromFloat64(float64(freq))
-	multiplier, _ := decimal.New(int64(order), 0)
-	harmFreq, _ := fundFreq.Mul(multiplier)
+	fundFreq, err := decimal.NewFromFloat64(float64(freq))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidFrequency, err)
+	}
+	multiplier, err := decimal.NewFromFloat64(tone.multiplier(order))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidFrequency, err)
+	}
+	harmFreq, err := fundFreq.Mul(multiplier)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidFrequency, err)
+	}
 	f64, _ := harmFreq.Float64()
 
-	return Trunc(float32(f64), MaxSigFigs)
+	return Trunc(float32(f64), MaxSigFigs), nil
+}
+
+// PartialFreq calculates the frequency of one of the tone's partials the same way that HarmonicFreq
+// does, but with float64 math and without truncating the frequency. This is much cheaper than
+// HarmonicFreq, so it's better suited to rendering audio, where the frequencies are needed over and
+// over again. If the frequency cannot be calculated, it's NaN or infinite.
+func (tone *Tone) PartialFreq(order int) float64 {
+	if tone == nil || order <= 0 || tone.Frequency == 0 {
+		return 0
+	}
+
+	return math.Abs(float64(tone.Frequency)) * tone.PartialRatio(order)
+}
+
+// PartialRatio returns the number that the size of the tone's fundamental frequency is multiplied
+// by to get the frequency of one of the tone's partials, which PartialFreq uses. It only depends on
+// the tone's partial ratios, inharmonicity, and the sign of its fundamental frequency, so tones that
+// only differ in the size of their fundamental frequency share the same ratios.
+func (tone *Tone) PartialRatio(order int) float64 {
+	if tone == nil || order <= 0 {
+		return 0
+	}
+
+	if tone.Frequency < 0 {
+		order -= 2
+	}
+
+	return tone.multiplier(order)
+}
+
+// multiplier returns the number that the fundamental frequency is multiplied by to get the
+// frequency of a harmonic of the specified order.
+func (tone *Tone) multiplier(order int) float64 {
+	if i := order - 2; i >= 0 && i < len(tone.PartialRatios) {
+		if ratio := tone.PartialRatios[i]; ratio != 0 {
+			return float64(ratio)
+		}
+	}
+
+	n := float64(order)
+	if b := float64(tone.Inharmonicity); b != 0 && order > 1 {
+		return n * math.Sqrt((1+b*n*n)/(1+b))
+	}
+
+	return n
 }
 
 // Clone returns a complete copy of the tone that has all of the same values as the original but
@@ -109,6 +238,16 @@ func (tone *Tone) Clone() Tone {
 	c.HarmonicGains = make([]float32, len(tone.HarmonicGains))
 	copy(c.HarmonicGains, tone.HarmonicGains)
 
+	if tone.HarmonicPhases != nil {
+		c.HarmonicPhases = make([]float32, len(tone.HarmonicPhases))
+		copy(c.HarmonicPhases, tone.HarmonicPhases)
+	}
+
+	if tone.PartialRatios != nil {
+		c.PartialRatios = make([]float32, len(tone.PartialRatios))
+		copy(c.PartialRatios, tone.PartialRatios)
+	}
+
 	return c
 }
 
@@ -132,11 +271,27 @@ func (tone *Tone) Empty() bool {
 		}
 	}
 
+	for _, phase := range tone.HarmonicPhases {
+		if phase != 0 {
+			return false
+		}
+	}
+
+	if tone.Inharmonicity != 0 {
+		return false
+	}
+
+	for _, ratio := range tone.PartialRatios {
+		if ratio != 0 {
+			return false
+		}
+	}
+
 	return true
 }
 
-// Reset resets the tone to its zero values. The harmonic gains are set to zero, but the slice header
-// does not change.
+// Reset resets the tone to its zero values. The harmonic gains, harmonic phases, and partial ratios
+// are set to zero, but the slice headers do not change.
 func (tone *Tone) Reset() {
 	if tone == nil {
 		return
@@ -147,4 +302,11 @@ func (tone *Tone) Reset() {
 	for i := range tone.HarmonicGains {
 		tone.HarmonicGains[i] = 0
 	}
+	for i := range tone.HarmonicPhases {
+		tone.HarmonicPhases[i] = 0
+	}
+	tone.Inharmonicity = 0
+	for i := range tone.PartialRatios {
+		tone.PartialRatios[i] = 0
+	}
 }
@@ -1,7 +1,9 @@
 package tone_test
 
 import (
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/green-aloe/enobox/context"
 	"github.com/green-aloe/enobox/note"
@@ -128,17 +130,71 @@ func ExampleNewSawtoothTone() {
 	// [0.5 0.333333 0.25 0.2 0.166666 0.142857 0.125 0.111111 0.1 0.090909 0.0833333 0.076923 0.0714285 0.0666666 0.0625 0.0588235 0.0555555 0.0526315 0.05 0.047619]
 }
 
+func ExampleNewToneFromFormula() {
+	ctx := context.NewContext()
+
+	// Only the octaves of the fundamental frequency, each one half as loud as the last.
+	octaves := tone.NewToneFromFormula(ctx, 110, func(order int) float32 {
+		gain := float32(1)
+		for ; order > 1; order /= 2 {
+			if order%2 != 0 {
+				return 0
+			}
+			gain /= 2
+		}
+		return gain
+	})
+
+	fmt.Println(octaves.HarmonicGains)
+
+	// Output:
+	// [0.5 0 0.25 0 0 0 0.125 0 0 0 0 0 0 0 0.0625 0 0 0 0 0]
+}
+
+func ExampleNewDrawbarTone() {
+	ctx := context.NewContext()
+
+	organ := tone.NewDrawbarTone(ctx, 65.40639, tone.Drawbars{8, 8, 8, 0, 0, 0, 0, 0, 0})
+
+	fmt.Println(organ.HarmonicGains)
+
+	// Output:
+	// [1 1 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0]
+}
+
+func ExampleNewToneFromCycle() {
+	ctx := context.NewContext()
+
+	// One cycle of a square wave.
+	cycle := make([]float32, 64)
+	for i := range cycle {
+		cycle[i] = 1
+		if i >= len(cycle)/2 {
+			cycle[i] = -1
+		}
+	}
+
+	sqrTone := tone.NewToneFromCycle(ctx, 440, cycle)
+
+	fmt.Printf("%.2f\n", sqrTone.Gain)
+	fmt.Printf("%.2f\n", sqrTone.HarmonicGains[:6])
+
+	// Output:
+	// 1.27
+	// [0.00 0.33 0.00 0.20 0.00 0.15]
+}
+
 func ExampleTone_HarmonicFreq() {
 	ctx := context.NewContext()
 
 	tone := tone.NewTone(ctx)
-	harmFreq1 := tone.HarmonicFreq(2)
+	harmFreq1, _ := tone.HarmonicFreq(2)
 
 	tone.Frequency = 440
 
-	harmFreq2 := tone.HarmonicFreq(1)
-	harmFreq3 := tone.HarmonicFreq(2)
-	harmFreq4 := tone.HarmonicFreq(3)
+	harmFreq2, _ := tone.HarmonicFreq(1)
+	harmFreq3, _ := tone.HarmonicFreq(2)
+	harmFreq4, _ := tone.HarmonicFreq(3)
 
 	fmt.Println(harmFreq1, harmFreq2, harmFreq3, harmFreq4)
 
@@ -146,6 +202,42 @@ func ExampleTone_HarmonicFreq() {
 	// 0 440 880 1320
 }
 
+func ExampleTone_HarmonicFreq_inharmonic() {
+	ctx := context.NewContext()
+
+	// A stretched piano-like tone.
+	piano := tone.NewToneAt(ctx, 110)
+	piano.Inharmonicity = 0.0004
+
+	var freqs []float32
+	for _, order := range []int{1, 2, 10} {
+		freq, _ := piano.HarmonicFreq(order)
+		freqs = append(freqs, freq)
+	}
+	fmt.Println(freqs)
+
+	// A metal bar with explicit partial ratios.
+	bar := tone.NewToneAt(ctx, 200)
+	bar.PartialRatios = []float32{2.76, 5.40, 8.93}
+
+	freqs = freqs[:0]
+	for _, order := range []int{1, 2, 3, 4} {
+		freq, _ := bar.HarmonicFreq(order)
+		freqs = append(freqs, freq)
+	}
+	fmt.Println(freqs)
+
+	// A partial ratio that isn't a number doesn't have a frequency.
+	bar.PartialRatios[0] = float32(math.NaN())
+	_, err := bar.HarmonicFreq(2)
+	fmt.Println(errors.Is(err, tone.ErrInvalidFrequency))
+
+	// Output:
+	// [110 220.131 1121.56]
+	// [200 552 1080 1786]
+	// true
+}
+
 func ExampleTone_Clone() {
 	ctx := context.NewContext()
 
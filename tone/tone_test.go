@@ -2,6 +2,7 @@ package tone
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/green-aloe/enobox/context"
@@ -255,23 +256,66 @@ func Test_NewToneWith(t *testing.T) {
 				require.Equal(t, frequency, tone.Frequency)
 				require.Equal(t, gain, tone.Gain)
 				require.Len(t, tone.HarmonicGains, numHarmGains)
+				require.Nil(t, tone.HarmonicPhases)
 			}
 		}
 	}
 }
 
+// Test_Tone_HarmonicPhase tests that Tone's HarmonicPhase and SetHarmonicPhase methods read and
+// write a harmonic's phase, allocating the phases only when they're needed.
+func Test_Tone_HarmonicPhase(t *testing.T) {
+	t.Run("nil tone", func(t *testing.T) {
+		var tone *Tone
+		require.Zero(t, tone.HarmonicPhase(0))
+		require.NotPanics(t, func() { tone.SetHarmonicPhase(0, 1) })
+	})
+
+	tone := Tone{HarmonicGains: make([]float32, 3)}
+	for _, i := range []int{-1, 0, 2, 3} {
+		require.Zero(t, tone.HarmonicPhase(i))
+	}
+
+	// Setting a phase of 0 doesn't need any memory.
+	tone.SetHarmonicPhase(1, 0)
+	require.Nil(t, tone.HarmonicPhases)
+
+	tone.SetHarmonicPhase(1, 1.5)
+	require.Equal(t, []float32{0, 1.5, 0}, tone.HarmonicPhases)
+	require.Equal(t, float32(1.5), tone.HarmonicPhase(1))
+
+	// Invalid indexes are ignored.
+	tone.SetHarmonicPhase(-1, 2)
+	tone.SetHarmonicPhase(3, 2)
+	require.Equal(t, []float32{0, 1.5, 0}, tone.HarmonicPhases)
+
+	// Phases that are shorter than the harmonic gains are grown to match.
+	tone = Tone{HarmonicGains: make([]float32, 3), HarmonicPhases: []float32{0.5}}
+	tone.SetHarmonicPhase(2, 2.5)
+	require.Equal(t, []float32{0.5, 0, 2.5}, tone.HarmonicPhases)
+}
+
 // Test_Tone_HarmonicFreq tests that Tone's HarmonicFreq method returns the correct frequency for a
 // variety of tones and harmonics.
 func Test_Tone_HarmonicFreq(t *testing.T) {
 	t.Run("nil", func(t *testing.T) {
 		for i := range 10 {
 			var tone *Tone
-			require.Zero(t, tone.HarmonicFreq(i))
+			freq, err := tone.HarmonicFreq(i)
+			require.NoError(t, err)
+			require.Zero(t, freq)
 		}
 	})
 
 	ctx := context.NewContext()
 
+	// harmFreq returns the frequency of the tone's harmonic and makes sure that it is valid.
+	harmFreq := func(t *testing.T, tone Tone, order int) float32 {
+		freq, err := tone.HarmonicFreq(order)
+		require.NoError(t, err)
+		return freq
+	}
+
 	type subtest struct {
 		tone Tone
 		n    int
@@ -303,11 +347,66 @@ func Test_Tone_HarmonicFreq(t *testing.T) {
 
 	for _, subtest := range subtests {
 		t.Run(subtest.name, func(t *testing.T) {
-			have := subtest.tone.HarmonicFreq(subtest.n)
+			have := harmFreq(t, subtest.tone, subtest.n)
 			require.Equal(t, subtest.want, have)
 		})
 	}
 
+	t.Run("inharmonicity", func(t *testing.T) {
+		tone := NewToneAt(ctx, 100)
+		tone.Inharmonicity = 0.01
+
+		// The fundamental frequency doesn't move.
+		require.Equal(t, float32(100), harmFreq(t, tone, 1))
+
+		// Each harmonic is stretched further than the one before it.
+		for order, want := range map[int]float32{2: 202.948, 3: 311.654, 10: 1407.19} {
+			require.Equal(t, want, harmFreq(t, tone, order))
+		}
+
+		var prevStretch float32
+		for order := 2; order < 30; order++ {
+			stretch := harmFreq(t, tone, order) / float32(order*100)
+			require.Greater(t, stretch, prevStretch)
+			prevStretch = stretch
+		}
+	})
+
+	t.Run("partial ratios", func(t *testing.T) {
+		tone := NewToneAt(ctx, 100)
+		tone.Inharmonicity = 0.01
+		tone.PartialRatios = []float32{2.76, 0, 8.93}
+
+		require.Equal(t, float32(100), harmFreq(t, tone, 1))
+		require.Equal(t, float32(276), harmFreq(t, tone, 2))
+		require.Equal(t, float32(311.654), harmFreq(t, tone, 3))
+		require.Equal(t, float32(893), harmFreq(t, tone, 4))
+		require.Equal(t, float32(556.242), harmFreq(t, tone, 5))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, ratio := range []float32{float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1))} {
+			tone := NewToneAt(ctx, 100)
+			tone.PartialRatios = []float32{ratio}
+
+			require.Equal(t, float32(100), harmFreq(t, tone, 1))
+			require.Equal(t, float32(300), harmFreq(t, tone, 3))
+
+			freq, err := tone.HarmonicFreq(2)
+			require.ErrorIs(t, err, ErrInvalidFrequency)
+			require.Zero(t, freq)
+		}
+
+		tone := NewToneAt(ctx, float32(math.Inf(1)))
+		_, err := tone.HarmonicFreq(1)
+		require.ErrorIs(t, err, ErrInvalidFrequency)
+
+		tone = NewToneAt(ctx, 100)
+		tone.Inharmonicity = float32(math.NaN())
+		_, err = tone.HarmonicFreq(2)
+		require.ErrorIs(t, err, ErrInvalidFrequency)
+	})
+
 	t.Run("table", func(t *testing.T) {
 		fundFreq := float32(34.6478)
 		wantsNeg := []float32{
@@ -342,19 +441,58 @@ func Test_Tone_HarmonicFreq(t *testing.T) {
 		// Test a tone with a negative fundamental frequency.
 		negTone := NewToneAt(ctx, -fundFreq)
 		for i, want := range wantsNeg {
-			have := negTone.HarmonicFreq(i + 1)
+			have := harmFreq(t, negTone, i+1)
 			require.Equal(t, want, have)
 		}
 
 		// Test a tone with a positive fundamental frequency.
 		posTone := NewToneAt(ctx, fundFreq)
 		for i, want := range wantsPos {
-			have := posTone.HarmonicFreq(i + 1)
+			have := harmFreq(t, posTone, i+1)
 			require.Equal(t, want, have)
 		}
 	})
 }
 
+// Test_Tone_PartialFreq tests that Tone's PartialFreq method returns the same frequencies as
+// HarmonicFreq, without truncating them.
+func Test_Tone_PartialFreq(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var tone *Tone
+		require.Zero(t, tone.PartialFreq(1))
+	})
+
+	ctx := context.NewContext()
+
+	tones := []Tone{NewTone(ctx), NewToneAt(ctx, -34.6478), NewToneAt(ctx, 87.30706)}
+
+	tone := NewToneAt(ctx, 100)
+	tone.Inharmonicity = 0.01
+	tones = append(tones, tone)
+
+	tone = NewToneAt(ctx, 100)
+	tone.PartialRatios = []float32{2.76, 0, 8.93}
+	tones = append(tones, tone)
+
+	for _, tone := range tones {
+		for order := -1; order < 30; order++ {
+			want, err := tone.HarmonicFreq(order)
+			require.NoError(t, err)
+			require.InEpsilon(t, float64(want)+1, tone.PartialFreq(order)+1, 1e-5)
+		}
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		tone := NewToneAt(ctx, 100)
+		tone.PartialRatios = []float32{float32(math.NaN()), float32(math.Inf(1))}
+
+		require.Equal(t, float64(100), tone.PartialFreq(1))
+		require.True(t, math.IsNaN(tone.PartialFreq(2)))
+		require.True(t, math.IsInf(tone.PartialFreq(3), 1))
+		require.Equal(t, float64(400), tone.PartialFreq(4))
+	})
+}
+
 // Test_Tone_Clone tests that Tone's Clone method creates a deep copy of the tone that has all of
 // the same values as the original but does not share any memory with it.
 func Test_Tone_Clone(t *testing.T) {
@@ -374,10 +512,11 @@ func Test_Tone_Clone(t *testing.T) {
 		{Tone{}, "empty"},
 		{NewTone(ctx), "new"},
 		{NewToneAt(ctx, 42), "frequency only"},
-		{Tone{0, 0, []float32{}}, "empty, no harmonics"},
-		{Tone{1, 1, []float32{}}, "frequency and gain only"},
-		{Tone{0, 0, []float32{.41, 103.3}}, "harmonics only"},
-		{Tone{1.1, 2.2, []float32{0, 1.1, 0.03}}, "all fields"},
+		{Tone{HarmonicGains: []float32{}}, "empty, no harmonics"},
+		{Tone{Frequency: 1, Gain: 1, HarmonicGains: []float32{}}, "frequency and gain only"},
+		{Tone{HarmonicGains: []float32{.41, 103.3}}, "harmonics only"},
+		{Tone{Frequency: 1.1, Gain: 2.2, HarmonicGains: []float32{0, 1.1, 0.03}}, "frequency, gain, and harmonics"},
+		{Tone{Frequency: 1.1, Gain: 2.2, HarmonicGains: []float32{0, 1.1, 0.03}, HarmonicPhases: []float32{0.5, 0, 3.14}, Inharmonicity: 0.01, PartialRatios: []float32{2.5, 0, 3.5}}, "all fields"},
 	}
 
 	for _, subtest := range subtests {
@@ -391,6 +530,9 @@ func Test_Tone_Clone(t *testing.T) {
 			for i := range subtest.tone.HarmonicGains {
 				require.Equal(t, subtest.tone.HarmonicGains[i], clone.HarmonicGains[i])
 			}
+			require.Equal(t, subtest.tone.HarmonicPhases, clone.HarmonicPhases)
+			require.Equal(t, subtest.tone.Inharmonicity, clone.Inharmonicity)
+			require.Equal(t, subtest.tone.PartialRatios, clone.PartialRatios)
 
 			// Make sure no fields share memory.
 			subtest.tone.Frequency++
@@ -401,6 +543,14 @@ func Test_Tone_Clone(t *testing.T) {
 				subtest.tone.HarmonicGains[i]++
 				require.NotEqual(t, subtest.tone.HarmonicGains[i], clone.HarmonicGains[i])
 			}
+			for i := range subtest.tone.HarmonicPhases {
+				subtest.tone.HarmonicPhases[i]++
+				require.NotEqual(t, subtest.tone.HarmonicPhases[i], clone.HarmonicPhases[i])
+			}
+			for i := range subtest.tone.PartialRatios {
+				subtest.tone.PartialRatios[i]++
+				require.NotEqual(t, subtest.tone.PartialRatios[i], clone.PartialRatios[i])
+			}
 		})
 	}
 }
@@ -423,14 +573,18 @@ func Test_Tone_Empty(t *testing.T) {
 	subtests := []subtest{
 		{true, Tone{}, "empty"},
 		{true, NewTone(ctx), "new"},
-		{false, Tone{10, 0, nil}, "frequency only"},
-		{false, Tone{0, 10, nil}, "gain only"},
-		{false, Tone{0, 0, []float32{0.1, 0.2}}, "harmonics only"},
-		{false, Tone{10, 10, nil}, "frequency and gain"},
-		{false, Tone{10, 10, []float32{0.1, 0.2}}, "all fields"},
-		{false, Tone{10, 10, []float32{-20}}, "all fields, negative harmonic gain"},
-		{false, Tone{-20, 0, nil}, "negative frequency"},
-		{false, Tone{0, -20, nil}, "negative gain"},
+		{false, Tone{Frequency: 10}, "frequency only"},
+		{false, Tone{Gain: 10}, "gain only"},
+		{false, Tone{HarmonicGains: []float32{0.1, 0.2}}, "harmonics only"},
+		{false, Tone{Frequency: 10, Gain: 10}, "frequency and gain"},
+		{false, Tone{Frequency: 10, Gain: 10, HarmonicGains: []float32{0.1, 0.2}}, "all fields"},
+		{false, Tone{Frequency: 10, Gain: 10, HarmonicGains: []float32{-20}}, "all fields, negative harmonic gain"},
+		{false, Tone{Frequency: -20}, "negative frequency"},
+		{false, Tone{Gain: -20}, "negative gain"},
+		{false, Tone{HarmonicPhases: []float32{0, 1.5}}, "harmonic phases only"},
+		{false, Tone{Inharmonicity: 0.001}, "inharmonicity only"},
+		{false, Tone{PartialRatios: []float32{0, 2.76}}, "partial ratios only"},
+		{true, Tone{PartialRatios: []float32{0, 0}}, "zero partial ratios"},
 		{false, NewSquareTone(ctx, 10), "square tone"},
 		{false, NewTriangleTone(ctx, 20), "triangle tone"},
 		{false, NewSawtoothTone(ctx, 30), "sawtooth tone"},
@@ -492,5 +646,22 @@ func Test_Tone_Reset(t *testing.T) {
 			require.True(t, tone.Empty())
 			require.Len(t, tone.HarmonicGains, numHarmGains)
 		})
+
+		t.Run("initialized with partials", func(t *testing.T) {
+			tone := NewToneAt(ctx, 52.24)
+			for i := range tone.HarmonicGains {
+				tone.SetHarmonicPhase(i, float32(i)+0.5)
+			}
+			tone.Inharmonicity = 0.001
+			tone.PartialRatios = []float32{2.76, 5.40, 8.93}
+
+			tone.Reset()
+			require.True(t, tone.Empty())
+			for i := range numHarmGains {
+				require.Zero(t, tone.HarmonicPhase(i))
+			}
+			require.Zero(t, tone.Inharmonicity)
+			require.Equal(t, []float32{0, 0, 0}, tone.PartialRatios)
+		})
 	}
 }
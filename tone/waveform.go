@@ -1,65 +1,9 @@
 package tone
 
-import "github.com/green-aloe/enobox/context"
-
-var (
-	// First 100 harmonic gains for a square waveform, truncated to 6 digits, calculated with this
-	// formula:
-	//   gain = 1 / (order + 2) if order is odd, 0 if order is even
-	squareHarmGains = []float32{
-		0, Trunc(1.0/3, MaxSigFigs), 0, Trunc(1.0/5, MaxSigFigs), 0, Trunc(1.0/7, MaxSigFigs), 0, Trunc(1.0/9, MaxSigFigs), 0, Trunc(1.0/11, MaxSigFigs),
-		0, Trunc(1.0/13, MaxSigFigs), 0, Trunc(1.0/15, MaxSigFigs), 0, Trunc(1.0/17, MaxSigFigs), 0, Trunc(1.0/19, MaxSigFigs), 0, Trunc(1.0/21, MaxSigFigs),
-		0, Trunc(1.0/23, MaxSigFigs), 0, Trunc(1.0/25, MaxSigFigs), 0, Trunc(1.0/27, MaxSigFigs), 0, Trunc(1.0/29, MaxSigFigs), 0, Trunc(1.0/31, MaxSigFigs),
-		0, Trunc(1.0/33, MaxSigFigs), 0, Trunc(1.0/35, MaxSigFigs), 0, Trunc(1.0/37, MaxSigFigs), 0, Trunc(1.0/39, MaxSigFigs), 0, Trunc(1.0/41, MaxSigFigs),
-		0, Trunc(1.0/43, MaxSigFigs), 0, Trunc(1.0/45, MaxSigFigs), 0, Trunc(1.0/47, MaxSigFigs), 0, Trunc(1.0/49, MaxSigFigs), 0, Trunc(1.0/51, MaxSigFigs),
-		0, Trunc(1.0/53, MaxSigFigs), 0, Trunc(1.0/55, MaxSigFigs), 0, Trunc(1.0/57, MaxSigFigs), 0, Trunc(1.0/59, MaxSigFigs), 0, Trunc(1.0/61, MaxSigFigs),
-		0, Trunc(1.0/63, MaxSigFigs), 0, Trunc(1.0/65, MaxSigFigs), 0, Trunc(1.0/67, MaxSigFigs), 0, Trunc(1.0/69, MaxSigFigs), 0, Trunc(1.0/71, MaxSigFigs),
-		0, Trunc(1.0/73, MaxSigFigs), 0, Trunc(1.0/75, MaxSigFigs), 0, Trunc(1.0/77, MaxSigFigs), 0, Trunc(1.0/79, MaxSigFigs), 0, Trunc(1.0/81, MaxSigFigs),
-		0, Trunc(1.0/83, MaxSigFigs), 0, Trunc(1.0/85, MaxSigFigs), 0, Trunc(1.0/87, MaxSigFigs), 0, Trunc(1.0/89, MaxSigFigs), 0, Trunc(1.0/91, MaxSigFigs),
-		0, Trunc(1.0/93, MaxSigFigs), 0, Trunc(1.0/95, MaxSigFigs), 0, Trunc(1.0/97, MaxSigFigs), 0, Trunc(1.0/99, MaxSigFigs), 0, Trunc(1.0/101, MaxSigFigs),
-	}
-
-	// First 100 harmonic gains for a triangle waveform, truncated to 6 digits, calculated with this
-	// formula:
-	//   gain = 1 / (order^2 + 1) if order is odd, 0 if order is even
-	triangleHarmGains = []float32{
-		0, Trunc(1.0/9, MaxSigFigs), 0, Trunc(1.0/25, MaxSigFigs), 0, Trunc(1.0/49, MaxSigFigs), 0, Trunc(1.0/81, MaxSigFigs), 0, Trunc(1.0/121, MaxSigFigs),
-		0, Trunc(1.0/169, MaxSigFigs), 0, Trunc(1.0/225, MaxSigFigs), 0, Trunc(1.0/289, MaxSigFigs), 0, Trunc(1.0/361, MaxSigFigs), 0, Trunc(1.0/441, MaxSigFigs),
-		0, Trunc(1.0/529, MaxSigFigs), 0, Trunc(1.0/625, MaxSigFigs), 0, Trunc(1.0/729, MaxSigFigs), 0, Trunc(1.0/841, MaxSigFigs), 0, Trunc(1.0/961, MaxSigFigs),
-		0, Trunc(1.0/1089, MaxSigFigs), 0, Trunc(1.0/1225, MaxSigFigs), 0, Trunc(1.0/1369, MaxSigFigs), 0, Trunc(1.0/1521, MaxSigFigs), 0, Trunc(1.0/1681, MaxSigFigs),
-		0, Trunc(1.0/1849, MaxSigFigs), 0, Trunc(1.0/2025, MaxSigFigs), 0, Trunc(1.0/2209, MaxSigFigs), 0, Trunc(1.0/2401, MaxSigFigs), 0, Trunc(1.0/2601, MaxSigFigs),
-		0, Trunc(1.0/2809, MaxSigFigs), 0, Trunc(1.0/3025, MaxSigFigs), 0, Trunc(1.0/3249, MaxSigFigs), 0, Trunc(1.0/3481, MaxSigFigs), 0, Trunc(1.0/3721, MaxSigFigs),
-		0, Trunc(1.0/3969, MaxSigFigs), 0, Trunc(1.0/4225, MaxSigFigs), 0, Trunc(1.0/4489, MaxSigFigs), 0, Trunc(1.0/4761, MaxSigFigs), 0, Trunc(1.0/5041, MaxSigFigs),
-		0, Trunc(1.0/5329, MaxSigFigs), 0, Trunc(1.0/5625, MaxSigFigs), 0, Trunc(1.0/5929, MaxSigFigs), 0, Trunc(1.0/6241, MaxSigFigs), 0, Trunc(1.0/6561, MaxSigFigs),
-		0, Trunc(1.0/6889, MaxSigFigs), 0, Trunc(1.0/7225, MaxSigFigs), 0, Trunc(1.0/7569, MaxSigFigs), 0, Trunc(1.0/7921, MaxSigFigs), 0, Trunc(1.0/8281, MaxSigFigs),
-		0, Trunc(1.0/8649, MaxSigFigs), 0, Trunc(1.0/9025, MaxSigFigs), 0, Trunc(1.0/9409, MaxSigFigs), 0, Trunc(1.0/9801, MaxSigFigs), 0, Trunc(1.0/10201, MaxSigFigs),
-	}
+import (
+	"math"
 
-	// First 100 harmonic gains for a sawtooth waveform, truncated to 6 digits, calculated with this
-	// formula:
-	//   gain = 1 / order
-	sawtoothHarmGains = []float32{
-		Trunc(1.0/2, MaxSigFigs), Trunc(1.0/3, MaxSigFigs), Trunc(1.0/4, MaxSigFigs), Trunc(1.0/5, MaxSigFigs), Trunc(1.0/6, MaxSigFigs),
-		Trunc(1.0/7, MaxSigFigs), Trunc(1.0/8, MaxSigFigs), Trunc(1.0/9, MaxSigFigs), Trunc(1.0/10, MaxSigFigs), Trunc(1.0/11, MaxSigFigs),
-		Trunc(1.0/12, MaxSigFigs), Trunc(1.0/13, MaxSigFigs), Trunc(1.0/14, MaxSigFigs), Trunc(1.0/15, MaxSigFigs), Trunc(1.0/16, MaxSigFigs),
-		Trunc(1.0/17, MaxSigFigs), Trunc(1.0/18, MaxSigFigs), Trunc(1.0/19, MaxSigFigs), Trunc(1.0/20, MaxSigFigs), Trunc(1.0/21, MaxSigFigs),
-		Trunc(1.0/22, MaxSigFigs), Trunc(1.0/23, MaxSigFigs), Trunc(1.0/24, MaxSigFigs), Trunc(1.0/25, MaxSigFigs), Trunc(1.0/26, MaxSigFigs),
-		Trunc(1.0/27, MaxSigFigs), Trunc(1.0/28, MaxSigFigs), Trunc(1.0/29, MaxSigFigs), Trunc(1.0/30, MaxSigFigs), Trunc(1.0/31, MaxSigFigs),
-		Trunc(1.0/32, MaxSigFigs), Trunc(1.0/33, MaxSigFigs), Trunc(1.0/34, MaxSigFigs), Trunc(1.0/35, MaxSigFigs), Trunc(1.0/36, MaxSigFigs),
-		Trunc(1.0/37, MaxSigFigs), Trunc(1.0/38, MaxSigFigs), Trunc(1.0/39, MaxSigFigs), Trunc(1.0/40, MaxSigFigs), Trunc(1.0/41, MaxSigFigs),
-		Trunc(1.0/42, MaxSigFigs), Trunc(1.0/43, MaxSigFigs), Trunc(1.0/44, MaxSigFigs), Trunc(1.0/45, MaxSigFigs), Trunc(1.0/46, MaxSigFigs),
-		Trunc(1.0/47, MaxSigFigs), Trunc(1.0/48, MaxSigFigs), Trunc(1.0/49, MaxSigFigs), Trunc(1.0/50, MaxSigFigs), Trunc(1.0/51, MaxSigFigs),
-		Trunc(1.0/52, MaxSigFigs), Trunc(1.0/53, MaxSigFigs), Trunc(1.0/54, MaxSigFigs), Trunc(1.0/55, MaxSigFigs), Trunc(1.0/56, MaxSigFigs),
-		Trunc(1.0/57, MaxSigFigs), Trunc(1.0/58, MaxSigFigs), Trunc(1.0/59, MaxSigFigs), Trunc(1.0/60, MaxSigFigs), Trunc(1.0/61, MaxSigFigs),
-		Trunc(1.0/62, MaxSigFigs), Trunc(1.0/63, MaxSigFigs), Trunc(1.0/64, MaxSigFigs), Trunc(1.0/65, MaxSigFigs), Trunc(1.0/66, MaxSigFigs),
-		Trunc(1.0/67, MaxSigFigs), Trunc(1.0/68, MaxSigFigs), Trunc(1.0/69, MaxSigFigs), Trunc(1.0/70, MaxSigFigs), Trunc(1.0/71, MaxSigFigs),
-		Trunc(1.0/72, MaxSigFigs), Trunc(1.0/73, MaxSigFigs), Trunc(1.0/74, MaxSigFigs), Trunc(1.0/75, MaxSigFigs), Trunc(1.0/76, MaxSigFigs),
-		Trunc(1.0/77, MaxSigFigs), Trunc(1.0/78, MaxSigFigs), Trunc(1.0/79, MaxSigFigs), Trunc(1.0/80, MaxSigFigs), Trunc(1.0/81, MaxSigFigs),
-		Trunc(1.0/82, MaxSigFigs), Trunc(1.0/83, MaxSigFigs), Trunc(1.0/84, MaxSigFigs), Trunc(1.0/85, MaxSigFigs), Trunc(1.0/86, MaxSigFigs),
-		Trunc(1.0/87, MaxSigFigs), Trunc(1.0/88, MaxSigFigs), Trunc(1.0/89, MaxSigFigs), Trunc(1.0/90, MaxSigFigs), Trunc(1.0/91, MaxSigFigs),
-		Trunc(1.0/92, MaxSigFigs), Trunc(1.0/93, MaxSigFigs), Trunc(1.0/94, MaxSigFigs), Trunc(1.0/95, MaxSigFigs), Trunc(1.0/96, MaxSigFigs),
-		Trunc(1.0/97, MaxSigFigs), Trunc(1.0/98, MaxSigFigs), Trunc(1.0/99, MaxSigFigs), Trunc(1.0/100, MaxSigFigs), Trunc(1.0/101, MaxSigFigs),
-	}
+	"github.com/green-aloe/enobox/context"
 )
 
 // NewSquareTone creates a new tone that has a square waveform.
@@ -74,11 +18,10 @@ var (
 //   - Harmonic 2: order = 3, frequency = 300Hz, gain = 1/3
 //   - Harmonic 3: order = 4, frequency = 400Hz, gain = 0
 //   - Harmonic 4: order = 5, frequency = 500Hz, gain = 1/5
+//
+// Every harmonic has a phase of 0.
 func NewSquareTone(ctx context.Context, frequency float32) Tone {
-	tone := NewToneAt(ctx, frequency)
-	copy(tone.HarmonicGains, squareHarmGains)
-
-	return tone
+	return NewToneFromFormula(ctx, frequency, SquareFormula)
 }
 
 // NewTriangleTone creates a new tone that has a triangle waveform.
@@ -93,11 +36,11 @@ func NewSquareTone(ctx context.Context, frequency float32) Tone {
 //   - Harmonic 2: order = 3, frequency = 300Hz, gain = 1/9
 //   - Harmonic 3: order = 4, frequency = 400Hz, gain = 0
 //   - Harmonic 4: order = 5, frequency = 500Hz, gain = 1/25
+//
+// Every other odd-ordered harmonic (orders 3, 7, 11, and so on) has a phase of pi, which gives the
+// waveform its sharp corners.
 func NewTriangleTone(ctx context.Context, frequency float32) Tone {
-	tone := NewToneAt(ctx, frequency)
-	copy(tone.HarmonicGains, triangleHarmGains)
-
-	return tone
+	return NewToneFromFormula(ctx, frequency, TriangleFormula)
 }
 
 // NewSawtoothTone creates a new tone that has a sawtooth waveform.
@@ -112,9 +55,190 @@ func NewTriangleTone(ctx context.Context, frequency float32) Tone {
 //   - Harmonic 2: order = 3, frequency = 300Hz, gain = 1/3
 //   - Harmonic 3: order = 4, frequency = 400Hz, gain = 1/4
 //   - Harmonic 4: order = 5, frequency = 500Hz, gain = 1/5
+//
+// The even-ordered harmonics have a phase of pi, which makes the waveform rise steadily and then
+// drop sharply at the end of each cycle.
 func NewSawtoothTone(ctx context.Context, frequency float32) Tone {
-	tone := NewToneAt(ctx, frequency)
-	copy(tone.HarmonicGains, sawtoothHarmGains)
+	return NewToneFromFormula(ctx, frequency, SawtoothFormula)
+}
+
+// NewPulseTone creates a new tone that has a pulse waveform with the specified duty cycle.
+//
+// A pulse waveform is high for a portion of each cycle and low for the rest of it. The duty cycle is
+// the portion of the cycle that the waveform is high, from 0 to 1. A duty cycle of 0.5 is the same
+// as a square waveform, while narrower pulses sound thinner and more nasal. Pulse waves with duty
+// cycles of d and 1-d sound the same.
+//
+// The gains come from PulseFormula. The harmonics of a pulse waveform are not all in phase with
+// each other like they are in the other waveforms, so this also sets the harmonic phases to keep
+// the waveform's shape.
+func NewPulseTone(ctx context.Context, frequency float32, duty float32) Tone {
+	tone := NewToneFromFormula(ctx, frequency, PulseFormula(duty))
+
+	// Harmonic n is a cosine that is n quarter-cycles ahead of the fundamental frequency, relative
+	// to a sine wave. Negative gains add another half cycle.
+	for i, gain := range tone.HarmonicGains {
+		if gain == 0 {
+			tone.SetHarmonicPhase(i, 0)
+			continue
+		}
+
+		order := i + 2
+		quarters := ((1-order)%4 + 4) % 4
+		if tone.HarmonicPhase(i) != 0 {
+			quarters = (quarters + 2) % 4
+		}
+		tone.SetHarmonicPhase(i, float32(quarters)*math.Pi/2)
+	}
 
 	return tone
 }
+
+// NewDrawbarTone creates a new tone that has the sound of a tonewheel organ with the specified
+// drawbar registration. The tone's frequency is the pitch of the 16' drawbar. The gains come from
+// DrawbarFormula.
+func NewDrawbarTone(ctx context.Context, frequency float32, drawbars Drawbars) Tone {
+	return NewToneFromFormula(ctx, frequency, DrawbarFormula(drawbars))
+}
+
+// NewClarinetTone creates a new tone that has a clarinet-like waveform, where only the odd-ordered
+// harmonics have gains and the gains fall off at the specified rate. The gains come from
+// ClarinetFormula.
+func NewClarinetTone(ctx context.Context, frequency float32, rolloff float32) Tone {
+	return NewToneFromFormula(ctx, frequency, ClarinetFormula(rolloff))
+}
+
+// SquareFormula calculates the gain of a harmonic in a square waveform:
+//
+//	gain = 1 / order if order is odd, 0 if order is even
+func SquareFormula(order int) float32 {
+	if order <= 0 || order%2 == 0 {
+		return 0
+	}
+
+	return 1 / float32(order)
+}
+
+// TriangleFormula calculates the gain of a harmonic in a triangle waveform:
+//
+//	gain = 1 / order^2 if order is 1, 5, 9, ...
+//	gain = -1 / order^2 if order is 3, 7, 11, ...
+//	gain = 0 if order is even
+func TriangleFormula(order int) float32 {
+	if order <= 0 || order%2 == 0 {
+		return 0
+	}
+
+	gain := 1 / float32(order*order)
+	if order%4 == 3 {
+		gain = -gain
+	}
+
+	return gain
+}
+
+// SawtoothFormula calculates the gain of a harmonic in a sawtooth waveform:
+//
+//	gain = 1 / order if order is odd, -1 / order if order is even
+func SawtoothFormula(order int) float32 {
+	if order <= 0 {
+		return 0
+	}
+
+	gain := 1 / float32(order)
+	if order%2 == 0 {
+		gain = -gain
+	}
+
+	return gain
+}
+
+// PulseFormula returns a formula that calculates the gain of a harmonic in a pulse waveform with the
+// specified duty cycle (0 to 1):
+//
+//	gain = sin(order * pi * duty) / (order * sin(pi * duty))
+//
+// A duty cycle of 0 or 1 has no harmonics, because the waveform never changes. Note that the gains
+// alone do not make a pulse waveform; see NewPulseTone.
+func PulseFormula(duty float32) Formula {
+	return func(order int) float32 {
+		if order <= 0 || duty <= 0 || duty >= 1 {
+			return 0
+		}
+
+		d := float64(duty)
+		n := float64(order)
+		gain := math.Sin(n*math.Pi*d) / (n * math.Sin(math.Pi*d))
+
+		// Round away the floating-point noise for harmonics that should be exactly 0.
+		if math.Abs(gain) < 1e-9 {
+			return 0
+		}
+
+		return float32(gain)
+	}
+}
+
+// Drawbars holds the settings of the nine drawbars on a tonewheel organ, from 0 (all the way in) to
+// 8 (all the way out). The drawbars are in the order that they appear on the organ:
+//
+//	16', 5 1/3', 8', 4', 2 2/3', 2', 1 3/5', 1 1/3', 1'
+//
+// As an example, the classic "888000000" registration is Drawbars{8, 8, 8, 0, 0, 0, 0, 0, 0}.
+type Drawbars [9]int
+
+// Harmonic order of each drawbar, relative to the 16' drawbar.
+var drawbarOrders = Drawbars{1, 3, 2, 4, 6, 8, 10, 12, 16}
+
+// DrawbarFormula returns a formula that calculates the gain of a harmonic for a drawbar
+// registration. Each step on a drawbar is about 3dB, and a drawbar that is all the way in is silent.
+// The gains are relative to the 16' drawbar, which plays the fundamental frequency. Because a tone
+// always has its fundamental frequency, a 16' drawbar that is all the way in is treated as if it
+// were all the way out.
+func DrawbarFormula(drawbars Drawbars) Formula {
+	ref := drawbarLevel(drawbars[0])
+	if ref == 0 {
+		ref = 1
+	}
+
+	var gains [17]float32
+	for i, order := range drawbarOrders {
+		gains[order] = drawbarLevel(drawbars[i]) / ref
+	}
+
+	return func(order int) float32 {
+		if order <= 1 || order >= len(gains) {
+			return 0
+		}
+
+		return gains[order]
+	}
+}
+
+// drawbarLevel converts a drawbar setting to an amplitude.
+func drawbarLevel(setting int) float32 {
+	if setting <= 0 {
+		return 0
+	}
+	setting = min(setting, 8)
+
+	return float32(math.Pow(10, -3*float64(8-setting)/20))
+}
+
+// ClarinetFormula returns a formula that calculates the gain of a harmonic in a clarinet-like
+// waveform, where the even-ordered harmonics are silent and the odd-ordered harmonics fall off at
+// the specified rate:
+//
+//	gain = 1 / order^rolloff if order is odd, 0 if order is even
+//
+// A roll-off of 1 is the same as a square waveform, while larger roll-offs sound darker and smaller
+// roll-offs sound brighter.
+func ClarinetFormula(rolloff float32) Formula {
+	return func(order int) float32 {
+		if order <= 0 || order%2 == 0 {
+			return 0
+		}
+
+		return float32(math.Pow(float64(order), -float64(rolloff)))
+	}
+}
@@ -1,6 +1,7 @@
 package tone
 
 import (
+	"math"
 	"testing"
 
 	"github.com/green-aloe/enobox/context"
@@ -24,11 +25,10 @@ func Test_NewSquareTone(t *testing.T) {
 		0, 0.0107526, 0, 0.0105263, 0, 0.0103092, 0, 0.0101010, 0, 0.00990099,
 	}
 
-	t.Run("precalculations", func(t *testing.T) {
-		require.Equal(t, len(wantHarmGains), len(squareHarmGains))
-		for i, have := range squareHarmGains {
-			want := wantHarmGains[i]
-			require.Equal(t, want, have)
+	t.Run("formula", func(t *testing.T) {
+		for i, want := range wantHarmGains {
+			have := SquareFormula(i + 2)
+			require.Equal(t, want, Trunc(have, MaxSigFigs))
 		}
 	})
 
@@ -58,6 +58,7 @@ func Test_NewSquareTone(t *testing.T) {
 				for i, harmGain := range tone.HarmonicGains {
 					require.Equal(t, wantHarmGains[i], harmGain)
 				}
+				require.Nil(t, tone.HarmonicPhases)
 
 				// Test negative frequencies.
 				tone = NewSquareTone(ctx, -subtest.frequency)
@@ -88,11 +89,10 @@ func Test_NewTriangleTone(t *testing.T) {
 		0, 0.000115620, 0, 0.000110803, 0, 0.000106281, 0, 0.000102030, 0, 0.0000980296,
 	}
 
-	t.Run("precalculations", func(t *testing.T) {
-		require.Equal(t, len(wantHarmGains), len(triangleHarmGains))
-		for i, have := range triangleHarmGains {
-			want := wantHarmGains[i]
-			require.Equal(t, want, have)
+	t.Run("formula", func(t *testing.T) {
+		for i, want := range wantHarmGains {
+			have := TriangleFormula(i + 2)
+			require.Equal(t, want, Trunc(float32(math.Abs(float64(have))), MaxSigFigs))
 		}
 	})
 
@@ -122,6 +122,14 @@ func Test_NewTriangleTone(t *testing.T) {
 				for i, harmGain := range tone.HarmonicGains {
 					require.Equal(t, wantHarmGains[i], harmGain)
 				}
+				for i := range numHarmGains {
+					phase := tone.HarmonicPhase(i)
+					if order := i + 2; order%4 == 3 {
+						require.Equal(t, float32(math.Pi), phase)
+					} else {
+						require.Zero(t, phase)
+					}
+				}
 
 				// Test negative frequencies.
 				tone = NewTriangleTone(ctx, -subtest.frequency)
@@ -152,11 +160,10 @@ func Test_NewSawtoothTone(t *testing.T) {
 		0.0108695, 0.0107526, 0.0106382, 0.0105263, 0.0104166, 0.0103092, 0.0102040, 0.0101010, 0.0100000, 0.00990099,
 	}
 
-	t.Run("precalculations", func(t *testing.T) {
-		require.Equal(t, len(wantHarmGains), len(sawtoothHarmGains))
-		for i, have := range sawtoothHarmGains {
-			want := wantHarmGains[i]
-			require.Equal(t, want, have)
+	t.Run("formula", func(t *testing.T) {
+		for i, want := range wantHarmGains {
+			have := SawtoothFormula(i + 2)
+			require.Equal(t, want, Trunc(float32(math.Abs(float64(have))), MaxSigFigs))
 		}
 	})
 
@@ -186,6 +193,14 @@ func Test_NewSawtoothTone(t *testing.T) {
 				for i, harmGain := range tone.HarmonicGains {
 					require.Equal(t, wantHarmGains[i], harmGain)
 				}
+				for i := range numHarmGains {
+					phase := tone.HarmonicPhase(i)
+					if order := i + 2; order%2 == 0 {
+						require.Equal(t, float32(math.Pi), phase)
+					} else {
+						require.Zero(t, phase)
+					}
+				}
 
 				// Test negative frequencies.
 				tone = NewSawtoothTone(ctx, -subtest.frequency)
@@ -198,3 +213,99 @@ func Test_NewSawtoothTone(t *testing.T) {
 		})
 	}
 }
+
+// Test_NewPulseTone tests that NewPulseTone returns a tone that has a pulse waveform.
+func Test_NewPulseTone(t *testing.T) {
+	ctx := context.NewContext()
+
+	t.Run("square", func(t *testing.T) {
+		// A pulse with a duty cycle of 50% is a square wave.
+		pulse := NewPulseTone(ctx, 440, 0.5)
+		square := NewSquareTone(ctx, 440)
+		require.InDeltaSlice(t, square.HarmonicGains, pulse.HarmonicGains, 1e-6)
+		for i := range square.HarmonicGains {
+			require.Equal(t, square.HarmonicPhase(i), pulse.HarmonicPhase(i))
+		}
+	})
+
+	t.Run("quarter", func(t *testing.T) {
+		pulse := NewPulseTone(ctx, 440, 0.25)
+		require.Equal(t, float32(440), pulse.Frequency)
+
+		wantGains := []float32{0.707106, 0.333333, 0, 0.2, 0.235702, 0.142857, 0}
+		wantPhases := []float32{3 * math.Pi / 2, math.Pi, 0, math.Pi, math.Pi / 2, 0, 0}
+		require.Equal(t, wantGains, pulse.HarmonicGains[:len(wantGains)])
+		require.Equal(t, wantPhases, pulse.HarmonicPhases[:len(wantPhases)])
+	})
+
+	t.Run("mirrored duty cycles", func(t *testing.T) {
+		narrow := NewPulseTone(ctx, 440, 0.1)
+		wide := NewPulseTone(ctx, 440, 0.9)
+		for i := range narrow.HarmonicGains {
+			require.InDelta(t, narrow.HarmonicGains[i], wide.HarmonicGains[i], 1e-5)
+		}
+	})
+
+	t.Run("invalid duty cycles", func(t *testing.T) {
+		for _, duty := range []float32{-1, 0, 1, 2} {
+			pulse := NewPulseTone(ctx, 440, duty)
+			require.Equal(t, NewToneAt(ctx, 440), pulse)
+		}
+	})
+}
+
+// Test_NewDrawbarTone tests that NewDrawbarTone returns a tone with the gains of a drawbar
+// registration.
+func Test_NewDrawbarTone(t *testing.T) {
+	ctx := context.NewContext()
+
+	t.Run("all out", func(t *testing.T) {
+		tone := NewDrawbarTone(ctx, 110, Drawbars{8, 8, 8, 8, 8, 8, 8, 8, 8})
+		require.Equal(t, float32(110), tone.Frequency)
+		for i, gain := range tone.HarmonicGains {
+			switch order := i + 2; order {
+			case 2, 3, 4, 6, 8, 10, 12, 16:
+				require.Equal(t, float32(1), gain, order)
+			default:
+				require.Zero(t, gain, order)
+			}
+		}
+	})
+
+	t.Run("relative to 16'", func(t *testing.T) {
+		tone := NewDrawbarTone(ctx, 110, Drawbars{6, 0, 8, 0, 0, 0, 0, 0, 2})
+		require.Equal(t, Trunc(float32(math.Pow(10, 6.0/20)), MaxSigFigs), tone.HarmonicGains[0])
+		require.Equal(t, Trunc(float32(math.Pow(10, -12.0/20)), MaxSigFigs), tone.HarmonicGains[14])
+		require.Zero(t, tone.HarmonicGains[1])
+	})
+
+	t.Run("16' pushed in", func(t *testing.T) {
+		tone := NewDrawbarTone(ctx, 110, Drawbars{0, 0, 8, 7})
+		require.Equal(t, float32(1), tone.HarmonicGains[0])
+		require.Equal(t, Trunc(float32(math.Pow(10, -3.0/20)), MaxSigFigs), tone.HarmonicGains[2])
+	})
+
+	t.Run("out of range settings", func(t *testing.T) {
+		tone := NewDrawbarTone(ctx, 110, Drawbars{8, -3, 20})
+		require.Zero(t, tone.HarmonicGains[1])
+		require.Equal(t, float32(1), tone.HarmonicGains[0])
+	})
+}
+
+// Test_NewClarinetTone tests that NewClarinetTone returns a tone with only odd-ordered harmonics.
+func Test_NewClarinetTone(t *testing.T) {
+	ctx := context.NewContext()
+
+	// A roll-off of 1 is a square wave.
+	require.Equal(t, NewSquareTone(ctx, 220), NewClarinetTone(ctx, 220, 1))
+
+	tone := NewClarinetTone(ctx, 220, 1.5)
+	for i, gain := range tone.HarmonicGains {
+		order := i + 2
+		if order%2 == 0 {
+			require.Zero(t, gain)
+		} else {
+			require.Equal(t, Trunc(float32(math.Pow(float64(order), -1.5)), MaxSigFigs), gain)
+		}
+	}
+}
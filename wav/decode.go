@@ -0,0 +1,202 @@
+package wav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// Largest format chunk that is parsed, which is the extensible format. Anything after it in a
+	// longer chunk is skipped.
+	maxFormatSize = 40
+
+	// Size of a data chunk that was streamed before its size was known, which means that the data
+	// goes until the end of the file
+	streamedDataSize = 0xFFFFFFFF
+)
+
+// Decode reads a complete WAV file from r and returns its format and samples. If the file has more
+// than one channel, the samples are interleaved. Integer samples are scaled to be between -1 and 1.
+func Decode(r io.Reader) (Format, []float32, error) {
+	if r == nil {
+		return Format{}, nil, ErrInvalidFile
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return Format{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return Format{}, nil, fmt.Errorf("%w: missing RIFF/WAVE header", ErrInvalidFile)
+	}
+
+	var (
+		format    Format
+		hasFormat bool
+	)
+
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			return Format{}, nil, fmt.Errorf("%w: missing data chunk", ErrInvalidFile)
+		}
+		id := string(chunkHeader[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
+
+		switch id {
+		case "fmt ":
+			chunk, err := readChunk(r, min(size, maxFormatSize))
+			if err != nil {
+				return Format{}, nil, err
+			}
+			if _, err := io.CopyN(io.Discard, r, size-int64(len(chunk))); err != nil {
+				return Format{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+
+			if format, err = parseFormat(chunk); err != nil {
+				return Format{}, nil, err
+			}
+			hasFormat = true
+
+		case "data":
+			if !hasFormat {
+				return Format{}, nil, fmt.Errorf("%w: data chunk before format chunk", ErrInvalidFile)
+			}
+
+			var data []byte
+			var err error
+			if size == streamedDataSize {
+				data, err = io.ReadAll(r)
+				if err != nil {
+					err = fmt.Errorf("%w: %w", ErrInvalidFile, err)
+				}
+			} else {
+				data, err = readChunk(r, size)
+			}
+			if err != nil {
+				return Format{}, nil, err
+			}
+
+			return format, decodeSamples(data, format.Encoding), nil
+
+		default:
+			// Skip over any chunks that we don't need.
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return Format{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+		}
+
+		// Chunks are always aligned to an even number of bytes.
+		if size%2 == 1 {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return Format{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+		}
+	}
+}
+
+// readChunk reads the contents of a chunk. The chunk's size comes from the file and can't be
+// trusted, so memory is only allocated as the contents are actually read.
+func readChunk(r io.Reader, size int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	if int64(len(data)) < size {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, io.ErrUnexpectedEOF)
+	}
+
+	return data, nil
+}
+
+// parseFormat parses the contents of a format chunk.
+func parseFormat(chunk []byte) (Format, error) {
+	if len(chunk) < 16 {
+		return Format{}, fmt.Errorf("%w: format chunk too short", ErrInvalidFile)
+	}
+
+	tag := binary.LittleEndian.Uint16(chunk[0:2])
+	numChannels := int(binary.LittleEndian.Uint16(chunk[2:4]))
+	sampleRate := int(binary.LittleEndian.Uint32(chunk[4:8]))
+	bitsPerSample := int(binary.LittleEndian.Uint16(chunk[14:16]))
+
+	// The extensible format stores the real format tag at the start of its sub-format GUID.
+	if tag == formatExtensible {
+		if len(chunk) < 26 {
+			return Format{}, fmt.Errorf("%w: extensible format chunk too short", ErrInvalidFile)
+		}
+		tag = binary.LittleEndian.Uint16(chunk[24:26])
+	}
+
+	var encoding Encoding
+	switch {
+	case tag == formatPCM && bitsPerSample == 8:
+		encoding = PCM8
+	case tag == formatPCM && bitsPerSample == 16:
+		encoding = PCM16
+	case tag == formatPCM && bitsPerSample == 24:
+		encoding = PCM24
+	case tag == formatPCM && bitsPerSample == 32:
+		encoding = PCM32
+	case tag == formatFloat && bitsPerSample == 32:
+		encoding = Float32
+	case tag == formatFloat && bitsPerSample == 64:
+		encoding = Float64
+	default:
+		return Format{}, fmt.Errorf("%w: unsupported format tag %#x with %d bits per sample", ErrInvalidFormat, tag, bitsPerSample)
+	}
+
+	format := Format{
+		SampleRate:  sampleRate,
+		NumChannels: numChannels,
+		Encoding:    encoding,
+	}
+	if !format.Valid() {
+		return Format{}, ErrInvalidFormat
+	}
+
+	return format, nil
+}
+
+// decodeSamples decodes every complete sample in data. Any trailing bytes are ignored.
+func decodeSamples(data []byte, encoding Encoding) []float32 {
+	samples := make([]float32, len(data)/encoding.BytesPerSample())
+
+	switch encoding {
+	case PCM8:
+		for i := range samples {
+			samples[i] = float32(int(data[i])-1<<7) / (1 << 7)
+		}
+
+	case PCM16:
+		for i := range samples {
+			samples[i] = float32(int16(binary.LittleEndian.Uint16(data[i*2:]))) / (1 << 15)
+		}
+
+	case PCM24:
+		for i := range samples {
+			// Shift the value up into the top of a 32-bit integer and back down to sign-extend it.
+			v := int32(uint32(data[i*3])<<8|uint32(data[i*3+1])<<16|uint32(data[i*3+2])<<24) >> 8
+			samples[i] = float32(v) / (1 << 23)
+		}
+
+	case PCM32:
+		for i := range samples {
+			samples[i] = float32(float64(int32(binary.LittleEndian.Uint32(data[i*4:]))) / (1 << 31))
+		}
+
+	case Float32:
+		for i := range samples {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+
+	case Float64:
+		for i := range samples {
+			samples[i] = float32(math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:])))
+		}
+	}
+
+	return samples
+}
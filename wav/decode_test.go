@@ -0,0 +1,211 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Decode tests that Decode reads back the audio that Encode writes.
+func Test_Decode(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		samples := make([]float32, 4_410)
+		for i := range samples {
+			samples[i] = float32(0.9 * math.Sin(2*math.Pi*440*float64(i)/44_100))
+		}
+
+		type subtest struct {
+			encoding Encoding
+			delta    float64
+		}
+
+		for _, subtest := range []subtest{
+			{PCM8, 1.0 / (1 << 7)},
+			{PCM16, 1.0 / (1 << 15)},
+			{PCM24, 1.0 / (1 << 23)},
+			{PCM32, 1e-7},
+			{Float32, 0},
+			{Float64, 0},
+		} {
+			for _, numChannels := range []int{1, 2, 3, 6} {
+				format := Format{44_100, numChannels, subtest.encoding}
+
+				var b bytes.Buffer
+				require.NoError(t, Encode(&b, format, samples))
+
+				haveFormat, haveSamples, err := Decode(&b)
+				require.NoError(t, err)
+				require.Equal(t, format, haveFormat)
+				require.Len(t, haveSamples, len(samples))
+				for i := range samples {
+					require.InDelta(t, samples[i], haveSamples[i], subtest.delta)
+				}
+			}
+		}
+	})
+
+	t.Run("exact pcm values", func(t *testing.T) {
+		samples := []float32{-1, -0.5, 0, 0.25, 0.5}
+		for _, encoding := range []Encoding{PCM8, PCM16, PCM24, PCM32} {
+			var b bytes.Buffer
+			require.NoError(t, Encode(&b, Format{8_000, 1, encoding}, samples))
+
+			_, have, err := Decode(&b)
+			require.NoError(t, err)
+			require.Equal(t, samples, have)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, data := range [][]byte{
+			nil,
+			[]byte("RIFF"),
+			[]byte("RIFX\x00\x00\x00\x00WAVE"),
+			[]byte("RIFF\x00\x00\x00\x00WAVX"),
+			[]byte("RIFF\x00\x00\x00\x00WAVE"),
+			[]byte("RIFF\x00\x00\x00\x00WAVEdata\x00\x00\x00\x00"),
+			[]byte("RIFF\x00\x00\x00\x00WAVEfmt \x04\x00\x00\x00\x01\x00\x01\x00"),
+		} {
+			_, _, err := Decode(bytes.NewReader(data))
+			require.ErrorIs(t, err, ErrInvalidFile)
+		}
+
+		_, _, err := Decode(nil)
+		require.ErrorIs(t, err, ErrInvalidFile)
+	})
+
+	t.Run("oversized chunks", func(t *testing.T) {
+		// The data chunk claims to be almost 4GiB but the file ends right after its header.
+		data := riffFile(fmtChunk(formatPCM, 1, 44_100, 16))
+		data = append(data, "data\xf0\xff\xff\xff"...)
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, _, err := Decode(bytes.NewReader(data))
+		runtime.ReadMemStats(&after)
+		require.ErrorIs(t, err, ErrInvalidFile)
+		require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+
+	})
+
+	t.Run("long format chunk", func(t *testing.T) {
+		// Anything after the extensible format is skipped, including the padding byte.
+		for _, extra := range []int{1, 2, 1_000} {
+			fmtData := fmtChunk(formatPCM, 1, 44_100, 16)[8:]
+			fmtData = append(fmtData, make([]byte, maxFormatSize-len(fmtData)+extra)...)
+			data := riffFile(chunk("fmt ", fmtData), chunk("data", []byte{0x00, 0x40}))
+
+			format, samples, err := Decode(bytes.NewReader(data))
+			require.NoError(t, err, "%d extra bytes", extra)
+			require.Equal(t, Format{44_100, 1, PCM16}, format)
+			require.Equal(t, []float32{0.5}, samples)
+		}
+	})
+
+	t.Run("streamed data size", func(t *testing.T) {
+		// Files that were streamed before their size was known have data until the end of the file.
+		data := riffFile(fmtChunk(formatPCM, 1, 44_100, 16))
+		data = append(data, "data\xff\xff\xff\xff"...)
+		data = append(data, 0x00, 0x40, 0x00, 0xC0, 0x00)
+
+		format, samples, err := Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, Format{44_100, 1, PCM16}, format)
+		require.Equal(t, []float32{0.5, -0.5}, samples)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		data := riffFile(fmtChunk(0x0002, 1, 44_100, 16), chunk("data", make([]byte, 4)))
+		_, _, err := Decode(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidFormat)
+
+		data = riffFile(fmtChunk(formatPCM, 1, 44_100, 12), chunk("data", make([]byte, 4)))
+		_, _, err = Decode(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidFormat)
+
+		data = riffFile(fmtChunk(formatPCM, 0, 44_100, 16), chunk("data", make([]byte, 4)))
+		_, _, err = Decode(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("extra chunks", func(t *testing.T) {
+		data := riffFile(
+			chunk("LIST", []byte("INFOsome")),
+			fmtChunk(formatPCM, 1, 44_100, 16),
+			chunk("junk", []byte{1, 2, 3}),
+			chunk("data", []byte{0x00, 0x40, 0x00, 0xC0}),
+		)
+
+		format, samples, err := Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, Format{44_100, 1, PCM16}, format)
+		require.Equal(t, []float32{0.5, -0.5}, samples)
+	})
+
+	t.Run("extensible format", func(t *testing.T) {
+		fmtData := binary.LittleEndian.AppendUint16(nil, formatExtensible)
+		fmtData = binary.LittleEndian.AppendUint16(fmtData, 2)
+		fmtData = binary.LittleEndian.AppendUint32(fmtData, 48_000)
+		fmtData = binary.LittleEndian.AppendUint32(fmtData, 48_000*8)
+		fmtData = binary.LittleEndian.AppendUint16(fmtData, 8)
+		fmtData = binary.LittleEndian.AppendUint16(fmtData, 32)
+		fmtData = binary.LittleEndian.AppendUint16(fmtData, 22)
+		fmtData = binary.LittleEndian.AppendUint16(fmtData, 32)
+		fmtData = binary.LittleEndian.AppendUint32(fmtData, 0x3)
+		fmtData = binary.LittleEndian.AppendUint16(fmtData, formatFloat)
+		fmtData = append(fmtData, make([]byte, 14)...)
+
+		sampleData := binary.LittleEndian.AppendUint32(nil, math.Float32bits(0.125))
+		sampleData = binary.LittleEndian.AppendUint32(sampleData, math.Float32bits(-0.75))
+
+		data := riffFile(chunk("fmt ", fmtData), chunk("data", sampleData))
+
+		format, samples, err := Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, Format{48_000, 2, Float32}, format)
+		require.Equal(t, []float32{0.125, -0.75}, samples)
+	})
+}
+
+// riffFile wraps the chunks in a RIFF/WAVE header.
+func riffFile(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+
+	data := []byte("RIFF")
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(body)))
+
+	return append(data, body...)
+}
+
+// chunk builds a RIFF chunk, including its padding byte if needed.
+func chunk(id string, data []byte) []byte {
+	c := []byte(id)
+	c = binary.LittleEndian.AppendUint32(c, uint32(len(data)))
+	c = append(c, data...)
+	if len(data)%2 == 1 {
+		c = append(c, 0)
+	}
+
+	return c
+}
+
+// fmtChunk builds a basic format chunk.
+func fmtChunk(tag uint16, numChannels int, sampleRate int, bitsPerSample int) []byte {
+	blockAlign := numChannels * bitsPerSample / 8
+
+	data := binary.LittleEndian.AppendUint16(nil, tag)
+	data = binary.LittleEndian.AppendUint16(data, uint16(numChannels))
+	data = binary.LittleEndian.AppendUint32(data, uint32(sampleRate))
+	data = binary.LittleEndian.AppendUint32(data, uint32(sampleRate*blockAlign))
+	data = binary.LittleEndian.AppendUint16(data, uint16(blockAlign))
+	data = binary.LittleEndian.AppendUint16(data, uint16(bitsPerSample))
+
+	return chunk("fmt ", data)
+}
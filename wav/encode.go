@@ -0,0 +1,270 @@
+package wav
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+)
+
+// Encode writes a complete WAV file that contains the samples to w. If the format has more than
+// one channel, the samples must be interleaved, i.e. the first sample of every channel, then the
+// second sample of every channel, and so on.
+//
+// Samples are expected to be between -1 and 1. Samples outside of that range are clipped when
+// encoded as integers.
+//
+// Files with more than two channels or more than 16 bits per sample are written in the extensible
+// format, with the channels assigned to the speakers of the standard layout for their number.
+func Encode(w io.Writer, format Format, samples []float32) error {
+	if w == nil || !format.Valid() {
+		return ErrInvalidFormat
+	}
+
+	dataSize := len(samples) * format.Encoding.BytesPerSample()
+	if err := writeHeader(w, format, dataSize); err != nil {
+		return err
+	}
+
+	data := make([]byte, dataSize, dataSize+1)
+	encodeSamples(data, format.Encoding, samples)
+	if dataSize%2 == 1 {
+		data = append(data, 0)
+	}
+
+	_, err := w.Write(data)
+
+	return err
+}
+
+// EncodeBuffer renders the tones in the buffer, one frame at a time, and writes the result to w as
+// a WAV file with the context's sample rate and one channel for every channel in the buffer's
+// layout. Rendering starts at the context's current time and advances it by the number of frames
+// in the buffer.
+func EncodeBuffer(w io.Writer, ctx context.Context, buffer buffer.Buffer, encoding Encoding) error {
+	if ctx == nil {
+		return ErrInvalidFormat
+	}
+
+	numChannels := max(buffer.Layout.Channels(), 1)
+	samples := make([]float32, buffer.Frames()*numChannels)
+	buffer.Render(ctx, nil, samples)
+
+	return Encode(w, NewFormat(ctx, numChannels, encoding), samples)
+}
+
+// An Encoder writes a WAV file incrementally. This is useful when the total number of samples is
+// not known ahead of time. The file is not complete until the encoder is closed.
+type Encoder struct {
+	w        io.WriteSeeker
+	format   Format
+	start    int64
+	dataSize int
+	closed   bool
+}
+
+// NewEncoder writes the beginning of a WAV file to w and returns an encoder that can write the
+// rest of it. The sizes in the file's header are filled in when the encoder is closed.
+func NewEncoder(w io.WriteSeeker, format Format) (*Encoder, error) {
+	if w == nil || !format.Valid() {
+		return nil, ErrInvalidFormat
+	}
+
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeHeader(w, format, 0); err != nil {
+		return nil, err
+	}
+
+	return &Encoder{
+		w:      w,
+		format: format,
+		start:  start,
+	}, nil
+}
+
+// Format returns the format that the encoder is writing.
+func (encoder *Encoder) Format() Format {
+	if encoder == nil {
+		return Format{}
+	}
+
+	return encoder.format
+}
+
+// Write encodes the samples and writes them to the file. If the format has more than one channel,
+// the samples must be interleaved. Consecutive calls do not need to be aligned to whole frames.
+func (encoder *Encoder) Write(samples []float32) error {
+	if encoder == nil || encoder.closed {
+		return ErrClosed
+	}
+
+	data := make([]byte, len(samples)*encoder.format.Encoding.BytesPerSample())
+	encodeSamples(data, encoder.format.Encoding, samples)
+
+	n, err := encoder.w.Write(data)
+	encoder.dataSize += n
+
+	return err
+}
+
+// Close finishes the file by padding the audio data if necessary and filling in the sizes in the
+// file's header. It does not close the underlying writer.
+func (encoder *Encoder) Close() error {
+	if encoder == nil || encoder.closed {
+		return ErrClosed
+	}
+	encoder.closed = true
+
+	if encoder.dataSize%2 == 1 {
+		if _, err := encoder.w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+
+	end, err := encoder.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err := encoder.w.Seek(encoder.start, io.SeekStart); err != nil {
+		return err
+	}
+	if err := writeHeader(encoder.w, encoder.format, encoder.dataSize); err != nil {
+		return err
+	}
+
+	_, err = encoder.w.Seek(end, io.SeekStart)
+
+	return err
+}
+
+// writeHeader writes everything in a WAV file that comes before the audio data. The format must be
+// valid.
+func writeHeader(w io.Writer, format Format, dataSize int) error {
+	bytesPerSample := format.Encoding.BytesPerSample()
+	blockAlign := format.NumChannels * bytesPerSample
+	isFloat := format.Encoding.formatTag() == formatFloat
+
+	// Files with more than two channels or more than 16 bits per sample must use the extensible
+	// format, which also says which speaker each channel is for.
+	isExtensible := format.NumChannels > 2 || bytesPerSample > 2
+
+	// Non-PCM formats have an extension field in the format chunk, and they need a fact chunk that
+	// holds the number of frames.
+	fmtSize := 16
+	switch {
+	case isExtensible:
+		fmtSize = 40
+	case isFloat:
+		fmtSize = 18
+	}
+	hasFact := isFloat || isExtensible
+
+	riffSize := 4 + (8 + fmtSize) + (8 + dataSize + dataSize%2)
+	if hasFact {
+		riffSize += 12
+	}
+
+	header := make([]byte, 0, 12+8+fmtSize+12+8)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(riffSize))
+	header = append(header, "WAVE"...)
+
+	tag := format.Encoding.formatTag()
+	if isExtensible {
+		tag = formatExtensible
+	}
+
+	header = append(header, "fmt "...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(fmtSize))
+	header = binary.LittleEndian.AppendUint16(header, tag)
+	header = binary.LittleEndian.AppendUint16(header, uint16(format.NumChannels))
+	header = binary.LittleEndian.AppendUint32(header, uint32(format.SampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(format.SampleRate*blockAlign))
+	header = binary.LittleEndian.AppendUint16(header, uint16(blockAlign))
+	header = binary.LittleEndian.AppendUint16(header, uint16(bytesPerSample*8))
+	switch {
+	case isExtensible:
+		// The extension holds the number of valid bits in each sample, the speaker mask, and the
+		// sub-format GUID, which starts with the real format tag.
+		header = binary.LittleEndian.AppendUint16(header, 22)
+		header = binary.LittleEndian.AppendUint16(header, uint16(bytesPerSample*8))
+		header = binary.LittleEndian.AppendUint32(header, channelMask(format.NumChannels))
+		header = binary.LittleEndian.AppendUint16(header, format.Encoding.formatTag())
+		header = append(header, subFormatGUID...)
+	case isFloat:
+		header = binary.LittleEndian.AppendUint16(header, 0)
+	}
+
+	if hasFact {
+		header = append(header, "fact"...)
+		header = binary.LittleEndian.AppendUint32(header, 4)
+		header = binary.LittleEndian.AppendUint32(header, uint32(dataSize/blockAlign))
+	}
+
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(dataSize))
+
+	_, err := w.Write(header)
+
+	return err
+}
+
+// encodeSamples encodes the samples into data, which must be large enough to hold all of them.
+func encodeSamples(data []byte, encoding Encoding, samples []float32) {
+	switch encoding {
+	case PCM8:
+		for i, sample := range samples {
+			data[i] = uint8(quantize(sample, 1<<7) + 1<<7)
+		}
+
+	case PCM16:
+		for i, sample := range samples {
+			binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(quantize(sample, 1<<15))))
+		}
+
+	case PCM24:
+		for i, sample := range samples {
+			v := uint32(int32(quantize(sample, 1<<23)))
+			data[i*3] = byte(v)
+			data[i*3+1] = byte(v >> 8)
+			data[i*3+2] = byte(v >> 16)
+		}
+
+	case PCM32:
+		for i, sample := range samples {
+			binary.LittleEndian.PutUint32(data[i*4:], uint32(int32(quantize(sample, 1<<31))))
+		}
+
+	case Float32:
+		for i, sample := range samples {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(sample))
+		}
+
+	case Float64:
+		for i, sample := range samples {
+			binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(float64(sample)))
+		}
+	}
+}
+
+// quantize scales a sample by the maximum magnitude of an integer encoding and rounds it to the
+// nearest integer that the encoding can hold.
+func quantize(sample float32, scale int64) int64 {
+	v := math.Round(float64(sample) * float64(scale))
+
+	switch {
+	case v >= float64(scale-1):
+		return scale - 1
+	case v <= float64(-scale):
+		return -scale
+	default:
+		return int64(v)
+	}
+}
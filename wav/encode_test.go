@@ -0,0 +1,229 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/green-aloe/enobox/buffer"
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/render"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Encode tests that Encode writes a well-formed WAV file for every encoding.
+func Test_Encode(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		require.ErrorIs(t, Encode(nil, Format{44_100, 1, PCM16}, nil), ErrInvalidFormat)
+		require.ErrorIs(t, Encode(&bytes.Buffer{}, Format{}, nil), ErrInvalidFormat)
+		require.ErrorIs(t, Encode(&bytes.Buffer{}, Format{44_100, 0, PCM16}, nil), ErrInvalidFormat)
+	})
+
+	t.Run("pcm header", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, Encode(&b, Format{48_000, 2, PCM16}, []float32{0, 0.5, -0.5, 1}))
+
+		data := b.Bytes()
+		require.Len(t, data, 44+8)
+		require.Equal(t, "RIFF", string(data[0:4]))
+		require.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+		require.Equal(t, "WAVE", string(data[8:12]))
+		require.Equal(t, "fmt ", string(data[12:16]))
+		require.Equal(t, uint32(16), binary.LittleEndian.Uint32(data[16:20]))
+		require.Equal(t, uint16(formatPCM), binary.LittleEndian.Uint16(data[20:22]))
+		require.Equal(t, uint16(2), binary.LittleEndian.Uint16(data[22:24]))
+		require.Equal(t, uint32(48_000), binary.LittleEndian.Uint32(data[24:28]))
+		require.Equal(t, uint32(48_000*4), binary.LittleEndian.Uint32(data[28:32]))
+		require.Equal(t, uint16(4), binary.LittleEndian.Uint16(data[32:34]))
+		require.Equal(t, uint16(16), binary.LittleEndian.Uint16(data[34:36]))
+		require.Equal(t, "data", string(data[36:40]))
+		require.Equal(t, uint32(8), binary.LittleEndian.Uint32(data[40:44]))
+		require.Equal(t, []byte{0x00, 0x00, 0x00, 0x40, 0x00, 0xC0, 0xFF, 0x7F}, data[44:])
+	})
+
+	t.Run("extensible header", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, Encode(&b, Format{44_100, 1, Float32}, []float32{0.25, -0.25, 2}))
+
+		data := b.Bytes()
+		require.Len(t, data, 80+12)
+		require.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+		require.Equal(t, uint32(40), binary.LittleEndian.Uint32(data[16:20]))
+		require.Equal(t, uint16(formatExtensible), binary.LittleEndian.Uint16(data[20:22]))
+		require.Equal(t, uint16(32), binary.LittleEndian.Uint16(data[34:36]))
+		require.Equal(t, uint16(22), binary.LittleEndian.Uint16(data[36:38]))
+		require.Equal(t, uint16(32), binary.LittleEndian.Uint16(data[38:40]))
+		require.Equal(t, uint32(0x4), binary.LittleEndian.Uint32(data[40:44]))
+		require.Equal(t, uint16(formatFloat), binary.LittleEndian.Uint16(data[44:46]))
+		require.Equal(t, subFormatGUID, data[46:60])
+		require.Equal(t, "fact", string(data[60:64]))
+		require.Equal(t, uint32(3), binary.LittleEndian.Uint32(data[68:72]))
+		require.Equal(t, "data", string(data[72:76]))
+		require.Equal(t, uint32(12), binary.LittleEndian.Uint32(data[76:80]))
+
+		// More than two channels also need the extensible format, even with 16-bit samples.
+		b.Reset()
+		require.NoError(t, Encode(&b, Format{48_000, 6, PCM16}, make([]float32, 6)))
+
+		data = b.Bytes()
+		require.Len(t, data, 80+12)
+		require.Equal(t, uint16(formatExtensible), binary.LittleEndian.Uint16(data[20:22]))
+		require.Equal(t, uint16(6), binary.LittleEndian.Uint16(data[22:24]))
+		require.Equal(t, uint16(16), binary.LittleEndian.Uint16(data[38:40]))
+		require.Equal(t, uint32(0x3F), binary.LittleEndian.Uint32(data[40:44]))
+		require.Equal(t, uint16(formatPCM), binary.LittleEndian.Uint16(data[44:46]))
+		require.Equal(t, uint32(1), binary.LittleEndian.Uint32(data[68:72]))
+
+		format, samples, err := Decode(&b)
+		require.NoError(t, err)
+		require.Equal(t, Format{48_000, 6, PCM16}, format)
+		require.Equal(t, make([]float32, 6), samples)
+	})
+
+	t.Run("odd data size", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, Encode(&b, Format{44_100, 1, PCM24}, []float32{0.1, 0.2, 0.3}))
+
+		data := b.Bytes()
+		require.Len(t, data, 80+9+1)
+		require.Equal(t, uint32(9), binary.LittleEndian.Uint32(data[76:80]))
+		require.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+	})
+
+	t.Run("clipping", func(t *testing.T) {
+		samples := []float32{-2, -1, 1, 2}
+		for _, encoding := range []Encoding{PCM8, PCM16, PCM24, PCM32} {
+			var b bytes.Buffer
+			require.NoError(t, Encode(&b, Format{44_100, 1, encoding}, samples))
+
+			_, decoded, err := Decode(&b)
+			require.NoError(t, err)
+			require.Equal(t, float32(-1), decoded[0])
+			require.Equal(t, float32(-1), decoded[1])
+			require.InDelta(t, 1, decoded[2], 1.0/64)
+			require.Equal(t, decoded[2], decoded[3])
+		}
+	})
+}
+
+// Test_EncodeBuffer tests that EncodeBuffer renders a buffer's tones into a mono WAV file.
+func Test_EncodeBuffer(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		require.ErrorIs(t, EncodeBuffer(&bytes.Buffer{}, nil, buffer.Buffer{}, PCM16), ErrInvalidFormat)
+	})
+
+	buffer.CheckLeaks(t)
+
+	ctx := context.NewContextWith(context.ContextOptions{
+		SampleRate: 8_000,
+	})
+
+	buf := buffer.NewBuffer(ctx)
+	defer buf.Release()
+	for i := range buf.Tones {
+		buf.Tones[i].Frequency = 440
+		buf.Tones[i].Gain = 0.5
+		buf.Tones[i].HarmonicGains[1] = 0.3
+	}
+
+	start := ctx.Time()
+	var b bytes.Buffer
+	require.NoError(t, EncodeBuffer(&b, ctx, buf, Float32))
+	require.Equal(t, start.ShiftBy(len(buf.Tones)), ctx.Time())
+
+	format, samples, err := Decode(&b)
+	require.NoError(t, err)
+	require.Equal(t, Format{8_000, 1, Float32}, format)
+
+	var renderer render.Renderer
+	want := make([]float32, len(buf.Tones))
+	renderer.Render(ctx, buf.Tones[0], want)
+	require.Equal(t, want, samples)
+
+	t.Run("stereo", func(t *testing.T) {
+		buf := buffer.Buffer{
+			Tones:     buf.Tones[:100],
+			Positions: make([]buffer.Position, 100),
+			Layout:    buffer.LayoutStereo,
+			PanLaw:    buffer.PanLawLinear,
+		}
+		for i := range buf.Positions {
+			buf.Positions[i].Pan = -1
+		}
+
+		var b bytes.Buffer
+		require.NoError(t, EncodeBuffer(&b, ctx, buf, Float32))
+
+		format, samples, err := Decode(&b)
+		require.NoError(t, err)
+		require.Equal(t, Format{8_000, 2, Float32}, format)
+		require.Len(t, samples, 200)
+		for i := range 100 {
+			require.Equal(t, want[i], samples[2*i])
+			require.Zero(t, samples[2*i+1])
+		}
+	})
+}
+
+// Test_Encoder tests that Encoder writes a WAV file incrementally and fills in its header when
+// closed.
+func Test_Encoder(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		encoder, err := NewEncoder(nil, Format{44_100, 1, PCM16})
+		require.ErrorIs(t, err, ErrInvalidFormat)
+		require.Nil(t, encoder)
+
+		f, err := os.Create(filepath.Join(t.TempDir(), "invalid.wav"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		encoder, err = NewEncoder(f, Format{})
+		require.ErrorIs(t, err, ErrInvalidFormat)
+		require.Nil(t, encoder)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var encoder *Encoder
+		require.Equal(t, Format{}, encoder.Format())
+		require.ErrorIs(t, encoder.Write([]float32{1}), ErrClosed)
+		require.ErrorIs(t, encoder.Close(), ErrClosed)
+	})
+
+	for _, encoding := range []Encoding{PCM8, PCM16, PCM24, PCM32, Float32, Float64} {
+		for _, numChannels := range []int{1, 2, 6} {
+			format := Format{22_050, numChannels, encoding}
+
+			samples := make([]float32, 1_001*numChannels)
+			for i := range samples {
+				samples[i] = float32(i%200)/100 - 1
+			}
+
+			// Write the samples in uneven pieces.
+			path := filepath.Join(t.TempDir(), "encoder.wav")
+			f, err := os.Create(path)
+			require.NoError(t, err)
+
+			encoder, err := NewEncoder(f, format)
+			require.NoError(t, err)
+			require.Equal(t, format, encoder.Format())
+			for start := 0; start < len(samples); start += 333 {
+				end := min(start+333, len(samples))
+				require.NoError(t, encoder.Write(samples[start:end]))
+			}
+			require.NoError(t, encoder.Close())
+			require.ErrorIs(t, encoder.Close(), ErrClosed)
+			require.ErrorIs(t, encoder.Write(samples), ErrClosed)
+			require.NoError(t, f.Close())
+
+			// The file should be identical to one written all at once.
+			var want bytes.Buffer
+			require.NoError(t, Encode(&want, format, samples))
+
+			have, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, want.Bytes(), have)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package wav
+
+import (
+	"errors"
+
+	"github.com/green-aloe/enobox/context"
+)
+
+// An Encoding is the way that each sample is stored in a WAV file.
+type Encoding int
+
+const (
+	// PCM8 stores each sample as an unsigned 8-bit integer.
+	PCM8 Encoding = iota + 1
+	// PCM16 stores each sample as a signed 16-bit integer.
+	PCM16
+	// PCM24 stores each sample as a signed 24-bit integer.
+	PCM24
+	// PCM32 stores each sample as a signed 32-bit integer.
+	PCM32
+	// Float32 stores each sample as a 32-bit IEEE floating-point number.
+	Float32
+	// Float64 stores each sample as a 64-bit IEEE floating-point number.
+	Float64
+)
+
+// Format tags from the WAVE specification
+const (
+	formatPCM        = 0x0001
+	formatFloat      = 0x0003
+	formatExtensible = 0xFFFE
+)
+
+var (
+	// Rest of the sub-format GUID of the extensible format after the format tag
+	subFormatGUID = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+)
+
+var (
+	// ErrInvalidFormat is returned when a format cannot be used to encode or decode audio.
+	ErrInvalidFormat = errors.New("wav: invalid format")
+
+	// ErrInvalidFile is returned when the data being decoded is not a well-formed WAV file.
+	ErrInvalidFile = errors.New("wav: invalid file")
+
+	// ErrClosed is returned when writing to an encoder that has already been closed.
+	ErrClosed = errors.New("wav: encoder closed")
+)
+
+// A Format describes the layout of the audio in a WAV file.
+type Format struct {
+	// SampleRate is the number of samples per second (Hz) in each channel.
+	SampleRate int
+
+	// NumChannels is the number of interleaved channels. 1 is mono, 2 is stereo, and so on.
+	NumChannels int
+
+	// Encoding is the way each sample is stored.
+	Encoding Encoding
+}
+
+// NewFormat returns a format with the context's sample rate and the specified number of channels
+// and encoding.
+func NewFormat(ctx context.Context, numChannels int, encoding Encoding) Format {
+	var sampleRate int
+	if ctx != nil {
+		sampleRate = ctx.SampleRate()
+	}
+
+	return Format{
+		SampleRate:  sampleRate,
+		NumChannels: numChannels,
+		Encoding:    encoding,
+	}
+}
+
+// Valid reports if the format can be used to store audio.
+func (format Format) Valid() bool {
+	return format.SampleRate > 0 && format.NumChannels > 0 && format.Encoding.BytesPerSample() > 0
+}
+
+// BytesPerSample returns the number of bytes used to store a single sample in one channel, or 0 if
+// the encoding is invalid.
+func (encoding Encoding) BytesPerSample() int {
+	switch encoding {
+	case PCM8:
+		return 1
+	case PCM16:
+		return 2
+	case PCM24:
+		return 3
+	case PCM32, Float32:
+		return 4
+	case Float64:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// formatTag returns the WAVE format tag for the encoding.
+func (encoding Encoding) formatTag() uint16 {
+	switch encoding {
+	case Float32, Float64:
+		return formatFloat
+	default:
+		return formatPCM
+	}
+}
+
+// channelMask returns the speakers of the standard layout with the number of channels, in the form
+// that the extensible format uses. The channels are in WAV order, so quad is front left, front
+// right, rear left, and rear right, and 5.1 is front left, front right, center, low-frequency
+// effects, rear left, and rear right. Other numbers of channels aren't assigned to any speakers.
+func channelMask(numChannels int) uint32 {
+	const (
+		frontLeft   = 0x1
+		frontRight  = 0x2
+		frontCenter = 0x4
+		lowFreq     = 0x8
+		backLeft    = 0x10
+		backRight   = 0x20
+		sideLeft    = 0x200
+		sideRight   = 0x400
+	)
+
+	switch numChannels {
+	case 1:
+		return frontCenter
+	case 2:
+		return frontLeft | frontRight
+	case 3:
+		return frontLeft | frontRight | frontCenter
+	case 4:
+		return frontLeft | frontRight | backLeft | backRight
+	case 5:
+		return frontLeft | frontRight | frontCenter | backLeft | backRight
+	case 6:
+		return frontLeft | frontRight | frontCenter | lowFreq | backLeft | backRight
+	case 8:
+		return frontLeft | frontRight | frontCenter | lowFreq | backLeft | backRight | sideLeft | sideRight
+	default:
+		return 0
+	}
+}
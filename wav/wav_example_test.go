@@ -0,0 +1,45 @@
+package wav_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/green-aloe/enobox/wav"
+)
+
+func ExampleEncode() {
+	ctx := context.NewContext()
+	format := wav.NewFormat(ctx, 2, wav.PCM16)
+
+	var b bytes.Buffer
+	samples := []float32{0, 0, 0.5, -0.5, 1, -1}
+	if err := wav.Encode(&b, format, samples); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(b.Len())
+
+	// Output:
+	// 56
+}
+
+func ExampleDecode() {
+	var b bytes.Buffer
+	format := wav.Format{SampleRate: 48_000, NumChannels: 1, Encoding: wav.PCM24}
+	if err := wav.Encode(&b, format, []float32{0.25, -0.5, 0.75}); err != nil {
+		panic(err)
+	}
+
+	format, samples, err := wav.Decode(&b)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(format.SampleRate, format.NumChannels, format.Encoding == wav.PCM24)
+	fmt.Println(samples)
+
+	// Output:
+	// 48000 1 true
+	// [0.25 -0.5 0.75]
+}
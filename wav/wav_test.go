@@ -0,0 +1,85 @@
+package wav
+
+import (
+	"testing"
+
+	"github.com/green-aloe/enobox/context"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_NewFormat tests that NewFormat uses the context's sample rate.
+func Test_NewFormat(t *testing.T) {
+	t.Run("nil context", func(t *testing.T) {
+		format := NewFormat(nil, 2, PCM16)
+		require.Equal(t, Format{0, 2, PCM16}, format)
+		require.False(t, format.Valid())
+	})
+
+	for _, sampleRate := range []int{8_000, context.DefaultSampleRate, 96_000} {
+		ctx := context.NewContextWith(context.ContextOptions{
+			SampleRate: sampleRate,
+		})
+
+		format := NewFormat(ctx, 1, Float32)
+		require.Equal(t, Format{sampleRate, 1, Float32}, format)
+		require.True(t, format.Valid())
+	}
+}
+
+// Test_Format_Valid tests that Format's Valid method correctly determines whether a format can be
+// used.
+func Test_Format_Valid(t *testing.T) {
+	type subtest struct {
+		format Format
+		want   bool
+		name   string
+	}
+
+	subtests := []subtest{
+		{Format{}, false, "empty"},
+		{Format{44_100, 1, PCM16}, true, "mono"},
+		{Format{44_100, 6, PCM24}, true, "multichannel"},
+		{Format{0, 1, PCM16}, false, "no sample rate"},
+		{Format{-1, 1, PCM16}, false, "negative sample rate"},
+		{Format{44_100, 0, PCM16}, false, "no channels"},
+		{Format{44_100, 1, 0}, false, "no encoding"},
+		{Format{44_100, 1, Float64 + 1}, false, "unknown encoding"},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			require.Equal(t, subtest.want, subtest.format.Valid())
+		})
+	}
+}
+
+// Test_Encoding_BytesPerSample tests that Encoding's BytesPerSample method returns the correct
+// sample size for every encoding.
+func Test_Encoding_BytesPerSample(t *testing.T) {
+	require.Equal(t, 0, Encoding(0).BytesPerSample())
+	require.Equal(t, 1, PCM8.BytesPerSample())
+	require.Equal(t, 2, PCM16.BytesPerSample())
+	require.Equal(t, 3, PCM24.BytesPerSample())
+	require.Equal(t, 4, PCM32.BytesPerSample())
+	require.Equal(t, 4, Float32.BytesPerSample())
+	require.Equal(t, 8, Float64.BytesPerSample())
+	require.Equal(t, 0, (Float64 + 1).BytesPerSample())
+}
+
+// Test_channelMask tests that channelMask assigns the channels of standard layouts to speakers.
+func Test_channelMask(t *testing.T) {
+	for numChannels, mask := range map[int]uint32{
+		0: 0,
+		1: 0x4,
+		2: 0x3,
+		3: 0x7,
+		4: 0x33,
+		5: 0x37,
+		6: 0x3F,
+		7: 0,
+		8: 0x63F,
+		9: 0,
+	} {
+		require.Equal(t, mask, channelMask(numChannels), "%d channels", numChannels)
+	}
+}